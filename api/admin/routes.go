@@ -42,6 +42,8 @@ func InitApi(router *gin.RouterGroup) {
 	{
 
 		admin.POST("/login", userHandler.Login)
+		admin.POST("/login/twoFactor", userHandler.LoginTwoFactor)
+		admin.POST("/login/twoFactor/setup", userHandler.LoginTwoFactorSetup)
 		// admin.POST("/register", userHandler.Register)
 
 		user.POST("/createUser", userHandler.CreateUser)
@@ -50,12 +52,47 @@ func InitApi(router *gin.RouterGroup) {
 		user.PUT("/updateUser", userHandler.UpdateUser)
 		user.POST("/searchUser", userHandler.SearchUser)
 		user.DELETE("/deleteUser", userHandler.DeleteUser)
+		user.POST("/lockUser", userHandler.LockUser)
+		user.POST("/unlockUser", userHandler.UnlockUser)
 		user.PUT("/changePassword", userHandler.ChangePassword)
 		user.GET("/getMembers", userHandler.GetMembers)
 		user.POST("/uploadAvatar", userHandler.UploadAvatar)
 
 	}
 
+	sessionHandler := handlers.NewSessionHandler()
+	{
+		admin.POST("/refreshToken", sessionHandler.RefreshToken)
+
+		user.POST("/logout", sessionHandler.Logout)
+		user.GET("/userSessions", sessionHandler.GetUserSessions)
+		user.POST("/revokeUserSession", sessionHandler.RevokeUserSession)
+		user.POST("/revokeUserSessions", sessionHandler.RevokeUserSessions)
+		user.POST("/revokeToken", sessionHandler.RevokeToken)
+	}
+
+	lockoutHandler := handlers.NewLockoutHandler()
+	{
+		user.GET("/loginLockouts", lockoutHandler.GetLockouts)
+		user.POST("/clearLoginLockout", lockoutHandler.ClearLockout)
+	}
+
+	accessTokenHandler := handlers.NewAccessTokenHandler()
+	{
+		user.GET("/userAccessTokens", accessTokenHandler.GetUserAccessTokens)
+		user.POST("/revokeUserAccessToken", accessTokenHandler.RevokeUserAccessToken)
+		user.POST("/revokeUserAccessTokens", accessTokenHandler.RevokeUserAccessTokens)
+	}
+
+	twoFactorHandler := handlers.NewTwoFactorHandler()
+	{
+		user.GET("/twoFactor", twoFactorHandler.GetStatus)
+		user.POST("/twoFactor/setup", twoFactorHandler.Setup)
+		user.POST("/twoFactor/enable", twoFactorHandler.Enable)
+		user.POST("/twoFactor/disable", twoFactorHandler.Disable)
+		user.POST("/twoFactor/recoveryCodes", twoFactorHandler.RegenerateRecoveryCodes)
+	}
+
 	projectHandler := handlers.NewProjectHandler()
 	{
 
@@ -67,6 +104,8 @@ func InitApi(router *gin.RouterGroup) {
 		user.POST("/addProjectMember", projectHandler.AddProjectMember)
 		user.POST("/removeProjectMember", projectHandler.RemoveProjectMember)
 		user.POST("/setProjectAssignee", projectHandler.SetProjectAssignee)
+		user.GET("/exportProject", projectHandler.ExportProject)
+		user.POST("/restoreProject", projectHandler.RestoreProject)
 
 	}
 
@@ -74,4 +113,9 @@ func InitApi(router *gin.RouterGroup) {
 	{
 		user.GET("/getProjectTask", taskHandler.GetProjectTask)
 	}
+
+	auditHandler := handlers.NewAuditHandler()
+	{
+		user.GET("/auditLogs", auditHandler.GetAuditLogs)
+	}
 }
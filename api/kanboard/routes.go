@@ -17,7 +17,13 @@ func InitApi(router *gin.RouterGroup) {
 		userHandler := handlers.NewUserHandler()
 
 		kanboard.POST("/login", userHandler.Login)
+		kanboard.POST("/login/twoFactor", userHandler.LoginTwoFactor)
+		kanboard.POST("/login/twoFactor/setup", userHandler.LoginTwoFactorSetup)
 		kanboard.POST("/register", userHandler.Register)
+		kanboard.POST("/forgotPassword", userHandler.ForgotPassword)
+		kanboard.POST("/resetPassword", userHandler.ResetPassword)
+		kanboard.POST("/resendVerification", userHandler.ResendVerification)
+		kanboard.POST("/verifyEmail", userHandler.VerifyEmail)
 
 		user.GET("/getUser", userHandler.GetUserById)
 		user.PUT("/update", userHandler.UpdateInfo)
@@ -26,6 +32,49 @@ func InitApi(router *gin.RouterGroup) {
 		user.GET("/calendar", userHandler.GetCalendar)
 	}
 
+	sessionHandler := handlers.NewSessionHandler()
+	{
+		kanboard.POST("/refreshToken", sessionHandler.RefreshToken)
+
+		user.POST("/logout", sessionHandler.Logout)
+		user.GET("/sessions", sessionHandler.GetSessions)
+		user.POST("/revokeSession", sessionHandler.RevokeSession)
+		user.POST("/revokeAllSessions", sessionHandler.RevokeAllSessions)
+	}
+
+	accessTokenHandler := handlers.NewAccessTokenHandler()
+	{
+		user.GET("/accessTokenScopes", accessTokenHandler.GetScopes)
+		user.GET("/accessTokens", accessTokenHandler.GetAccessTokens)
+		user.POST("/createAccessToken", accessTokenHandler.CreateAccessToken)
+		user.POST("/revokeAccessToken", accessTokenHandler.RevokeAccessToken)
+	}
+
+	oidcHandler := handlers.NewOIDCHandler()
+	{
+		kanboard.GET("/oidc/authorize", oidcHandler.Authorize)
+		kanboard.POST("/oidc/callback", oidcHandler.Callback)
+	}
+
+	twoFactorHandler := handlers.NewTwoFactorHandler()
+	{
+		user.GET("/twoFactor", twoFactorHandler.GetStatus)
+		user.POST("/twoFactor/setup", twoFactorHandler.Setup)
+		user.POST("/twoFactor/enable", twoFactorHandler.Enable)
+		user.POST("/twoFactor/disable", twoFactorHandler.Disable)
+		user.POST("/twoFactor/recoveryCodes", twoFactorHandler.RegenerateRecoveryCodes)
+	}
+
+	calendarHandler := handlers.NewCalendarHandler()
+	{
+		kanboard.GET("/feed/:token", calendarHandler.GetFeed)
+
+		user.GET("/calendarTasks", calendarHandler.GetCalendarTasks)
+		user.POST("/createCalendarFeed", calendarHandler.CreateFeed)
+		user.GET("/getCalendarFeeds", calendarHandler.GetFeeds)
+		user.POST("/revokeCalendarFeed", calendarHandler.RevokeFeed)
+	}
+
 	public := kanboard.Group("/")
 	publicHandler := handlers.NewPublicHandler()
 	{
@@ -54,6 +103,8 @@ func InitApi(router *gin.RouterGroup) {
 		user.POST("/removeProjectMember", projectHandler.RemoveProjectMember)
 		user.POST("/getProjectMembers", projectHandler.GetProjectMembers)
 		user.POST("/getMembers", projectHandler.GetMembers)
+		user.POST("/changeMemberRole", projectHandler.ChangeMemberRole)
+		user.GET("/projectRole", projectHandler.GetProjectRole)
 	}
 
 	taskHandler := handlers.NewTaskHandler()
@@ -70,5 +121,47 @@ func InitApi(router *gin.RouterGroup) {
 		user.POST("/addTaskAssignee", taskHandler.AddTaskAssignee)
 		user.POST("/removeTaskAssignee", taskHandler.RemoveTaskAssignee)
 		user.POST("/searchTask", taskHandler.SearchTask)
+		user.GET("/timeline", taskHandler.GetProjectTimeline)
+		user.POST("/rescheduleTask", taskHandler.RescheduleTask)
+		user.POST("/addTaskDependency", taskHandler.AddTaskDependency)
+		user.POST("/removeTaskDependency", taskHandler.RemoveTaskDependency)
+		user.GET("/exportTasks", taskHandler.ExportTasks)
+		user.POST("/importTasks", taskHandler.ImportTasks)
+	}
+
+	chartHandler := handlers.NewChartHandler()
+	{
+		user.GET("/cumulativeFlow", chartHandler.GetCumulativeFlow)
+		user.GET("/burndown", chartHandler.GetBurndown)
+		user.GET("/flowMetrics", chartHandler.GetFlowMetrics)
+	}
+
+	automationHandler := handlers.NewAutomationHandler()
+	{
+		user.GET("/automationRules", automationHandler.GetRules)
+		user.POST("/createAutomationRule", automationHandler.CreateRule)
+		user.POST("/updateAutomationRule", automationHandler.UpdateRule)
+		user.DELETE("/deleteAutomationRule", automationHandler.DeleteRule)
+		user.GET("/automationLogs", automationHandler.GetLogs)
+	}
+
+	webhookHandler := handlers.NewWebhookHandler()
+	{
+		user.GET("/webhooks", webhookHandler.GetWebhooks)
+		user.POST("/createWebhook", webhookHandler.CreateWebhook)
+		user.POST("/updateWebhook", webhookHandler.UpdateWebhook)
+		user.DELETE("/deleteWebhook", webhookHandler.DeleteWebhook)
+		user.GET("/webhookDeliveries", webhookHandler.GetDeliveries)
+		user.POST("/redeliverWebhook", webhookHandler.Redeliver)
+	}
+
+	gitHandler := handlers.NewGitHandler()
+	{
+		kanboard.POST("/git/:hookId", gitHandler.ReceiveHook)
+
+		user.GET("/gitIntegrations", gitHandler.GetIntegrations)
+		user.POST("/createGitIntegration", gitHandler.CreateIntegration)
+		user.DELETE("/deleteGitIntegration", gitHandler.DeleteIntegration)
+		user.GET("/taskCommits", gitHandler.GetTaskCommits)
 	}
 }
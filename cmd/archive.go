@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"server/internal/app/admin/dto"
+	"server/internal/app/admin/services"
+)
+
+// 导出项目归档，例如：
+// server export-project -project 1 -out project-1.zip
+func runExportProject(args []string) error {
+	flags := flag.NewFlagSet("export-project", flag.ContinueOnError)
+	projectId := flags.Uint("project", 0, "project id")
+	out := flags.String("out", "", "archive file to write, defaults to project-<id>.zip")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *projectId == 0 {
+		flags.Usage()
+		return errors.New("-project is required")
+	}
+	if *out == "" {
+		*out = fmt.Sprintf("project-%d.zip", *projectId)
+	}
+
+	archiveService := services.NewArchiveService()
+	archive, files, err := archiveService.BuildProjectArchive(*projectId)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := archiveService.WriteProjectArchive(archive, files, file); err != nil {
+		file.Close()
+		os.Remove(*out)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("exported project %d to %s (%d tasks, %d resources)\n", *projectId, *out, len(archive.Tasks), len(archive.Resources))
+	return nil
+}
+
+// 从归档恢复项目，例如：
+// server restore-project -user 1 -file project-1.zip -name "restored"
+func runRestoreProject(args []string) error {
+	flags := flag.NewFlagSet("restore-project", flag.ContinueOnError)
+	userId := flags.Uint("user", 0, "fallback user id for creators that cannot be mapped")
+	path := flags.String("file", "", "archive file to restore")
+	name := flags.String("name", "", "project name, defaults to the archived name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *userId == 0 || *path == "" {
+		flags.Usage()
+		return errors.New("-user and -file are required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	report, err := services.NewArchiveService().RestoreProject(file, info.Size(), dto.ProjectRestoreDto{Name: *name}, *userId)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
@@ -1,16 +1,56 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
 	"server/config"
+	kanboardServices "server/internal/app/kanboard/services"
 	"server/internal/global"
 	"server/internal/router"
 )
 
-func init() {
+// setup 加载配置并初始化日志、签名密钥、数据库与 Redis
+// 只在启动服务与需要访问数据的命令中调用，生成密钥与本地模拟服务等命令不依赖这些
+func setup() {
 	config.InitConfig()
 	global.InitGlobal()
 }
 
 func Start() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	setup()
+	kanboardServices.StartAutomationScheduler()
+	kanboardServices.StartWebhookScheduler()
 	router.Run()
 }
+
+func runCommand(name string, args []string) error {
+	switch name {
+	case "import":
+		setup()
+		return runImport(args)
+	case "export-project":
+		setup()
+		return runExportProject(args)
+	case "restore-project":
+		setup()
+		return runRestoreProject(args)
+	case "webhook-receiver":
+		return runWebhookReceiver(args)
+	case "jwt-keygen":
+		return runJWTKeygen(args)
+	case "oidc-mock":
+		return runOIDCMock(args)
+	case "ldap-mock":
+		return runLDAPMock(args)
+	default:
+		return fmt.Errorf("unknown command: %s", name)
+	}
+}
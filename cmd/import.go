@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+)
+
+// 从命令行导入任务，例如：
+// server import -project 1 -user 1 -format csv -file tasks.csv
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	projectId := flags.Uint("project", 0, "project id")
+	userId := flags.Uint("user", 0, "acting user id")
+	format := flags.String("format", "csv", "csv, trello or jira")
+	path := flags.String("file", "", "file to import")
+	mapping := flags.String("mapping", "", "column mapping as JSON, e.g. {\"title\":\"Name\"}")
+	dryRun := flags.Bool("dry-run", false, "validate only, do not create tasks")
+	addMembers := flags.Bool("add-members", false, "add unknown assignees to the project")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *projectId == 0 || *userId == 0 || *path == "" {
+		flags.Usage()
+		return errors.New("-project, -user and -file are required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	report, err := services.NewImportService().ImportTasks(dto.TaskImportDto{
+		ProjectId:  *projectId,
+		Format:     *format,
+		Mapping:    *mapping,
+		DryRun:     *dryRun,
+		AddMembers: *addMembers,
+	}, file, *userId)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
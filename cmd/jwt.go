@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"server/pkg/crypto"
+)
+
+// 生成新的 JWT 签名密钥对并输出对应的配置，例如：
+// server jwt-keygen -alg EdDSA -kid 2026-10 -out ./keys
+func runJWTKeygen(args []string) error {
+	flags := flag.NewFlagSet("jwt-keygen", flag.ContinueOnError)
+	alg := flags.String("alg", crypto.ALG_EDDSA, "signing algorithm: EdDSA or RS256")
+	kid := flags.String("kid", "", "key id written to the kid header")
+	out := flags.String("out", "./keys", "directory to write the key files to")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *kid == "" {
+		return fmt.Errorf("-kid is required")
+	}
+
+	privatePem, publicPem, err := crypto.GenerateKeyPair(*alg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0o700); err != nil {
+		return err
+	}
+	privatePath := filepath.Join(*out, *kid+".pem")
+	publicPath := filepath.Join(*out, *kid+".pub.pem")
+	if err := os.WriteFile(privatePath, privatePem, 0o600); err != nil {
+		return err
+	}
+	if err := os.WriteFile(publicPath, publicPem, 0o644); err != nil {
+		return err
+	}
+
+	fmt.Printf("[[jwt.keys]]\nkid = %q\nalgorithm = %q\nprivateKey = %q\npublicKey = %q\n", *kid, *alg, privatePath, publicPath)
+	return nil
+}
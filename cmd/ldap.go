@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strings"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+)
+
+const (
+	ldapBindRequest       ber.Tag = 0
+	ldapBindResponse      ber.Tag = 1
+	ldapUnbindRequest     ber.Tag = 2
+	ldapSearchRequest     ber.Tag = 3
+	ldapSearchResultEntry ber.Tag = 4
+	ldapSearchResultDone  ber.Tag = 5
+	ldapExtendedRequest   ber.Tag = 23
+	ldapExtendedResponse  ber.Tag = 24
+)
+
+const (
+	ldapResultSuccess            = 0
+	ldapResultProtocolError      = 2
+	ldapResultNoSuchObject       = 32
+	ldapResultInvalidCredentials = 49
+)
+
+type mockLDAPEntry struct {
+	DN         string
+	Attributes map[string][]string
+}
+
+// 本地模拟 LDAP 目录，只支持简单绑定与搜索，例如：
+// server ldap-mock -addr :3389 -users alice:secret,bob:secret -admins alice
+// 服务账号为 cn=admin,<base>，用户位于 ou=people,<base>，管理员分组为 cn=admins,ou=groups,<base>
+func runLDAPMock(args []string) error {
+	flags := flag.NewFlagSet("ldap-mock", flag.ContinueOnError)
+	addr := flags.String("addr", ":3389", "address to listen on")
+	base := flags.String("base", "dc=example,dc=com", "base dn")
+	bindPassword := flags.String("bind-password", "admin", "password of the service account")
+	users := flags.String("users", "alice:secret", "comma separated username:password pairs")
+	admins := flags.String("admins", "", "comma separated usernames in the admin group")
+	domain := flags.String("domain", "example.com", "mail domain of the users")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	adminGroup := &mockLDAPEntry{
+		DN:         "cn=admins,ou=groups," + *base,
+		Attributes: map[string][]string{"objectclass": {"groupOfNames"}, "cn": {"admins"}},
+	}
+	entries := []*mockLDAPEntry{
+		{DN: "cn=admin," + *base, Attributes: map[string][]string{"objectclass": {"organizationalRole"}, "userpassword": {*bindPassword}}},
+		adminGroup,
+	}
+	for _, item := range strings.Split(*users, ",") {
+		username, password, ok := strings.Cut(item, ":")
+		if !ok || username == "" {
+			return fmt.Errorf("invalid user %q", item)
+		}
+		entry := &mockLDAPEntry{
+			DN: "uid=" + username + ",ou=people," + *base,
+			Attributes: map[string][]string{
+				"objectclass":  {"person", "inetOrgPerson"},
+				"uid":          {username},
+				"cn":           {username},
+				"mail":         {username + "@" + *domain},
+				"userpassword": {password},
+			},
+		}
+		entries = append(entries, entry)
+		if slices.Contains(strings.Split(*admins, ","), username) {
+			adminGroup.Attributes["member"] = append(adminGroup.Attributes["member"], entry.DN)
+		}
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("ldap mock listening on %s, base %s\n", *addr, *base)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go serveLDAPMock(conn, entries)
+	}
+}
+
+func serveLDAPMock(conn net.Conn, entries []*mockLDAPEntry) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			if err != io.EOF {
+				fmt.Printf("read error: %s\n", err)
+			}
+			return
+		}
+		if len(packet.Children) < 2 {
+			return
+		}
+		id, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldapBindRequest:
+			name := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := ldapResultInvalidCredentials
+			if name == "" && password == "" {
+				code = ldapResultSuccess
+			} else if entry := findLDAPEntry(entries, name); entry != nil && password != "" &&
+				slices.Contains(entry.Attributes["userpassword"], password) {
+				code = ldapResultSuccess
+			}
+			fmt.Printf("bind dn=%q result=%d\n", name, code)
+			writeLDAPResult(conn, id, ldapBindResponse, code)
+		case ldapSearchRequest:
+			base := op.Children[0].Data.String()
+			scope, _ := op.Children[1].Value.(int64)
+			filter := op.Children[6]
+			attributes := []string{}
+			for _, item := range op.Children[7].Children {
+				attributes = append(attributes, strings.ToLower(item.Data.String()))
+			}
+
+			if findLDAPEntry(entries, base) == nil && !isLDAPSuffix(entries, base) {
+				writeLDAPResult(conn, id, ldapSearchResultDone, ldapResultNoSuchObject)
+				continue
+			}
+			matched := 0
+			for _, entry := range entries {
+				if inLDAPScope(entry.DN, base, scope) && matchLDAPFilter(entry, filter) {
+					conn.Write(ldapSearchEntry(id, entry, attributes).Bytes())
+					matched++
+				}
+			}
+			fmt.Printf("search base=%q scope=%d matched=%d\n", base, scope, matched)
+			writeLDAPResult(conn, id, ldapSearchResultDone, ldapResultSuccess)
+		case ldapUnbindRequest:
+			return
+		case ldapExtendedRequest:
+			// 不支持 StartTLS 等扩展操作
+			writeLDAPResult(conn, id, ldapExtendedResponse, ldapResultProtocolError)
+		default:
+			return
+		}
+	}
+}
+
+func findLDAPEntry(entries []*mockLDAPEntry, dn string) *mockLDAPEntry {
+	for _, entry := range entries {
+		if strings.EqualFold(entry.DN, dn) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// isLDAPSuffix 基准 DN 为目录中某些条目的上级时也视为存在
+func isLDAPSuffix(entries []*mockLDAPEntry, dn string) bool {
+	for _, entry := range entries {
+		if strings.HasSuffix(strings.ToLower(entry.DN), ","+strings.ToLower(dn)) {
+			return true
+		}
+	}
+	return false
+}
+
+func inLDAPScope(dn string, base string, scope int64) bool {
+	dn, base = strings.ToLower(dn), strings.ToLower(base)
+	switch scope {
+	case 0:
+		return dn == base
+	case 1:
+		parent, ok := strings.CutSuffix(dn, ","+base)
+		return ok && !strings.Contains(parent, ",")
+	default:
+		return dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+// matchLDAPFilter 支持 and、or、not、等值匹配与存在性判断
+func matchLDAPFilter(entry *mockLDAPEntry, filter *ber.Packet) bool {
+	switch filter.Tag {
+	case 0:
+		for _, child := range filter.Children {
+			if !matchLDAPFilter(entry, child) {
+				return false
+			}
+		}
+		return true
+	case 1:
+		for _, child := range filter.Children {
+			if matchLDAPFilter(entry, child) {
+				return true
+			}
+		}
+		return false
+	case 2:
+		return len(filter.Children) == 1 && !matchLDAPFilter(entry, filter.Children[0])
+	case 3:
+		name := strings.ToLower(filter.Children[0].Data.String())
+		value := filter.Children[1].Data.String()
+		return slices.ContainsFunc(entry.Attributes[name], func(item string) bool {
+			return strings.EqualFold(item, value)
+		})
+	case 7:
+		name := strings.ToLower(filter.Data.String())
+		return name == "objectclass" || len(entry.Attributes[name]) > 0
+	default:
+		return false
+	}
+}
+
+func ldapSearchEntry(id int64, entry *mockLDAPEntry, attributes []string) *ber.Packet {
+	packet := ldapEnvelope(id)
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldapSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, "Object Name"))
+	list := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+	for name, values := range entry.Attributes {
+		if name == "userpassword" || (len(attributes) > 0 && !slices.Contains(attributes, name) && !slices.Contains(attributes, "*")) {
+			continue
+		}
+		attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		list.AppendChild(attribute)
+	}
+	op.AppendChild(list)
+	packet.AppendChild(op)
+	return packet
+}
+
+func writeLDAPResult(conn net.Conn, id int64, tag ber.Tag, code int) {
+	packet := ldapEnvelope(id)
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	packet.AppendChild(op)
+	conn.Write(packet.Bytes())
+}
+
+func ldapEnvelope(id int64) *ber.Packet {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	return packet
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"server/pkg/crypto"
+	"server/pkg/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const mockOIDCKid = "mock"
+
+type mockAuthorization struct {
+	ClientID      string
+	RedirectURI   string
+	Nonce         string
+	CodeChallenge string
+	ExpiresAt     time.Time
+}
+
+// 本地模拟 OIDC 身份提供方，授权请求不经确认直接以指定用户登录，例如：
+// server oidc-mock -addr :9001 -user alice -email alice@example.com -groups admins
+func runOIDCMock(args []string) error {
+	flags := flag.NewFlagSet("oidc-mock", flag.ContinueOnError)
+	addr := flags.String("addr", ":9001", "address to listen on")
+	issuer := flags.String("issuer", "http://127.0.0.1:9001", "issuer, must match oidc.issuer")
+	clientId := flags.String("client-id", "kanboard", "accepted client id")
+	subject := flags.String("sub", "", "subject of the user, defaults to the username")
+	username := flags.String("user", "alice", "preferred_username claim")
+	email := flags.String("email", "", "email claim")
+	groups := flags.String("groups", "", "comma separated groups claim")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *subject == "" {
+		*subject = *username
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	var mu sync.Mutex
+	codes := map[string]mockAuthorization{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidc.DISCOVERY_PATH, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                *issuer,
+			"authorization_endpoint":                *issuer + "/authorize",
+			"token_endpoint":                        *issuer + "/token",
+			"jwks_uri":                              *issuer + "/jwks",
+			"response_types_supported":              []string{"code"},
+			"subject_types_supported":               []string{"public"},
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+			"code_challenge_methods_supported":      []string{oidc.CHALLENGE_METHOD},
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, oidc.JSONWebKeySet{Keys: []oidc.JSONWebKey{oidc.NewRSAKey(mockOIDCKid, &key.PublicKey)}})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		redirectURI, err := url.Parse(query.Get("redirect_uri"))
+		if err != nil || redirectURI.Scheme == "" {
+			http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+			return
+		}
+		if query.Get("response_type") != "code" || query.Get("client_id") != *clientId ||
+			query.Get("code_challenge_method") != oidc.CHALLENGE_METHOD || query.Get("code_challenge") == "" {
+			http.Error(w, "invalid authorization request", http.StatusBadRequest)
+			return
+		}
+
+		code, _ := crypto.GenerateRandomToken(16)
+		mu.Lock()
+		codes[code] = mockAuthorization{
+			ClientID:      query.Get("client_id"),
+			RedirectURI:   query.Get("redirect_uri"),
+			Nonce:         query.Get("nonce"),
+			CodeChallenge: query.Get("code_challenge"),
+			ExpiresAt:     time.Now().Add(time.Minute),
+		}
+		mu.Unlock()
+
+		values := redirectURI.Query()
+		values.Set("code", code)
+		values.Set("state", query.Get("state"))
+		redirectURI.RawQuery = values.Encode()
+		fmt.Printf("authorize client=%s user=%s\n", *clientId, *username)
+		http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.ParseForm() != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+			return
+		}
+		mu.Lock()
+		authorization, ok := codes[r.PostForm.Get("code")]
+		delete(codes, r.PostForm.Get("code"))
+		mu.Unlock()
+
+		if !ok || time.Now().After(authorization.ExpiresAt) || r.PostForm.Get("grant_type") != "authorization_code" ||
+			r.PostForm.Get("redirect_uri") != authorization.RedirectURI ||
+			oidc.CodeChallenge(r.PostForm.Get("code_verifier")) != authorization.CodeChallenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		now := time.Now()
+		claims := jwt.MapClaims{
+			"iss":                *issuer,
+			"sub":                *subject,
+			"aud":                authorization.ClientID,
+			"iat":                now.Unix(),
+			"exp":                now.Add(5 * time.Minute).Unix(),
+			"nonce":              authorization.Nonce,
+			"preferred_username": *username,
+		}
+		if *email != "" {
+			claims["email"] = *email
+			claims["email_verified"] = true
+		}
+		if *groups != "" {
+			claims["groups"] = strings.Split(*groups, ",")
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = mockOIDCKid
+		idToken, err := token.SignedString(key)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+			return
+		}
+		accessToken, _ := crypto.GenerateRandomToken(16)
+		writeJSON(w, http.StatusOK, oidc.Token{
+			AccessToken: accessToken,
+			TokenType:   "Bearer",
+			IDToken:     idToken,
+			ExpiresIn:   300,
+		})
+	})
+
+	fmt.Printf("oidc mock provider listening on %s, issuer %s\n", *addr, *issuer)
+	return http.ListenAndServe(*addr, mux)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
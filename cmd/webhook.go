@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
+	"server/pkg/webhook"
+)
+
+// 本地接收 webhook 并打印签名校验结果，例如：
+// server webhook-receiver -addr :9000 -secret xxx
+// 投递到本机地址需要在配置中开启 webhook.allowPrivateNetwork
+func runWebhookReceiver(args []string) error {
+	flags := flag.NewFlagSet("webhook-receiver", flag.ContinueOnError)
+	addr := flags.String("addr", ":9000", "address to listen on")
+	secret := flags.String("secret", "", "webhook secret used to verify signatures")
+	status := flags.Int("status", http.StatusOK, "status code to respond with")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		verified := "skipped"
+		if *secret != "" {
+			verified = fmt.Sprint(webhook.Verify(*secret, body, r.Header.Get(webhook.HEADER_SIGNATURE)))
+		}
+		fmt.Printf("event=%s delivery=%s verified=%s\n%s\n\n",
+			r.Header.Get(webhook.HEADER_EVENT), r.Header.Get(webhook.HEADER_DELIVERY), verified, body)
+		w.WriteHeader(*status)
+	})
+
+	fmt.Printf("webhook receiver listening on %s\n", *addr)
+	return http.ListenAndServe(*addr, handler)
+}
@@ -15,6 +15,7 @@ func setServerDefaultConfig() {
 	viper.SetDefault("server.host", "127.0.0.1")
 	viper.SetDefault("server.port", "9999")
 	viper.SetDefault("server.timeout", 5)
+	viper.SetDefault("server.trustedProxies", []string{})
 }
 
 func setLogDefaultConfig() {
@@ -39,8 +40,75 @@ func setRedisDefaultConfig() {
 }
 
 func setJWTDefaultConfig() {
+	viper.SetDefault("jwt.issuer", "kanboard")
 	viper.SetDefault("jwt.kanboardTokenExpiration", 30*24*time.Hour)
 	viper.SetDefault("jwt.adminTokenExpiration", 4*time.Hour)
+	viper.SetDefault("jwt.accessTokenExpiration", 15)
+}
+
+func setStatisticsDefaultConfig() {
+	viper.SetDefault("statistics.weekStart", 1)
+}
+
+func setCalendarDefaultConfig() {
+	viper.SetDefault("calendar.feedUrl", "http://127.0.0.1:9999/api/kanboard/feed")
+	viper.SetDefault("calendar.taskLink", "")
+	viper.SetDefault("calendar.pastDays", 90)
+	viper.SetDefault("calendar.refresh", 60)
+}
+
+func setMailDefaultConfig() {
+	viper.SetDefault("mail.transport", "log")
+	viper.SetDefault("mail.host", "127.0.0.1")
+	viper.SetDefault("mail.port", 1025)
+	viper.SetDefault("mail.from", "Kanboard <noreply@kanboard.local>")
+	viper.SetDefault("mail.resetLink", "http://127.0.0.1:5173/reset-password?token={token}")
+	viper.SetDefault("mail.verifyLink", "http://127.0.0.1:5173/verify-email?token={token}")
+	viper.SetDefault("mail.requireVerification", "")
+}
+
+func setTwoFactorDefaultConfig() {
+	viper.SetDefault("twoFactor.issuer", "Kanboard")
+	viper.SetDefault("twoFactor.requireAdmin", false)
+}
+
+func setOIDCDefaultConfig() {
+	viper.SetDefault("oidc.enabled", false)
+	viper.SetDefault("oidc.redirectUrl", "http://127.0.0.1:5173/oidc/callback")
+	viper.SetDefault("oidc.scopes", []string{"openid", "profile", "email"})
+	viper.SetDefault("oidc.timeout", 10)
+	viper.SetDefault("oidc.usernameClaim", "preferred_username")
+	viper.SetDefault("oidc.emailClaim", "email")
+	viper.SetDefault("oidc.groupsClaim", "")
+	viper.SetDefault("oidc.adminGroups", []string{})
+	viper.SetDefault("oidc.linkByEmail", false)
+}
+
+func setLDAPDefaultConfig() {
+	viper.SetDefault("ldap.enabled", false)
+	viper.SetDefault("ldap.url", "ldap://127.0.0.1:389")
+	viper.SetDefault("ldap.startTLS", false)
+	viper.SetDefault("ldap.insecureSkipVerify", false)
+	viper.SetDefault("ldap.timeout", 5)
+	viper.SetDefault("ldap.userFilter", "(&(objectClass=person)(uid={username}))")
+	viper.SetDefault("ldap.usernameAttribute", "uid")
+	viper.SetDefault("ldap.emailAttribute", "mail")
+	viper.SetDefault("ldap.adminGroupDn", "")
+	viper.SetDefault("ldap.localFallback", true)
+	viper.SetDefault("ldap.linkExisting", false)
+}
+
+func setLockoutDefaultConfig() {
+	viper.SetDefault("lockout.maxFailures", 5)
+	viper.SetDefault("lockout.ipMaxFailures", 50)
+	viper.SetDefault("lockout.delayAfter", 3)
+	viper.SetDefault("lockout.maxDelay", 30)
+	viper.SetDefault("lockout.window", 15)
+	viper.SetDefault("lockout.duration", 15)
+}
+
+func setWebhookDefaultConfig() {
+	viper.SetDefault("webhook.allowPrivateNetwork", false)
 }
 
 func setFileDefaultConfig() {
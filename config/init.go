@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"server/internal/constant"
@@ -19,6 +20,14 @@ func InitConfig() {
 	initRedisConfig()
 	initJWTConfig()
 	initFileConfig()
+	initStatisticsConfig()
+	initCalendarConfig()
+	initMailConfig()
+	initTwoFactorConfig()
+	initOIDCConfig()
+	initLDAPConfig()
+	initLockoutConfig()
+	initWebhookConfig()
 	initGinConfig()
 }
 
@@ -45,10 +54,11 @@ func initEnvConfig() {
 func initServerConfig() {
 	setServerDefaultConfig()
 	constant.ServerConfig = &types.Server{
-		Name:    viper.GetString("server.name"),
-		Host:    viper.GetString("server.host"),
-		Port:    viper.GetString("server.port"),
-		Timeout: viper.GetDuration("server.timeout") * time.Second,
+		Name:           viper.GetString("server.name"),
+		Host:           viper.GetString("server.host"),
+		Port:           viper.GetString("server.port"),
+		Timeout:        viper.GetDuration("server.timeout") * time.Second,
+		TrustedProxies: viper.GetStringSlice("server.trustedProxies"),
 	}
 }
 
@@ -84,10 +94,18 @@ func initRedisConfig() {
 
 func initJWTConfig() {
 	setJWTDefaultConfig()
+	keys := []types.JWTKey{}
+	if err := viper.UnmarshalKey("jwt.keys", &keys); err != nil {
+		fmt.Printf("jwt keys: %s\n\n", err)
+	}
 	constant.JWTConfig = &types.JWT{
 		Secret:                  viper.GetString("jwt.secret"),
+		Issuer:                  viper.GetString("jwt.issuer"),
+		ActiveKid:               viper.GetString("jwt.activeKid"),
+		Keys:                    keys,
 		KanboardTokenExpiration: viper.GetDuration("jwt.kanboardTokenExpiration") * 24 * time.Hour,
 		AdminTokenExpiration:    viper.GetDuration("jwt.adminTokenExpiration") * time.Hour,
+		AccessTokenExpiration:   viper.GetDuration("jwt.accessTokenExpiration") * time.Minute,
 	}
 }
 
@@ -98,3 +116,101 @@ func initFileConfig() {
 		Static: viper.GetString("file.static"),
 	}
 }
+
+func initStatisticsConfig() {
+	setStatisticsDefaultConfig()
+	constant.StatisticsConfig = &types.Statistics{
+		// 负数同样取模到 0-6
+		WeekStart: time.Weekday((viper.GetInt("statistics.weekStart")%7 + 7) % 7),
+	}
+}
+
+func initMailConfig() {
+	setMailDefaultConfig()
+	constant.MailConfig = &types.Mail{
+		Transport:           viper.GetString("mail.transport"),
+		Host:                viper.GetString("mail.host"),
+		Port:                viper.GetInt("mail.port"),
+		Username:            viper.GetString("mail.username"),
+		Password:            viper.GetString("mail.password"),
+		From:                viper.GetString("mail.from"),
+		ResetLink:           viper.GetString("mail.resetLink"),
+		VerifyLink:          viper.GetString("mail.verifyLink"),
+		RequireVerification: viper.GetString("mail.requireVerification"),
+	}
+}
+
+func initTwoFactorConfig() {
+	setTwoFactorDefaultConfig()
+	constant.TwoFactorConfig = &types.TwoFactor{
+		Issuer:       viper.GetString("twoFactor.issuer"),
+		RequireAdmin: viper.GetBool("twoFactor.requireAdmin"),
+	}
+}
+
+func initOIDCConfig() {
+	setOIDCDefaultConfig()
+	constant.OIDCConfig = &types.OIDC{
+		Enabled:       viper.GetBool("oidc.enabled"),
+		Issuer:        viper.GetString("oidc.issuer"),
+		ClientID:      viper.GetString("oidc.clientId"),
+		ClientSecret:  viper.GetString("oidc.clientSecret"),
+		RedirectURL:   viper.GetString("oidc.redirectUrl"),
+		Scopes:        viper.GetStringSlice("oidc.scopes"),
+		Timeout:       viper.GetDuration("oidc.timeout") * time.Second,
+		UsernameClaim: viper.GetString("oidc.usernameClaim"),
+		EmailClaim:    viper.GetString("oidc.emailClaim"),
+		GroupsClaim:   viper.GetString("oidc.groupsClaim"),
+		AdminGroups:   viper.GetStringSlice("oidc.adminGroups"),
+		LinkByEmail:   viper.GetBool("oidc.linkByEmail"),
+	}
+}
+
+func initLDAPConfig() {
+	setLDAPDefaultConfig()
+	constant.LDAPConfig = &types.LDAP{
+		Enabled:            viper.GetBool("ldap.enabled"),
+		URL:                viper.GetString("ldap.url"),
+		StartTLS:           viper.GetBool("ldap.startTLS"),
+		InsecureSkipVerify: viper.GetBool("ldap.insecureSkipVerify"),
+		Timeout:            viper.GetDuration("ldap.timeout") * time.Second,
+		BindDN:             viper.GetString("ldap.bindDn"),
+		BindPassword:       viper.GetString("ldap.bindPassword"),
+		BaseDN:             viper.GetString("ldap.baseDn"),
+		UserFilter:         viper.GetString("ldap.userFilter"),
+		UsernameAttribute:  viper.GetString("ldap.usernameAttribute"),
+		EmailAttribute:     viper.GetString("ldap.emailAttribute"),
+		AdminGroupDN:       viper.GetString("ldap.adminGroupDn"),
+		LocalFallback:      viper.GetBool("ldap.localFallback"),
+		LinkExisting:       viper.GetBool("ldap.linkExisting"),
+	}
+}
+
+func initLockoutConfig() {
+	setLockoutDefaultConfig()
+	constant.LockoutConfig = &types.Lockout{
+		MaxFailures:   viper.GetInt("lockout.maxFailures"),
+		IPMaxFailures: viper.GetInt("lockout.ipMaxFailures"),
+		DelayAfter:    viper.GetInt("lockout.delayAfter"),
+		MaxDelay:      viper.GetDuration("lockout.maxDelay") * time.Second,
+		Window:        viper.GetDuration("lockout.window") * time.Minute,
+		Duration:      viper.GetDuration("lockout.duration") * time.Minute,
+	}
+}
+
+func initWebhookConfig() {
+	setWebhookDefaultConfig()
+	constant.WebhookConfig = &types.Webhook{
+		AllowPrivateNetwork: viper.GetBool("webhook.allowPrivateNetwork"),
+	}
+}
+
+func initCalendarConfig() {
+	setCalendarDefaultConfig()
+	constant.CalendarConfig = &types.Calendar{
+		FeedURL:  strings.TrimRight(viper.GetString("calendar.feedUrl"), "/"),
+		TaskLink: viper.GetString("calendar.taskLink"),
+		PastDays: viper.GetInt("calendar.pastDays"),
+		Refresh:  viper.GetDuration("calendar.refresh") * time.Minute,
+	}
+}
@@ -0,0 +1,185 @@
+package accesstoken
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"server/internal/constant"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/crypto"
+)
+
+const (
+	TOKEN_SIZE          = 32
+	MAX_TOKENS_PER_USER = 50
+	// 最近使用时间的更新间隔，避免每次请求都写数据库
+	TOUCH_INTERVAL = time.Minute
+	HINT_SIZE      = 4
+)
+
+var (
+	ErrInvalidToken = errors.New("Invalid access token")
+	ErrUserDisabled = errors.New("User is disabled")
+)
+
+// Scopes 为可以授予的全部权限范围
+var Scopes = []string{
+	constant.SCOPE_TASKS_READ,
+	constant.SCOPE_TASKS_WRITE,
+	constant.SCOPE_PROJECTS_READ,
+	constant.SCOPE_MESSAGES_READ,
+	constant.SCOPE_MESSAGES_WRITE,
+}
+
+// 个人访问令牌只能访问以下看板接口，键为请求方法与 /:id 之后的路径
+// 未列出的接口一律拒绝，包括令牌、会话与账号管理接口
+var routeScopes = map[string]string{
+	"GET /tasks":                 constant.SCOPE_TASKS_READ,
+	"GET /tasksByProjectId":      constant.SCOPE_TASKS_READ,
+	"GET /tasksByUserId":         constant.SCOPE_TASKS_READ,
+	"GET /userTasks":             constant.SCOPE_TASKS_READ,
+	"GET /getTask":               constant.SCOPE_TASKS_READ,
+	"POST /searchTask":           constant.SCOPE_TASKS_READ,
+	"GET /timeline":              constant.SCOPE_TASKS_READ,
+	"GET /exportTasks":           constant.SCOPE_TASKS_READ,
+	"GET /calendarTasks":         constant.SCOPE_TASKS_READ,
+	"GET /taskCommits":           constant.SCOPE_TASKS_READ,
+	"POST /createTask":           constant.SCOPE_TASKS_WRITE,
+	"POST /updateTask":           constant.SCOPE_TASKS_WRITE,
+	"POST /updateTaskStatus":     constant.SCOPE_TASKS_WRITE,
+	"DELETE /deleteTask":         constant.SCOPE_TASKS_WRITE,
+	"POST /addTaskAssignee":      constant.SCOPE_TASKS_WRITE,
+	"POST /removeTaskAssignee":   constant.SCOPE_TASKS_WRITE,
+	"POST /rescheduleTask":       constant.SCOPE_TASKS_WRITE,
+	"POST /addTaskDependency":    constant.SCOPE_TASKS_WRITE,
+	"POST /removeTaskDependency": constant.SCOPE_TASKS_WRITE,
+	"POST /importTasks":          constant.SCOPE_TASKS_WRITE,
+	"GET /project":               constant.SCOPE_PROJECTS_READ,
+	"POST /getProjectMembers":    constant.SCOPE_PROJECTS_READ,
+	"POST /getMembers":           constant.SCOPE_PROJECTS_READ,
+	"GET /projectRole":           constant.SCOPE_PROJECTS_READ,
+	"GET /cumulativeFlow":        constant.SCOPE_PROJECTS_READ,
+	"GET /burndown":              constant.SCOPE_PROJECTS_READ,
+	"GET /flowMetrics":           constant.SCOPE_PROJECTS_READ,
+	"GET /unreadMsgs":            constant.SCOPE_MESSAGES_READ,
+	"GET /readedMsgs":            constant.SCOPE_MESSAGES_READ,
+	"POST /getMsgsByProjectId":   constant.SCOPE_MESSAGES_READ,
+	"POST /markReadMsg":          constant.SCOPE_MESSAGES_WRITE,
+}
+
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, constant.ACCESS_TOKEN_PREFIX)
+}
+
+// RouteScope 返回访问该路由所需的权限范围，fullPath 为 gin 注册的完整路由
+func RouteScope(method string, fullPath string) (string, bool) {
+	_, path, ok := strings.Cut(fullPath, "/:id")
+	if !ok {
+		return "", false
+	}
+	scope, ok := routeScopes[method+" "+path]
+	return scope, ok
+}
+
+// normalizeScopes 去重并校验权限范围
+func normalizeScopes(scopes []string) ([]string, error) {
+	result := []string{}
+	for _, scope := range scopes {
+		if !slices.Contains(Scopes, scope) {
+			return nil, errors.New("无效的权限范围：" + scope)
+		}
+		if !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+	if len(result) == 0 {
+		return nil, errors.New("请至少选择一个权限范围")
+	}
+	return result, nil
+}
+
+// Create 返回的明文令牌只展示一次
+func Create(userId uint, name string, scopes []string, expiresAt *time.Time) (string, *models.AccessToken, error) {
+	scopes, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return "", nil, errors.New("过期时间必须晚于当前时间")
+	}
+
+	accessTokenRepo := repositories.NewAccessTokenRepo()
+	count, err := accessTokenRepo.GetAccessTokenCountByUserId(userId)
+	if err != nil {
+		return "", nil, err
+	}
+	if count >= MAX_TOKENS_PER_USER {
+		return "", nil, errors.New("访问令牌数量已达上限")
+	}
+
+	random, err := crypto.GenerateRandomToken(TOKEN_SIZE)
+	if err != nil {
+		return "", nil, errors.New("token生成失败")
+	}
+	token := constant.ACCESS_TOKEN_PREFIX + random
+	accessToken, err := accessTokenRepo.CreateAccessToken(models.AccessToken{
+		UserID:    userId,
+		Name:      name,
+		TokenHash: crypto.HashToken(token),
+		Hint:      token[len(token)-HINT_SIZE:],
+		Scopes:    strings.Join(scopes, ","),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return token, accessToken, nil
+}
+
+func List(userId uint) (*[]models.AccessToken, error) {
+	return repositories.NewAccessTokenRepo().GetAccessTokensByUserId(userId)
+}
+
+func Revoke(userId uint, id uint) error {
+	rows, err := repositories.NewAccessTokenRepo().DeleteAccessToken(id, userId)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return errors.New("访问令牌不存在")
+	}
+	return nil
+}
+
+func RevokeAll(userId uint) error {
+	return repositories.NewAccessTokenRepo().DeleteAccessTokensByUserId(userId)
+}
+
+// Authenticate 校验令牌并返回所属用户，同时记录最近使用时间与 IP
+func Authenticate(token string, ip string) (*models.AccessToken, *models.User, error) {
+	accessTokenRepo := repositories.NewAccessTokenRepo()
+	accessToken, err := accessTokenRepo.GetAccessTokenByHash(crypto.HashToken(token))
+	if err != nil || accessToken.ID == 0 {
+		return nil, nil, ErrInvalidToken
+	}
+	now := time.Now()
+	if accessToken.Expired(now) {
+		return nil, nil, errors.New("Access token expired")
+	}
+	user, err := repositories.NewUserRepo().GetUserById(accessToken.UserID)
+	if err != nil || user.ID == 0 {
+		return nil, nil, ErrInvalidToken
+	}
+	// 账号被禁用期间令牌不可用，启用后恢复
+	if !user.Loginable {
+		return nil, nil, ErrUserDisabled
+	}
+
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) > TOUCH_INTERVAL || accessToken.LastUsedIP != ip {
+		accessTokenRepo.TouchAccessToken(accessToken.ID, now, ip)
+	}
+	return accessToken, user, nil
+}
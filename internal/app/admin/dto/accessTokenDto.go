@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+type UserAccessTokensDto struct {
+	UserId uint `json:"user_id" form:"user_id" binding:"required"`
+}
+
+type UserAccessTokenDto struct {
+	UserId uint `json:"user_id" binding:"required"`
+	Id     uint `json:"id" binding:"required"`
+}
+
+type AccessTokenResponse struct {
+	Id         uint     `json:"id"`
+	UserId     uint     `json:"user_id"`
+	Name       string   `json:"name"`
+	Hint       string   `json:"hint"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at"`
+	LastUsedAt string   `json:"last_used_at"`
+	LastUsedIP string   `json:"last_used_ip"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+func (a *AccessTokenResponse) Set(accessToken *models.AccessToken) *AccessTokenResponse {
+	a.Id = accessToken.ID
+	a.UserId = accessToken.UserID
+	a.Name = accessToken.Name
+	a.Hint = accessToken.Hint
+	a.Scopes = accessToken.ScopeList()
+	if accessToken.ExpiresAt != nil {
+		a.ExpiresAt = accessToken.ExpiresAt.Local().Format(time.DateTime)
+	}
+	if accessToken.LastUsedAt != nil {
+		a.LastUsedAt = accessToken.LastUsedAt.Local().Format(time.DateTime)
+	}
+	a.LastUsedIP = accessToken.LastUsedIP
+	a.CreatedAt = accessToken.CreatedAt.Local().Format(time.DateTime)
+	return a
+}
@@ -0,0 +1,123 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+type ProjectArchiveDto struct {
+	Id uint `json:"id" form:"id" binding:"required"`
+}
+
+type ProjectRestoreDto struct {
+	Name string `json:"name" form:"name"`
+}
+
+// 项目归档文件 project.json 的内容，资源文件以 resources/<md5><ext> 存放在同一个 zip 中
+type ProjectArchive struct {
+	Version      int                     `json:"version"`
+	ExportedAt   time.Time               `json:"exported_at"`
+	Project      ArchiveProject          `json:"project"`
+	Users        []ArchiveUser           `json:"users"`
+	Members      []models.ProjectMember  `json:"members"`
+	Tasks        []ArchiveTask           `json:"tasks"`
+	Assignees    []models.TaskAssignee   `json:"assignees"`
+	Dependencies []models.TaskDependency `json:"dependencies"`
+	Transitions  []models.TaskTransition `json:"transitions"`
+	Messages     []models.Message        `json:"messages"`
+	Resources    []ArchiveResource       `json:"resources"`
+}
+
+type ArchiveProject struct {
+	Id        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Desc      *string   `json:"desc"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (a *ArchiveProject) Set(project *models.Project) *ArchiveProject {
+	a.Id = project.ID
+	a.Name = project.Name
+	a.Desc = project.Desc
+	a.CreatedAt = project.CreatedAt
+	a.UpdatedAt = project.UpdatedAt
+	return a
+}
+
+type ArchiveUser struct {
+	Id       uint   `json:"id"`
+	Username string `json:"username"`
+}
+
+type ArchiveTask struct {
+	Id          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Desc        string     `json:"desc"`
+	Status      uint       `json:"status"`
+	Priority    int        `json:"priority"`
+	StartDate   *time.Time `json:"start_date"`
+	DueDate     *time.Time `json:"due_date"`
+	CompletedAt *time.Time `json:"completed_at"`
+	CreatorId   uint       `json:"creator_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (a *ArchiveTask) Set(task *models.Task) *ArchiveTask {
+	a.Id = task.ID
+	a.Title = task.Title
+	a.Desc = task.Desc
+	a.Status = task.Status
+	a.Priority = task.Priority
+	if !task.StartDate.IsZero() {
+		a.StartDate = &task.StartDate
+	}
+	if !task.DueDate.IsZero() {
+		a.DueDate = &task.DueDate
+	}
+	a.CompletedAt = task.CompletedAt
+	a.CreatorId = task.CreatorID
+	a.CreatedAt = task.CreatedAt
+	a.UpdatedAt = task.UpdatedAt
+	return a
+}
+
+func (a *ArchiveTask) Model() models.Task {
+	var task models.Task
+	task.ID = a.Id
+	task.Title = a.Title
+	task.Desc = a.Desc
+	task.Status = a.Status
+	task.Priority = a.Priority
+	if a.StartDate != nil {
+		task.StartDate = *a.StartDate
+	}
+	if a.DueDate != nil {
+		task.DueDate = *a.DueDate
+	}
+	task.CompletedAt = a.CompletedAt
+	task.CreatorID = a.CreatorId
+	task.CreatedAt = a.CreatedAt
+	task.UpdatedAt = a.UpdatedAt
+	return task
+}
+
+type ArchiveResource struct {
+	Id         uint   `json:"id"`
+	MD5        string `json:"md5"`
+	FileType   string `json:"file_type"`
+	StaticPath string `json:"static_path"`
+	File       string `json:"file"`
+}
+
+type ProjectRestoreReport struct {
+	ProjectId     uint     `json:"project_id"`
+	Name          string   `json:"name"`
+	Members       int      `json:"members"`
+	Tasks         int      `json:"tasks"`
+	Messages      int      `json:"messages"`
+	Resources     int      `json:"resources"`
+	UnmappedUsers []string `json:"unmapped_users"`
+}
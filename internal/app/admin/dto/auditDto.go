@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+type AuditLogDto struct {
+	ActorId *uint   `json:"actor_id" form:"actor_id"`
+	UserId  *uint   `json:"user_id" form:"user_id"`
+	Action  *string `json:"action" form:"action"`
+	PageRequest
+}
+
+type AuditLogResponse struct {
+	Id        uint   `json:"id"`
+	ActorId   uint   `json:"actor_id"`
+	UserId    uint   `json:"user_id"`
+	Action    string `json:"action"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Status    int    `json:"status"`
+	IP        string `json:"ip"`
+	Detail    string `json:"detail"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (a *AuditLogResponse) Set(log *models.AuditLog) *AuditLogResponse {
+	a.Id = log.ID
+	a.ActorId = log.ActorID
+	a.UserId = log.UserID
+	a.Action = log.Action
+	a.Method = log.Method
+	a.Path = log.Path
+	a.Status = log.Status
+	a.IP = log.IP
+	a.Detail = log.Detail
+	a.CreatedAt = log.CreatedAt.Local().Format(time.DateTime)
+	return a
+}
+
+type AuditLogPageResponse struct {
+	Total     int                `json:"total"`
+	Page      int                `json:"page"`
+	PageSize  int                `json:"page_size"`
+	TotalPage int                `json:"total_page"`
+	Data      []AuditLogResponse `json:"data"`
+}
+
+func (a *AuditLogPageResponse) Set(total int64, page int, pageSize int, data []AuditLogResponse) *AuditLogPageResponse {
+	a.Total = int(total)
+	a.Page = page
+	a.PageSize = pageSize
+	totalPage := int(float64(total) / float64(pageSize))
+	if total%int64(pageSize) != 0 {
+		totalPage++
+	}
+	a.TotalPage = totalPage
+	a.Data = data
+	return a
+}
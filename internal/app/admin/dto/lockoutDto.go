@@ -0,0 +1,6 @@
+package dto
+
+type LockoutDto struct {
+	Type string `json:"type" binding:"required,oneof=user ip"`
+	Key  string `json:"key" binding:"required"`
+}
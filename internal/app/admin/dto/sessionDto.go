@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+type RefreshTokenDto struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type UserSessionsDto struct {
+	UserId uint `json:"user_id" form:"user_id" binding:"required"`
+}
+
+type UserSessionDto struct {
+	UserId uint   `json:"user_id" binding:"required"`
+	Id     string `json:"id" binding:"required"`
+}
+
+type RevokeTokenDto struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type SessionResponse struct {
+	Id         string `json:"id"`
+	UserId     uint   `json:"user_id"`
+	Namespace  string `json:"namespace"`
+	Device     string `json:"device"`
+	IP         string `json:"ip"`
+	LastSeenAt string `json:"last_seen_at"`
+	ExpiresAt  string `json:"expires_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func (s *SessionResponse) Set(session *models.Session) *SessionResponse {
+	s.Id = session.ID
+	s.UserId = session.UserID
+	s.Namespace = session.Namespace
+	s.Device = session.Device
+	s.IP = session.IP
+	s.LastSeenAt = session.LastSeenAt.Local().Format(time.DateTime)
+	s.ExpiresAt = session.ExpiresAt.Local().Format(time.DateTime)
+	s.CreatedAt = session.CreatedAt.Local().Format(time.DateTime)
+	return s
+}
@@ -23,6 +23,7 @@ type TaskResponse struct {
 	Title     string                   `json:"title"`
 	Desc      string                   `json:"desc"`
 	Status    uint                     `json:"status"`
+	StartDate string                   `json:"start_date"`
 	DueDate   string                   `json:"due_date"`
 	Priority  int                      `json:"priority"`
 	ProjectId uint                     `json:"project_id"`
@@ -37,6 +38,9 @@ func (t *TaskResponse) Set(task *models.Task, creator *UserResponse, members *[]
 	t.Title = task.Title
 	t.Desc = task.Desc
 	t.Status = task.Status
+	if !task.StartDate.IsZero() {
+		t.StartDate = task.StartDate.Local().Format(time.DateTime)
+	}
 	if !task.DueDate.IsZero() {
 		t.DueDate = task.DueDate.Local().Format(time.DateTime)
 	}
@@ -16,6 +16,7 @@ type UserLoginRequest struct {
 	Password      string `json:"password" binding:"required" from:"password"`
 	CaptchaID     string `json:"captchaId" binding:"required" from:"captchaId"`
 	CaptchaAnswer string `json:"captchaAnswer" binding:"required" from:"captchaAnswer"`
+	Device        string `json:"device" form:"device"`
 }
 
 type UserRegisterRequest struct {
@@ -60,6 +61,11 @@ type UserSearchRequest struct {
 	PageRequest
 }
 
+type UserLockRequest struct {
+	ID     uint   `json:"id" binding:"required" form:"id"`
+	Reason string `json:"reason" binding:"required,max=255" form:"reason"`
+}
+
 type UserChangePasswordRequest struct {
 	ID          uint   `json:"id" binding:"required" form:"id"`
 	NewPassword string `json:"newPassword" binding:"required" form:"newPassword"`
@@ -118,33 +124,43 @@ func (r *UserPageResponse) Set(total int64, page int, pageSize int, users *[]mod
 }
 
 type UserResponse struct {
-	CreateAt   string          `json:"create_at"`
-	UpdateAt   string          `json:"update_at"`
-	ID         uint            `json:"id"`
-	Username   string          `json:"username"`
-	Avatar     string          `json:"avatar"`
-	Gender     constant.Gender `json:"gender"`
-	Email      string          `json:"email"`
-	Mobile     string          `json:"mobile"`
-	CreateFrom constant.From   `json:"create_from"`
-	Loginable  bool            `json:"loginable"`
-	IsAdmin    bool            `json:"is_admin"`
-	Position   string          `json:"position"`
+	CreateAt      string          `json:"create_at"`
+	UpdateAt      string          `json:"update_at"`
+	ID            uint            `json:"id"`
+	Username      string          `json:"username"`
+	Avatar        string          `json:"avatar"`
+	Gender        constant.Gender `json:"gender"`
+	Email         string          `json:"email"`
+	EmailVerified bool            `json:"email_verified"`
+	Mobile        string          `json:"mobile"`
+	CreateFrom    constant.From   `json:"create_from"`
+	Loginable     bool            `json:"loginable"`
+	LockReason    string          `json:"lock_reason"`
+	LockedAt      string          `json:"locked_at"`
+	IsAdmin       bool            `json:"is_admin"`
+	Position      string          `json:"position"`
 }
 
 func (r *UserResponse) Set(user *models.User, resource *models.Resource) *UserResponse {
+	var lockedAt string
+	if user.LockedAt != nil {
+		lockedAt = user.LockedAt.Local().Format(time.DateTime)
+	}
 	return &UserResponse{
-		CreateAt:   user.CreatedAt.Local().Format(time.DateTime),
-		UpdateAt:   user.UpdatedAt.Local().Format(time.DateTime),
-		ID:         user.ID,
-		Username:   user.Username,
-		Avatar:     resource.StaticPath,
-		Gender:     user.Gender,
-		Email:      user.Email,
-		Mobile:     user.Mobile,
-		CreateFrom: user.CreateFrom,
-		Loginable:  user.Loginable,
-		IsAdmin:    user.IsAdmin,
-		Position:   user.Position,
+		CreateAt:      user.CreatedAt.Local().Format(time.DateTime),
+		UpdateAt:      user.UpdatedAt.Local().Format(time.DateTime),
+		ID:            user.ID,
+		Username:      user.Username,
+		Avatar:        resource.StaticPath,
+		Gender:        user.Gender,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified(),
+		Mobile:        user.Mobile,
+		CreateFrom:    user.CreateFrom,
+		Loginable:     user.Loginable,
+		LockReason:    user.LockReason,
+		LockedAt:      lockedAt,
+		IsAdmin:       user.IsAdmin,
+		Position:      user.Position,
 	}
 }
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"server/internal/app/admin/dto"
+	"server/internal/app/admin/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService *services.AccessTokenService
+}
+
+var accessTokenHandler *AccessTokenHandler
+
+func NewAccessTokenHandler() *AccessTokenHandler {
+	if accessTokenHandler == nil {
+		accessTokenHandler = &AccessTokenHandler{
+			accessTokenService: services.NewAccessTokenService(),
+		}
+	}
+	return accessTokenHandler
+}
+
+func (a AccessTokenHandler) GetUserAccessTokens(ctx *gin.Context) {
+	var request dto.UserAccessTokensDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := a.accessTokenService.GetUserAccessTokens(request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (a AccessTokenHandler) RevokeUserAccessToken(ctx *gin.Context) {
+	var request dto.UserAccessTokenDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := a.accessTokenService.RevokeUserAccessToken(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "吊销成功",
+	})
+}
+
+func (a AccessTokenHandler) RevokeUserAccessTokens(ctx *gin.Context) {
+	var request dto.UserAccessTokensDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := a.accessTokenService.RevokeUserAccessTokens(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "吊销成功",
+	})
+}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"server/internal/app/admin/dto"
+	"server/internal/app/admin/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+var auditHandler *AuditHandler
+
+func NewAuditHandler() *AuditHandler {
+	if auditHandler == nil {
+		auditHandler = &AuditHandler{
+			auditService: services.NewAuditService(),
+		}
+	}
+	return auditHandler
+}
+
+func (a AuditHandler) GetAuditLogs(ctx *gin.Context) {
+	var request dto.AuditLogDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := a.auditService.GetLogs(request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"server/internal/app/admin/dto"
+	"server/internal/app/admin/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LockoutHandler struct {
+	lockoutService *services.LockoutService
+}
+
+var lockoutHandler *LockoutHandler
+
+func NewLockoutHandler() *LockoutHandler {
+	if lockoutHandler == nil {
+		lockoutHandler = &LockoutHandler{
+			lockoutService: services.NewLockoutService(),
+		}
+	}
+	return lockoutHandler
+}
+
+func (l LockoutHandler) GetLockouts(ctx *gin.Context) {
+	data, err := l.lockoutService.GetLockouts()
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (l LockoutHandler) ClearLockout(ctx *gin.Context) {
+	var request dto.LockoutDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := l.lockoutService.ClearLockout(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "解除成功",
+	})
+}
@@ -20,13 +20,9 @@ func NewMessageHandler() *MessageHandler {
 }
 
 func (m MessageHandler) GetUnReadMsgs(ctx *gin.Context) {
-	var request dto.UserIDRequest
+	userId := utils.GetUserId(ctx)
 
-	if err := utils.BindUri(ctx, &request); err != nil {
-		return
-	}
-
-	data := m.messageService.GetUnReadMsgs(request.ID)
+	data := m.messageService.GetUnReadMsgs(userId)
 
 	common.Ok(ctx, common.RspOpts{
 		Data: data,
@@ -34,13 +30,9 @@ func (m MessageHandler) GetUnReadMsgs(ctx *gin.Context) {
 }
 
 func (m MessageHandler) GetReadedMsgs(ctx *gin.Context) {
-	var request dto.UserIDRequest
-
-	if err := utils.BindUri(ctx, &request); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	data := m.messageService.GetReadedMsgs(request.ID)
+	data := m.messageService.GetReadedMsgs(userId)
 
 	common.Ok(ctx, common.RspOpts{
 		Data: data,
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"fmt"
+	"time"
+
 	"server/internal/app/admin/dto"
 	"server/internal/app/admin/services"
 	"server/internal/common"
@@ -11,6 +14,7 @@ import (
 
 type ProjectHandler struct {
 	projectService *services.ProjectService
+	archiveService *services.ArchiveService
 }
 
 var projectHandler *ProjectHandler
@@ -19,6 +23,7 @@ func NewProjectHandler() *ProjectHandler {
 	if projectHandler == nil {
 		projectHandler = &ProjectHandler{
 			projectService: services.NewProjectService(),
+			archiveService: services.NewArchiveService(),
 		}
 	}
 
@@ -184,3 +189,65 @@ func (p ProjectHandler) RemoveProjectMember(ctx *gin.Context) {
 		Msg: "移除项目成员成功",
 	})
 }
+
+func (p ProjectHandler) ExportProject(ctx *gin.Context) {
+	var request dto.ProjectArchiveDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	archive, files, err := p.archiveService.BuildProjectArchive(request.Id)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("project-%d-%s.zip", request.Id, time.Now().Format("20060102150405"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	ctx.Header("Content-Type", "application/zip")
+	if err := p.archiveService.WriteProjectArchive(archive, files, ctx.Writer); err != nil {
+		ctx.Error(err)
+	}
+}
+
+func (p ProjectHandler) RestoreProject(ctx *gin.Context) {
+	var request dto.ProjectRestoreDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: "请上传归档文件",
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := p.archiveService.RestoreProject(file, fileHeader.Size, request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "恢复成功",
+	})
+}
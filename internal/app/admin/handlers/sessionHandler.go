@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"server/internal/app/admin/dto"
+	"server/internal/app/admin/services"
+	"server/internal/common"
+	"server/internal/session"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+var sessionHandler *SessionHandler
+
+func NewSessionHandler() *SessionHandler {
+	if sessionHandler == nil {
+		sessionHandler = &SessionHandler{
+			sessionService: services.NewSessionService(),
+		}
+	}
+	return sessionHandler
+}
+
+func (s SessionHandler) RefreshToken(ctx *gin.Context) {
+	var request dto.RefreshTokenDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := s.sessionService.RefreshToken(request, session.NewClient(ctx, ""))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (s SessionHandler) GetUserSessions(ctx *gin.Context) {
+	var request dto.UserSessionsDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := s.sessionService.GetUserSessions(request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (s SessionHandler) RevokeUserSession(ctx *gin.Context) {
+	var request dto.UserSessionDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := s.sessionService.RevokeUserSession(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "注销成功",
+	})
+}
+
+func (s SessionHandler) RevokeUserSessions(ctx *gin.Context) {
+	var request dto.UserSessionsDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := s.sessionService.RevokeUserSessions(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "注销成功",
+	})
+}
+
+func (s SessionHandler) Logout(ctx *gin.Context) {
+	if err := s.sessionService.Logout(utils.GetPrincipal(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "退出成功",
+	})
+}
+
+func (s SessionHandler) RevokeToken(ctx *gin.Context) {
+	var request dto.RevokeTokenDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := s.sessionService.RevokeToken(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "吊销成功",
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"server/internal/app/admin/dto"
+	"server/internal/app/admin/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TwoFactorHandler struct {
+	twoFactorService *services.TwoFactorService
+}
+
+var twoFactorHandler *TwoFactorHandler
+
+func NewTwoFactorHandler() *TwoFactorHandler {
+	if twoFactorHandler == nil {
+		twoFactorHandler = &TwoFactorHandler{
+			twoFactorService: services.NewTwoFactorService(),
+		}
+	}
+	return twoFactorHandler
+}
+
+func (t TwoFactorHandler) GetStatus(ctx *gin.Context) {
+	data, err := t.twoFactorService.GetStatus(utils.GetUserId(ctx))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (t TwoFactorHandler) Setup(ctx *gin.Context) {
+	var request dto.TwoFactorPasswordRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := t.twoFactorService.Setup(utils.GetUserId(ctx), request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (t TwoFactorHandler) Enable(ctx *gin.Context) {
+	var request dto.TwoFactorCodeRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := t.twoFactorService.Enable(utils.GetUserId(ctx), request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: gin.H{"recovery_codes": data},
+		Msg:  "两步验证已开启",
+	})
+}
+
+func (t TwoFactorHandler) Disable(ctx *gin.Context) {
+	var request dto.TwoFactorCodeRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := t.twoFactorService.Disable(utils.GetUserId(ctx), request); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "两步验证已关闭",
+	})
+}
+
+func (t TwoFactorHandler) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var request dto.TwoFactorCodeRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := t.twoFactorService.RegenerateRecoveryCodes(utils.GetUserId(ctx), request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: gin.H{"recovery_codes": data},
+	})
+}
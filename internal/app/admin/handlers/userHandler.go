@@ -4,6 +4,7 @@ import (
 	"server/internal/app/admin/dto"
 	"server/internal/app/admin/services"
 	"server/internal/common"
+	"server/internal/session"
 	"server/internal/utils"
 
 	"github.com/gin-gonic/gin"
@@ -43,7 +44,32 @@ func (u UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	data, token, err := u.userService.Login(loginRequest)
+	data, err := u.userService.Login(loginRequest, session.NewClient(ctx, loginRequest.Device))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	msg := "登录成功"
+	if data.TwoFactorRequired {
+		msg = "请完成两步验证"
+	}
+	common.Ok(ctx, common.RspOpts{
+		Msg:  msg,
+		Data: data,
+	})
+}
+
+func (u UserHandler) LoginTwoFactor(ctx *gin.Context) {
+	var request dto.TwoFactorLoginRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := u.userService.LoginTwoFactor(request)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -52,11 +78,28 @@ func (u UserHandler) Login(ctx *gin.Context) {
 	}
 
 	common.Ok(ctx, common.RspOpts{
-		Msg: "登录成功",
-		Data: gin.H{
-			"token": token,
-			"user":  data,
-		},
+		Msg:  "登录成功",
+		Data: data,
+	})
+}
+
+func (u UserHandler) LoginTwoFactorSetup(ctx *gin.Context) {
+	var request dto.TwoFactorChallengeRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := u.userService.LoginTwoFactorSetup(request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
 	})
 }
 
@@ -214,6 +257,44 @@ func (u UserHandler) UpdateUser(ctx *gin.Context) {
 	})
 }
 
+func (u UserHandler) LockUser(ctx *gin.Context) {
+	var request dto.UserLockRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := u.userService.LockUser(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "禁用成功",
+	})
+}
+
+func (u UserHandler) UnlockUser(ctx *gin.Context) {
+	var idDto dto.UserIDRequest
+
+	if err := utils.BindRequest(ctx, &idDto); err != nil {
+		return
+	}
+
+	if err := u.userService.UnlockUser(idDto, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "启用成功",
+	})
+}
+
 func (u UserHandler) DeleteUser(ctx *gin.Context) {
 	var idDto dto.UserIDRequest
 
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"strconv"
+
+	"server/internal/accesstoken"
+	"server/internal/app/admin/dto"
+	"server/internal/constant"
+	"server/internal/models"
+)
+
+type AccessTokenService struct {
+	auditService *AuditService
+}
+
+var accessTokenService *AccessTokenService
+
+func NewAccessTokenService() *AccessTokenService {
+	if accessTokenService == nil {
+		accessTokenService = &AccessTokenService{
+			auditService: NewAuditService(),
+		}
+	}
+	return accessTokenService
+}
+
+func (a *AccessTokenService) GetUserAccessTokens(request dto.UserAccessTokensDto) ([]dto.AccessTokenResponse, error) {
+	accessTokens, err := accesstoken.List(request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []dto.AccessTokenResponse{}
+	for _, item := range *accessTokens {
+		var response dto.AccessTokenResponse
+		data = append(data, *response.Set(&item))
+	}
+	return data, nil
+}
+
+func (a *AccessTokenService) RevokeUserAccessToken(request dto.UserAccessTokenDto, adminId uint) error {
+	if err := accesstoken.Revoke(request.UserId, request.Id); err != nil {
+		return err
+	}
+
+	a.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  request.UserId,
+		Action:  constant.AUDIT_ACTION_REVOKE_ACCESS_TOKEN,
+		Detail:  strconv.Itoa(int(request.Id)),
+	})
+	return nil
+}
+
+func (a *AccessTokenService) RevokeUserAccessTokens(request dto.UserAccessTokensDto, adminId uint) error {
+	if err := accesstoken.RevokeAll(request.UserId); err != nil {
+		return err
+	}
+
+	a.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  request.UserId,
+		Action:  constant.AUDIT_ACTION_REVOKE_ACCESS_TOKEN,
+		Detail:  "all",
+	})
+	return nil
+}
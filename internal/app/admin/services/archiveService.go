@@ -0,0 +1,436 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/internal/app/admin/dto"
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/utils"
+)
+
+const (
+	PROJECT_ARCHIVE_VERSION  = 1
+	PROJECT_ARCHIVE_MANIFEST = "project.json"
+	PROJECT_ARCHIVE_RESOURCE = "resources"
+	// 归档内单个文件解压后的大小上限
+	PROJECT_ARCHIVE_MAX_FILE_SIZE = 100 << 20
+	ARCHIVE_MESSAGE_SCAN_COUNT    = 100
+)
+
+var (
+	archiveMD5Pattern      = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+	archiveFileTypePattern = regexp.MustCompile(`^\w[\w.+-]*/\w[\w.+-]*$`) // 不能以 . 开头，排除 . 与 .. 路径
+	archiveExtPattern      = regexp.MustCompile(`^\.[0-9A-Za-z]+$`)
+)
+
+type ArchiveService struct {
+	archiveRepo  *repositories.ProjectArchiveRepo
+	projectRepo  *repositories.ProjectRepo
+	userRepo     *repositories.UserRepo
+	resourceRepo *repositories.ResourceRepo
+	msgRepo      *repositories.MessageRepo
+}
+
+var archiveService *ArchiveService
+
+func NewArchiveService() *ArchiveService {
+	if archiveService == nil {
+		archiveService = &ArchiveService{
+			archiveRepo:  repositories.NewProjectArchiveRepo(),
+			projectRepo:  repositories.NewProjectRepo(),
+			userRepo:     repositories.NewUserRepo(),
+			resourceRepo: repositories.NewResourceRepo(),
+			msgRepo:      repositories.NewMessageRepo(),
+		}
+	}
+	return archiveService
+}
+
+func (a *ArchiveService) BuildProjectArchive(projectId uint) (*dto.ProjectArchive, map[string]string, error) {
+	if !a.projectRepo.CheckProjectExistById(projectId) {
+		return nil, nil, errors.New("项目不存在")
+	}
+	snapshot, err := a.archiveRepo.GetProjectSnapshot(projectId)
+	if err != nil {
+		return nil, nil, err
+	}
+	messages, err := a.msgRepo.GetAllMsgsByProjectId(ARCHIVE_MESSAGE_SCAN_COUNT, projectId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	archive := &dto.ProjectArchive{
+		Version:      PROJECT_ARCHIVE_VERSION,
+		ExportedAt:   time.Now(),
+		Users:        []dto.ArchiveUser{},
+		Members:      snapshot.Members,
+		Tasks:        []dto.ArchiveTask{},
+		Assignees:    snapshot.Assignees,
+		Dependencies: snapshot.Dependencies,
+		Transitions:  snapshot.Transitions,
+		Messages:     messages,
+		Resources:    []dto.ArchiveResource{},
+	}
+	archive.Project.Set(&snapshot.Project)
+
+	// 归档中只保存用户名，恢复时按用户名重新映射用户 ID
+	userIds := []uint{}
+	for _, member := range snapshot.Members {
+		userIds = append(userIds, member.UserID)
+	}
+	for _, task := range snapshot.Tasks {
+		var archiveTask dto.ArchiveTask
+		archive.Tasks = append(archive.Tasks, *archiveTask.Set(&task))
+		userIds = append(userIds, task.CreatorID)
+	}
+	for _, assignee := range snapshot.Assignees {
+		userIds = append(userIds, assignee.UserID)
+	}
+	for _, transition := range snapshot.Transitions {
+		userIds = append(userIds, transition.ActorID)
+	}
+	for _, message := range messages {
+		userIds = append(userIds, message.To...)
+	}
+	users, err := a.userRepo.GetUsersByIds(utils.UniqueUintSlice(userIds))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, user := range *users {
+		archive.Users = append(archive.Users, dto.ArchiveUser{Id: user.ID, Username: user.Username})
+	}
+
+	// 任务描述中引用的上传文件
+	files := make(map[string]string)
+	staticPaths := a.getReferencedStaticPaths(snapshot.Tasks)
+	if len(staticPaths) > 0 {
+		resources, err := a.resourceRepo.GetResourcesByStaticPaths(staticPaths)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, resource := range *resources {
+			if _, err := os.Stat(resource.FilePath); err != nil {
+				global.Logger.Warnw("archive resource missing", "resource", resource.ID, "path", resource.FilePath)
+				continue
+			}
+			file := fmt.Sprintf("%s/%s%s", PROJECT_ARCHIVE_RESOURCE, resource.MD5, filepath.Ext(resource.FilePath))
+			archive.Resources = append(archive.Resources, dto.ArchiveResource{
+				Id:         resource.ID,
+				MD5:        resource.MD5,
+				FileType:   resource.FileType,
+				StaticPath: resource.StaticPath,
+				File:       file,
+			})
+			files[file] = resource.FilePath
+		}
+	}
+
+	return archive, files, nil
+}
+
+func (a *ArchiveService) WriteProjectArchive(archive *dto.ProjectArchive, files map[string]string, w io.Writer) error {
+	writer := zip.NewWriter(w)
+
+	manifest, err := writer.Create(PROJECT_ARCHIVE_MANIFEST)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(archive); err != nil {
+		return err
+	}
+
+	for _, resource := range archive.Resources {
+		entry, err := writer.Create(resource.File)
+		if err != nil {
+			return err
+		}
+		file, err := os.Open(files[resource.File])
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, file)
+		file.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func (a *ArchiveService) ExportProject(projectId uint, w io.Writer) error {
+	archive, files, err := a.BuildProjectArchive(projectId)
+	if err != nil {
+		return err
+	}
+	return a.WriteProjectArchive(archive, files, w)
+}
+
+func (a *ArchiveService) RestoreProject(reader io.ReaderAt, size int64, request dto.ProjectRestoreDto, userId uint) (*dto.ProjectRestoreReport, error) {
+	zipReader, err := zip.NewReader(reader, size)
+	if err != nil {
+		return nil, errors.New("归档文件格式错误")
+	}
+	entries := make(map[string]*zip.File)
+	for _, file := range zipReader.File {
+		entries[file.Name] = file
+	}
+
+	manifest, ok := entries[PROJECT_ARCHIVE_MANIFEST]
+	if !ok {
+		return nil, errors.New("归档文件缺少 project.json")
+	}
+	data, err := readArchiveEntry(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var archive dto.ProjectArchive
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return nil, errors.New("归档文件格式错误")
+	}
+	if archive.Version < 1 || archive.Version > PROJECT_ARCHIVE_VERSION {
+		return nil, fmt.Errorf("不支持的归档版本 %d", archive.Version)
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		name = archive.Project.Name
+	}
+	if a.projectRepo.CheckProjectExistByName(name) {
+		return nil, errors.New("项目名称已存在")
+	}
+
+	report := &dto.ProjectRestoreReport{Name: name, UnmappedUsers: []string{}}
+
+	// 按用户名映射到当前实例的用户
+	usernames := []string{}
+	for _, user := range archive.Users {
+		usernames = append(usernames, user.Username)
+	}
+	userMap := make(map[uint]*models.User)
+	if len(usernames) > 0 {
+		users, err := a.userRepo.GetUsersByNames(usernames)
+		if err != nil {
+			return nil, err
+		}
+		byName := make(map[string]*models.User)
+		for index := range *users {
+			byName[(*users)[index].Username] = &(*users)[index]
+		}
+		for _, user := range archive.Users {
+			if target, ok := byName[user.Username]; ok {
+				userMap[user.Id] = target
+			} else {
+				report.UnmappedUsers = append(report.UnmappedUsers, user.Username)
+			}
+		}
+	}
+	mapUser := func(id uint, fallback uint) uint {
+		if user, ok := userMap[id]; ok {
+			return user.ID
+		}
+		return fallback
+	}
+
+	staticPaths, err := a.restoreResources(archive.Resources, entries)
+	if err != nil {
+		return nil, err
+	}
+	report.Resources = len(archive.Resources)
+
+	snapshot := repositories.ProjectSnapshot{
+		Project: models.Project{Name: name, Desc: archive.Project.Desc},
+	}
+	snapshot.Project.CreatedAt = archive.Project.CreatedAt
+	snapshot.Project.UpdatedAt = archive.Project.UpdatedAt
+
+	for _, member := range archive.Members {
+		user, ok := userMap[member.UserID]
+		if !ok {
+			continue
+		}
+		member.UserID = user.ID
+		member.Username = user.Username
+		snapshot.Members = append(snapshot.Members, member)
+	}
+	for _, archiveTask := range archive.Tasks {
+		task := archiveTask.Model()
+		task.CreatorID = mapUser(task.CreatorID, userId)
+		for oldPath, newPath := range staticPaths {
+			if oldPath != newPath {
+				task.Desc = strings.ReplaceAll(task.Desc, oldPath, newPath)
+			}
+		}
+		snapshot.Tasks = append(snapshot.Tasks, task)
+	}
+	for _, assignee := range archive.Assignees {
+		user, ok := userMap[assignee.UserID]
+		if !ok {
+			continue
+		}
+		assignee.UserID = user.ID
+		assignee.Username = user.Username
+		snapshot.Assignees = append(snapshot.Assignees, assignee)
+	}
+	snapshot.Dependencies = archive.Dependencies
+	for _, transition := range archive.Transitions {
+		transition.ActorID = mapUser(transition.ActorID, userId)
+		snapshot.Transitions = append(snapshot.Transitions, transition)
+	}
+
+	projectId, taskIds, err := a.archiveRepo.RestoreProjectSnapshot(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	report.ProjectId = projectId
+	report.Members = len(snapshot.Members)
+	report.Tasks = len(taskIds)
+
+	// 消息保存在 redis 中，重新生成 ID 后写入新项目，并作为已读消息加入接收者的消息列表
+	base := time.Now().UnixNano()
+	for index, message := range archive.Messages {
+		to := []uint{}
+		for _, id := range message.To {
+			if user, ok := userMap[id]; ok {
+				to = append(to, user.ID)
+			}
+		}
+		var taskId uint
+		if message.TaskID != nil {
+			taskId = taskIds[*message.TaskID]
+		}
+		msg := models.Message{
+			ID:        strconv.FormatInt(base+int64(index), 10),
+			To:        to,
+			Content:   message.Content,
+			CreatedAt: message.CreatedAt,
+		}
+		if err := a.msgRepo.SaveKanboardMsg(msg, constant.KANBOARD_NOTIFICATION, taskId, projectId); err != nil {
+			global.Logger.Errorw("restore message error", "error", err)
+			continue
+		}
+		for _, id := range to {
+			if err := a.msgRepo.AddReadedMsg(strconv.Itoa(int(id)), msg.ID, constant.KANBOARD_MESSAGE_READED); err != nil {
+				global.Logger.Errorw("restore message recipient error", "error", err)
+			}
+		}
+		report.Messages++
+	}
+
+	return report, nil
+}
+
+// 恢复归档中的资源文件，已存在相同 MD5 的资源直接复用，返回新旧访问路径的映射
+func (a *ArchiveService) restoreResources(resources []dto.ArchiveResource, entries map[string]*zip.File) (map[string]string, error) {
+	staticPaths := make(map[string]string)
+	for _, resource := range resources {
+		ext := filepath.Ext(resource.File)
+		if !archiveMD5Pattern.MatchString(resource.MD5) || !archiveFileTypePattern.MatchString(resource.FileType) || (ext != "" && !archiveExtPattern.MatchString(ext)) {
+			return nil, fmt.Errorf("归档资源『%s』格式错误", resource.File)
+		}
+
+		existing, err := a.resourceRepo.GetResourceByMd5(resource.MD5)
+		if err != nil {
+			return nil, err
+		}
+		if existing.ID != 0 {
+			staticPaths[resource.StaticPath] = existing.StaticPath
+			continue
+		}
+
+		entry, ok := entries[resource.File]
+		if !ok {
+			return nil, fmt.Errorf("归档文件缺少资源『%s』", resource.File)
+		}
+		data, err := readArchiveEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		sum := md5.Sum(data)
+		if !strings.EqualFold(hex.EncodeToString(sum[:]), resource.MD5) {
+			return nil, fmt.Errorf("资源『%s』md5校验失败", resource.File)
+		}
+
+		filetypePath := filepath.Join(constant.FileConfig.Path, resource.FileType)
+		if !isWithinDir(constant.FileConfig.Path, filetypePath) {
+			return nil, fmt.Errorf("归档资源『%s』格式错误", resource.File)
+		}
+		if err := os.MkdirAll(filetypePath, os.ModePerm); err != nil {
+			return nil, err
+		}
+		filename := fmt.Sprintf("%s%s", resource.MD5, ext)
+		filePath := filepath.Join(filetypePath, filename)
+		staticFp := fmt.Sprintf("%s/%s", constant.FileConfig.Static, resource.FileType)
+		staticPath := filepath.Join(staticFp, filename)
+		if err := os.WriteFile(filePath, data, 0644); err != nil {
+			return nil, err
+		}
+
+		created, err := a.resourceRepo.AddResource(resource.MD5, resource.FileType, filePath, staticPath)
+		if err != nil {
+			return nil, err
+		}
+		staticPaths[resource.StaticPath] = created.StaticPath
+	}
+	return staticPaths, nil
+}
+
+func (a *ArchiveService) getReferencedStaticPaths(tasks []models.Task) []string {
+	if constant.FileConfig.Static == "" {
+		return nil
+	}
+	pattern := regexp.MustCompile(regexp.QuoteMeta(constant.FileConfig.Static) + `/[^\s"'()<>\[\]]+`)
+	seen := make(map[string]bool)
+	staticPaths := []string{}
+	for _, task := range tasks {
+		for _, match := range pattern.FindAllString(task.Desc, -1) {
+			if !seen[match] {
+				seen[match] = true
+				staticPaths = append(staticPaths, match)
+			}
+		}
+	}
+	return staticPaths
+}
+
+// isWithinDir 检查 path 位于 dir 之下，防止归档内容写到上传目录之外
+func isWithinDir(dir string, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(dir), filepath.Clean(path))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+func readArchiveEntry(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > PROJECT_ARCHIVE_MAX_FILE_SIZE {
+		return nil, fmt.Errorf("归档文件『%s』过大", file.Name)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(io.LimitReader(reader, PROJECT_ARCHIVE_MAX_FILE_SIZE+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > PROJECT_ARCHIVE_MAX_FILE_SIZE {
+		return nil, fmt.Errorf("归档文件『%s』过大", file.Name)
+	}
+	return data, nil
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"errors"
+
+	"server/internal/app/admin/dto"
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+)
+
+type AuditService struct {
+	auditLogRepo *repositories.AuditLogRepo
+	userRepo     *repositories.UserRepo
+}
+
+var auditService *AuditService
+
+func NewAuditService() *AuditService {
+	if auditService == nil {
+		auditService = &AuditService{
+			auditLogRepo: repositories.NewAuditLogRepo(),
+			userRepo:     repositories.NewUserRepo(),
+		}
+	}
+	return auditService
+}
+
+// AuthorizeOnBehalf 校验操作者仍是管理员，返回操作者与被代为操作的用户
+func (a *AuditService) AuthorizeOnBehalf(adminId uint, userId uint) (*models.User, *models.User, error) {
+	admin, err := a.userRepo.GetUserById(adminId)
+	if err != nil || admin.ID == 0 || admin.IsAdmin != constant.IS_ADMIN {
+		return nil, nil, errors.New("没有权限")
+	}
+	user, err := a.userRepo.GetUserById(userId)
+	if err != nil || user.ID == 0 {
+		return nil, nil, errors.New("用户不存在")
+	}
+	return admin, user, nil
+}
+
+// Record 审计记录写入失败不影响请求本身
+func (a *AuditService) Record(log models.AuditLog) {
+	if err := a.auditLogRepo.CreateLog(log); err != nil {
+		global.Logger.Errorw("create audit log error", "error", err)
+	}
+}
+
+func (a *AuditService) GetLogs(request dto.AuditLogDto) (*dto.AuditLogPageResponse, error) {
+	query := make(map[string]any)
+	if request.ActorId != nil {
+		query["actorId"] = *request.ActorId
+	}
+	if request.UserId != nil {
+		query["userId"] = *request.UserId
+	}
+	if request.Action != nil {
+		query["action"] = *request.Action
+	}
+
+	total, err := a.auditLogRepo.GetLogCount(query)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := a.auditLogRepo.GetLogs(query, request.Page, request.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []dto.AuditLogResponse{}
+	for _, log := range *logs {
+		var response dto.AuditLogResponse
+		data = append(data, *response.Set(&log))
+	}
+
+	var pageResponse dto.AuditLogPageResponse
+	return pageResponse.Set(total, request.Page, request.PageSize, data), nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"server/internal/app/admin/dto"
+	"server/internal/constant"
+	"server/internal/lockout"
+	"server/internal/models"
+	"server/internal/repositories"
+)
+
+type LockoutService struct {
+	userRepo     *repositories.UserRepo
+	auditService *AuditService
+}
+
+var lockoutService *LockoutService
+
+func NewLockoutService() *LockoutService {
+	if lockoutService == nil {
+		lockoutService = &LockoutService{
+			userRepo:     repositories.NewUserRepo(),
+			auditService: NewAuditService(),
+		}
+	}
+	return lockoutService
+}
+
+func (l *LockoutService) GetLockouts() ([]lockout.Lock, error) {
+	return lockout.List()
+}
+
+func (l *LockoutService) ClearLockout(request dto.LockoutDto, adminId uint) error {
+	if err := lockout.Clear(request.Type, request.Key); err != nil {
+		return err
+	}
+
+	// 锁定 IP 时没有对应的用户
+	var userId uint
+	if request.Type == constant.LOCKOUT_TYPE_USER {
+		if user, err := l.userRepo.GetAuthUserByName(request.Key); err == nil {
+			userId = user.ID
+		}
+	}
+	l.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  userId,
+		Action:  constant.AUDIT_ACTION_CLEAR_LOCKOUT,
+		Detail:  request.Type + ":" + request.Key,
+	})
+	return nil
+}
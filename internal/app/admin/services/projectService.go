@@ -9,10 +9,13 @@ import (
 )
 
 type ProjectService struct {
-	projectRepo       *repositories.ProjectRepo
-	projectMemberRepo *repositories.ProjectMemberRepo
-	userRepo          *repositories.UserRepo
-	resourceRepo      *repositories.ResourceRepo
+	projectRepo        *repositories.ProjectRepo
+	projectMemberRepo  *repositories.ProjectMemberRepo
+	userRepo           *repositories.UserRepo
+	resourceRepo       *repositories.ResourceRepo
+	calendarFeedRepo   *repositories.CalendarFeedRepo
+	webhookRepo        *repositories.WebhookRepo
+	gitIntegrationRepo *repositories.GitIntegrationRepo
 }
 
 var projectService *ProjectService
@@ -20,10 +23,13 @@ var projectService *ProjectService
 func NewProjectService() *ProjectService {
 	if projectService == nil {
 		projectService = &ProjectService{
-			projectRepo:       repositories.NewProjectRepo(),
-			projectMemberRepo: repositories.NewProjectMemberRepo(),
-			userRepo:          repositories.NewUserRepo(),
-			resourceRepo:      repositories.NewResourceRepo(),
+			projectRepo:        repositories.NewProjectRepo(),
+			projectMemberRepo:  repositories.NewProjectMemberRepo(),
+			userRepo:           repositories.NewUserRepo(),
+			resourceRepo:       repositories.NewResourceRepo(),
+			calendarFeedRepo:   repositories.NewCalendarFeedRepo(),
+			webhookRepo:        repositories.NewWebhookRepo(),
+			gitIntegrationRepo: repositories.NewGitIntegrationRepo(),
 		}
 	}
 	return projectService
@@ -113,6 +119,11 @@ func (p *ProjectService) CreateProject(request *dto.ProjectCreateDto) error {
 	if p.projectRepo.CheckProjectExistByName(newProject.Name) {
 		return errors.New("项目名称已存在")
 	}
+	if request.Members != nil {
+		if err := p.userRepo.CheckMembersVerified(*request.Members); err != nil {
+			return err
+		}
+	}
 	project, err := p.projectRepo.CreateProject(newProject)
 	if err != nil {
 		return err
@@ -171,6 +182,18 @@ func (p *ProjectService) DeleteProject(request *dto.ProjectIdDto) error {
 		return err
 	}
 
+	if err := p.calendarFeedRepo.DeleteFeedsByProjectId(request.Id); err != nil {
+		return err
+	}
+
+	if err := p.webhookRepo.DeleteWebhooksByProjectId(request.Id); err != nil {
+		return err
+	}
+
+	if err := p.gitIntegrationRepo.DeleteIntegrationsByProjectId(request.Id); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -196,6 +219,9 @@ func (p *ProjectService) AddProjectMember(request *dto.ProjectAddMemberDto) erro
 			members = append(members, member)
 		}
 	}
+	if err := p.userRepo.CheckMembersVerified(members); err != nil {
+		return err
+	}
 	if err := p.projectMemberRepo.AddProjectMember(members, projectId); err != nil {
 		return err
 	}
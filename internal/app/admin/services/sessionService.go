@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+
+	"server/internal/app/admin/dto"
+	"server/internal/constant"
+	"server/internal/models"
+	"server/internal/session"
+	"server/internal/types"
+	"server/pkg/crypto"
+)
+
+type SessionService struct {
+	auditService *AuditService
+}
+
+var sessionService *SessionService
+
+func NewSessionService() *SessionService {
+	if sessionService == nil {
+		sessionService = &SessionService{
+			auditService: NewAuditService(),
+		}
+	}
+	return sessionService
+}
+
+func (s *SessionService) RefreshToken(request dto.RefreshTokenDto, client session.Client) (*session.TokenPair, error) {
+	return session.Refresh(constant.ADMIN_TOKEN, request.RefreshToken, client)
+}
+
+// GetUserSessions 返回用户在看板与管理端的全部会话
+func (s *SessionService) GetUserSessions(request dto.UserSessionsDto) ([]dto.SessionResponse, error) {
+	sessions, err := session.List(request.UserId, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data := []dto.SessionResponse{}
+	for _, item := range *sessions {
+		var response dto.SessionResponse
+		data = append(data, *response.Set(&item))
+	}
+	return data, nil
+}
+
+func (s *SessionService) RevokeUserSession(request dto.UserSessionDto, adminId uint) error {
+	target, err := session.Get(request.Id)
+	if err != nil {
+		return err
+	}
+	if target.ID == "" || target.UserID != request.UserId {
+		return errors.New("会话不存在")
+	}
+	if err := session.Revoke(target); err != nil {
+		return err
+	}
+
+	s.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  request.UserId,
+		Action:  constant.AUDIT_ACTION_REVOKE_SESSION,
+		Detail:  target.ID,
+	})
+	return nil
+}
+
+func (s *SessionService) RevokeUserSessions(request dto.UserSessionsDto, adminId uint) error {
+	if err := session.RevokeAll(request.UserId, "", ""); err != nil {
+		return err
+	}
+
+	s.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  request.UserId,
+		Action:  constant.AUDIT_ACTION_REVOKE_SESSION,
+		Detail:  "all",
+	})
+	return nil
+}
+
+func (s *SessionService) Logout(principal *types.Principal) error {
+	return session.Logout(principal.UserID, principal.SessionID, principal.TokenID)
+}
+
+// RevokeToken 按 JWT ID 吊销单个令牌，令牌所属的会话不受影响
+// 看板与管理端令牌均可吊销
+func (s *SessionService) RevokeToken(request dto.RevokeTokenDto, adminId uint) error {
+	claims, err := crypto.ParseTokenToKanboard(request.Token)
+	if err != nil {
+		claims, err = crypto.ParseTokenToAdmin(request.Token)
+	}
+	if err != nil || claims.ID == 0 || claims.RegisteredClaims.ID == "" || claims.ExpiresAt == nil {
+		return errors.New("令牌无效或已过期")
+	}
+	session.RevokeToken(claims.RegisteredClaims.ID, claims.ID, claims.ExpiresAt.Time)
+
+	s.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  claims.ID,
+		Action:  constant.AUDIT_ACTION_REVOKE_TOKEN,
+		Detail:  claims.RegisteredClaims.ID,
+	})
+	return nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"server/internal/app/admin/dto"
+	"server/internal/twofactor"
+)
+
+type TwoFactorService struct{}
+
+var twoFactorService *TwoFactorService
+
+func NewTwoFactorService() *TwoFactorService {
+	if twoFactorService == nil {
+		twoFactorService = &TwoFactorService{}
+	}
+	return twoFactorService
+}
+
+func (t *TwoFactorService) GetStatus(userId uint) (*twofactor.Status, error) {
+	return twofactor.GetStatus(userId)
+}
+
+func (t *TwoFactorService) Setup(userId uint, request dto.TwoFactorPasswordRequest) (*twofactor.Setup, error) {
+	return twofactor.Begin(userId, request.Password)
+}
+
+func (t *TwoFactorService) Enable(userId uint, request dto.TwoFactorCodeRequest) ([]string, error) {
+	return twofactor.Enable(userId, request.Code)
+}
+
+func (t *TwoFactorService) Disable(userId uint, request dto.TwoFactorCodeRequest) error {
+	return twofactor.Disable(userId, request.Code)
+}
+
+func (t *TwoFactorService) RegenerateRecoveryCodes(userId uint, request dto.TwoFactorCodeRequest) ([]string, error) {
+	return twofactor.RegenerateRecoveryCodes(userId, request.Code)
+}
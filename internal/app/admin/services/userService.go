@@ -6,17 +6,19 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
+	"time"
 
+	"server/internal/accesstoken"
 	"server/internal/app/admin/dto"
+	"server/internal/auth"
 	"server/internal/constant"
 	"server/internal/repositories"
 
-	"server/internal/global"
 	"server/internal/models"
+	"server/internal/session"
+	"server/internal/twofactor"
 	md5 "server/pkg/MD5"
-	"server/pkg/crypto"
 
 	"github.com/gin-gonic/gin"
 )
@@ -24,6 +26,7 @@ import (
 type UserService struct {
 	userRepo     *repositories.UserRepo
 	resourceRepo *repositories.ResourceRepo
+	auditService *AuditService
 }
 
 var userService *UserService
@@ -33,6 +36,7 @@ func NewUserService() *UserService {
 		userService = &UserService{
 			userRepo:     repositories.NewUserRepo(),
 			resourceRepo: repositories.NewResourceRepo(),
+			auditService: NewAuditService(),
 		}
 	}
 	return userService
@@ -57,40 +61,72 @@ func (u *UserService) checkUserInfoExist(username, email, mobile string) (bool,
 	return false, nil
 }
 
-func GenerateToken(id uint, username string) (string, error) {
-	token, err := crypto.GenerateJWTToAdmin(id, username)
-	global.Redis.Set(constant.ADMIN_TOKEN, strconv.Itoa(int(id)), token, constant.JWTConfig.AdminTokenExpiration)
-	return token, err
-}
-
-func (u *UserService) Login(request dto.UserLoginRequest) (*dto.UserResponse, *string, error) {
-	user, err := u.userRepo.GetUserByName(request.Username)
-	if user.ID == 0 || err != nil {
-		return nil, nil, errors.New("用户名或密码错误")
+// Login 开启两步验证的管理员只返回 challenge，由 LoginTwoFactor 完成登录
+func (u *UserService) Login(request dto.UserLoginRequest, client session.Client) (*dto.LoginResponse, error) {
+	user, err := auth.Authenticate(request.Username, request.Password, client.IP)
+	if err != nil {
+		return nil, err
 	}
 
 	if user.IsAdmin == constant.NOT_ADMIN {
-		return nil, nil, errors.New("权限不足")
+		return nil, errors.New("权限不足")
+	}
+
+	challenge, err := twofactor.LoginChallenge(user, constant.ADMIN_TOKEN, client)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &dto.LoginResponse{
+			TwoFactorRequired: true,
+			SetupRequired:     challenge.SetupRequired,
+			Challenge:         challenge.Token,
+			ChallengeExpires:  challenge.ExpiresIn,
+		}, nil
 	}
 
-	comp := crypto.CheckPasswordHash(user.Password, request.Password)
+	return u.completeLogin(user, client)
+}
+
+// LoginTwoFactorSetup 登录过程中尚未绑定两步验证的管理员获取密钥
+func (u *UserService) LoginTwoFactorSetup(request dto.TwoFactorChallengeRequest) (*twofactor.Setup, error) {
+	return twofactor.SetupChallenge(constant.ADMIN_TOKEN, request.Challenge)
+}
 
-	if !comp {
-		return nil, nil, errors.New("用户名或密码错误")
+func (u *UserService) LoginTwoFactor(request dto.TwoFactorLoginRequest) (*dto.LoginResponse, error) {
+	user, client, recoveryCodes, err := twofactor.CompleteChallenge(constant.ADMIN_TOKEN, request.Challenge, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin == constant.NOT_ADMIN {
+		return nil, errors.New("权限不足")
 	}
+	response, err := u.completeLogin(user, client)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
 
-	token, err := GenerateToken(user.ID, user.Username)
+func (u *UserService) completeLogin(user *models.User, client session.Client) (*dto.LoginResponse, error) {
+	tokens, err := session.Issue(constant.ADMIN_TOKEN, user, client)
 	if err != nil {
-		return nil, nil, errors.New("token生成失败")
+		return nil, err
 	}
 
 	var userResponse *dto.UserResponse
 	resource, err := u.resourceRepo.GetResourceById(user.Avatar)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
-	return userResponse.Set(user, resource), &token, nil
+	return &dto.LoginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         userResponse.Set(user, resource),
+	}, nil
 }
 
 func (u *UserService) Register(request dto.UserRegisterRequest) (*dto.UserResponse, error) {
@@ -187,7 +223,10 @@ func (u *UserService) CreateUser(request dto.UserCreateRequest) (*dto.UserRespon
 	createUser.Username = request.Username
 	createUser.Password = request.Password
 	if request.Email != nil {
+		// 管理员填写的邮箱视为已验证
+		now := time.Now()
 		createUser.Email = *request.Email
+		createUser.EmailVerifiedAt = &now
 	}
 	if request.Mobile != nil {
 		createUser.Mobile = *request.Mobile
@@ -233,6 +272,7 @@ func (u *UserService) UpdateUser(request dto.UserUpdateRequest) (*dto.UserRespon
 	}
 	if request.Email != nil {
 		updateData["email"] = request.Email
+		updateData["email_verified_at"] = time.Now()
 	}
 	if request.Position != nil {
 		updateData["position"] = request.Position
@@ -248,13 +288,24 @@ func (u *UserService) UpdateUser(request dto.UserUpdateRequest) (*dto.UserRespon
 	}
 	if request.Loginable != nil {
 		updateData["loginable"] = request.Loginable
+		if *request.Loginable {
+			updateData["lock_reason"] = nil
+			updateData["locked_at"] = nil
+		} else {
+			updateData["locked_at"] = time.Now()
+		}
 	}
 
 	user, err := u.userRepo.UpdateUserById(updateData, request.ID)
+	if err != nil {
+		return nil, err
+	}
 
+	if request.Loginable != nil {
+		session.ForgetLoginable(user.ID)
+	}
 	if user.Loginable == constant.NOT_LOGINABLE {
-		global.Redis.Delete(constant.ADMIN_TOKEN, strconv.Itoa(int(user.ID)))
-		global.Redis.Delete(constant.KANBOARD_TOKEN, strconv.Itoa(int(user.ID)))
+		session.RevokeAll(user.ID, "", "")
 	}
 
 	var userResponse *dto.UserResponse
@@ -266,6 +317,52 @@ func (u *UserService) UpdateUser(request dto.UserUpdateRequest) (*dto.UserRespon
 	return userResponse.Set(user, resource), err
 }
 
+// LockUser 禁用账号，立即注销全部会话并关闭其 WebSocket 连接，访问令牌在启用前不可用
+func (u *UserService) LockUser(request dto.UserLockRequest, adminId uint) error {
+	if request.ID == adminId {
+		return errors.New("不能禁用自己的账号")
+	}
+	user, err := u.userRepo.UpdateUserById(map[string]any{
+		"loginable":   constant.NOT_LOGINABLE,
+		"lock_reason": request.Reason,
+		"locked_at":   time.Now(),
+	}, request.ID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	session.ForgetLoginable(user.ID)
+	if err := session.RevokeAll(user.ID, "", ""); err != nil {
+		return err
+	}
+	u.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  user.ID,
+		Action:  constant.AUDIT_ACTION_LOCK_USER,
+		Detail:  request.Reason,
+	})
+	return nil
+}
+
+func (u *UserService) UnlockUser(request dto.UserIDRequest, adminId uint) error {
+	user, err := u.userRepo.UpdateUserById(map[string]any{
+		"loginable":   constant.IS_LOGINABLE,
+		"lock_reason": nil,
+		"locked_at":   nil,
+	}, request.ID)
+	if err != nil {
+		return errors.New("用户不存在")
+	}
+
+	session.ForgetLoginable(user.ID)
+	u.auditService.Record(models.AuditLog{
+		ActorID: adminId,
+		UserID:  user.ID,
+		Action:  constant.AUDIT_ACTION_UNLOCK_USER,
+	})
+	return nil
+}
+
 func (u *UserService) GetUserList(request dto.PageRequest) (*dto.UserPageResponse, error) {
 	total := u.userRepo.GetUserCount()
 	users, err := u.userRepo.GetUserList(request.Page, request.PageSize)
@@ -330,8 +427,13 @@ func (u *UserService) SearchUser(request dto.UserSearchRequest) (*dto.UserPageRe
 }
 
 func (u *UserService) DeleteUser(request dto.UserIDRequest) error {
-	global.Redis.Delete(constant.ADMIN_TOKEN, strconv.Itoa(int(request.ID)))
-	global.Redis.Delete(constant.KANBOARD_TOKEN, strconv.Itoa(int(request.ID)))
+	session.RevokeAll(request.ID, "", "")
+	if err := repositories.NewUserIdentityRepo().DeleteIdentitiesByUserId(request.ID); err != nil {
+		return err
+	}
+	if err := accesstoken.RevokeAll(request.ID); err != nil {
+		return err
+	}
 	return u.userRepo.DeleteUserById(request.ID)
 }
 
@@ -340,8 +442,7 @@ func (u *UserService) ChangePassword(request dto.UserChangePasswordRequest) erro
 	if err != nil {
 		return err
 	}
-	global.Redis.Delete(constant.ADMIN_TOKEN, strconv.Itoa(int(request.ID)))
-	global.Redis.Delete(constant.KANBOARD_TOKEN, strconv.Itoa(int(request.ID)))
+	session.RevokeAll(request.ID, "", "")
 
 	return err
 }
@@ -8,6 +8,9 @@ import (
 	"server/internal/constant"
 	"server/internal/global"
 	"server/internal/repositories"
+	"server/internal/session"
+	"server/internal/types"
+	"server/internal/utils"
 	"server/pkg/ws"
 
 	"github.com/gin-gonic/gin"
@@ -41,7 +44,8 @@ func (w *WsService) PushUnReadMsg(conn *websocket.Conn, userID uint) {
 	conn.WriteJSON(json)
 }
 
-func (w *WsService) ListenAndPush(conn *websocket.Conn, userID uint) {
+// ListenAndPush principal 为建立连接时的鉴权信息，会话或令牌被注销后关闭连接
+func (w *WsService) ListenAndPush(conn *websocket.Conn, userID uint, principal *types.Principal) {
 	pubsub := w.msgRepo.SubscribeMsg(userID, constant.ADMIN_MESSAGE_CHANNEL)
 	defer pubsub.Close()
 
@@ -52,6 +56,14 @@ func (w *WsService) ListenAndPush(conn *websocket.Conn, userID uint) {
 			break
 		}
 
+		if msg.Payload == constant.CLOSE_CONNECTION {
+			if principal != nil && !session.IsActive(principal.SessionID, principal.TokenID) {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "token revoked"))
+				break
+			}
+			continue
+		}
+
 		var json common.MsgRsp
 		if msg.Payload == "" {
 			json = common.MsgRsp{
@@ -99,7 +111,7 @@ func (w *WsService) HandleWebsocket(ctx *gin.Context, userID uint) {
 	w.PushUnReadMsg(conn, userID)
 
 	// 监听Redis并推送消息
-	w.ListenAndPush(conn, userID)
+	w.ListenAndPush(conn, userID, utils.GetPrincipal(ctx))
 
 	delete(w.clients, userID)
 }
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+// ExpiresInDays 为空表示永不过期
+type AccessTokenCreateDto struct {
+	Name          string   `json:"name" binding:"required,max=64"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays *int     `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+type AccessTokenIdDto struct {
+	Id uint `json:"id" form:"id" binding:"required"`
+}
+
+type AccessTokenResponse struct {
+	Id         uint     `json:"id"`
+	Name       string   `json:"name"`
+	Hint       string   `json:"hint"`
+	Scopes     []string `json:"scopes"`
+	ExpiresAt  string   `json:"expires_at"`
+	LastUsedAt string   `json:"last_used_at"`
+	LastUsedIP string   `json:"last_used_ip"`
+	CreatedAt  string   `json:"created_at"`
+}
+
+func (a *AccessTokenResponse) Set(accessToken *models.AccessToken) *AccessTokenResponse {
+	a.Id = accessToken.ID
+	a.Name = accessToken.Name
+	a.Hint = accessToken.Hint
+	a.Scopes = accessToken.ScopeList()
+	if accessToken.ExpiresAt != nil {
+		a.ExpiresAt = accessToken.ExpiresAt.Local().Format(time.DateTime)
+	}
+	if accessToken.LastUsedAt != nil {
+		a.LastUsedAt = accessToken.LastUsedAt.Local().Format(time.DateTime)
+	}
+	a.LastUsedIP = accessToken.LastUsedIP
+	a.CreatedAt = accessToken.CreatedAt.Local().Format(time.DateTime)
+	return a
+}
+
+// AccessTokenCreateResponse 中的 Token 只在创建时返回一次
+type AccessTokenCreateResponse struct {
+	AccessTokenResponse
+	Token string `json:"token"`
+}
@@ -0,0 +1,134 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"server/internal/models"
+)
+
+// 触发条件，为空表示不限
+type AutomationConditions struct {
+	Status   *uint `json:"status,omitempty"`
+	Priority *int  `json:"priority,omitempty"`
+}
+
+// 动作参数，不同动作使用不同字段
+type AutomationParams struct {
+	UserId     *uint  `json:"user_id,omitempty"`
+	Priority   *int   `json:"priority,omitempty"`
+	Status     *uint  `json:"status,omitempty"`
+	Content    string `json:"content,omitempty"`
+	Recipients string `json:"recipients,omitempty"`
+}
+
+type AutomationRuleCreateDto struct {
+	ProjectId  uint                 `json:"project_id" form:"project_id" binding:"required"`
+	Name       string               `json:"name" form:"name" binding:"required"`
+	Trigger    string               `json:"trigger" form:"trigger" binding:"required"`
+	Conditions AutomationConditions `json:"conditions" form:"conditions"`
+	Action     string               `json:"action" form:"action" binding:"required"`
+	Params     AutomationParams     `json:"params" form:"params"`
+	Enabled    *bool                `json:"enabled" form:"enabled"`
+}
+
+type AutomationRuleUpdateDto struct {
+	Id         uint                  `json:"id" form:"id" binding:"required"`
+	ProjectId  uint                  `json:"project_id" form:"project_id" binding:"required"`
+	Name       *string               `json:"name" form:"name"`
+	Trigger    *string               `json:"trigger" form:"trigger"`
+	Conditions *AutomationConditions `json:"conditions" form:"conditions"`
+	Action     *string               `json:"action" form:"action"`
+	Params     *AutomationParams     `json:"params" form:"params"`
+	Enabled    *bool                 `json:"enabled" form:"enabled"`
+}
+
+type AutomationRuleIdDto struct {
+	Id        uint `json:"id" form:"id" binding:"required"`
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+}
+
+type AutomationRulesDto struct {
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+}
+
+type AutomationLogDto struct {
+	Id        uint `json:"id" form:"id" binding:"required"`
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+	PageRequest
+}
+
+type AutomationRuleResponse struct {
+	Id         uint                 `json:"id"`
+	ProjectId  uint                 `json:"project_id"`
+	Name       string               `json:"name"`
+	Trigger    string               `json:"trigger"`
+	Conditions AutomationConditions `json:"conditions"`
+	Action     string               `json:"action"`
+	Params     AutomationParams     `json:"params"`
+	Enabled    bool                 `json:"enabled"`
+	CreatorId  uint                 `json:"creator_id"`
+	CreatedAt  string               `json:"created_at"`
+	UpdatedAt  string               `json:"updated_at"`
+}
+
+func (a *AutomationRuleResponse) Set(rule *models.AutomationRule) *AutomationRuleResponse {
+	a.Id = rule.ID
+	a.ProjectId = rule.ProjectID
+	a.Name = rule.Name
+	a.Trigger = rule.Trigger
+	a.Action = rule.Action
+	a.Enabled = rule.Enabled
+	a.CreatorId = rule.CreatorID
+	a.CreatedAt = rule.CreatedAt.Local().Format(time.DateTime)
+	a.UpdatedAt = rule.UpdatedAt.Local().Format(time.DateTime)
+	if rule.Conditions != "" {
+		json.Unmarshal([]byte(rule.Conditions), &a.Conditions)
+	}
+	if rule.Params != "" {
+		json.Unmarshal([]byte(rule.Params), &a.Params)
+	}
+	return a
+}
+
+type AutomationLogResponse struct {
+	Id        uint   `json:"id"`
+	RuleId    uint   `json:"rule_id"`
+	TaskId    uint   `json:"task_id"`
+	Trigger   string `json:"trigger"`
+	Result    string `json:"result"`
+	Message   string `json:"message"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (a *AutomationLogResponse) Set(log *models.AutomationLog) *AutomationLogResponse {
+	a.Id = log.ID
+	a.RuleId = log.RuleID
+	a.TaskId = log.TaskID
+	a.Trigger = log.Trigger
+	a.Result = log.Result
+	a.Message = log.Message
+	a.CreatedAt = log.CreatedAt.Local().Format(time.DateTime)
+	return a
+}
+
+type AutomationLogPageResponse struct {
+	Total     int                     `json:"total"`
+	Page      int                     `json:"page"`
+	PageSize  int                     `json:"size"`
+	TotalPage int                     `json:"total_page"`
+	Data      []AutomationLogResponse `json:"data"`
+}
+
+func (a *AutomationLogPageResponse) Set(total int64, page int, pageSize int, data []AutomationLogResponse) *AutomationLogPageResponse {
+	a.Total = int(total)
+	a.Page = page
+	a.PageSize = pageSize
+	totalPage := int(float64(total) / float64(pageSize))
+	if total%int64(pageSize) != 0 {
+		totalPage++
+	}
+	a.TotalPage = totalPage
+	a.Data = data
+	return a
+}
@@ -0,0 +1,84 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+// ProjectId 为空时查询个人日历，此时 UserId 不生效
+type CalendarRangeDto struct {
+	ProjectId *uint  `json:"project_id" form:"project_id"`
+	From      *int64 `json:"from" form:"from"`
+	To        *int64 `json:"to" form:"to"`
+	UserId    *uint  `json:"user_id" form:"user_id"`
+	Status    *uint  `json:"status" form:"status"`
+}
+
+type CalendarTaskResponse struct {
+	Id          uint            `json:"id"`
+	ProjectId   uint            `json:"project_id"`
+	ProjectName string          `json:"project_name"`
+	Title       string          `json:"title"`
+	Status      uint            `json:"status"`
+	Priority    int             `json:"priority"`
+	Start       string          `json:"start"`
+	Due         string          `json:"due"`
+	Assignees   []models.Member `json:"assignees"`
+}
+
+func (c *CalendarTaskResponse) Set(task *models.Task, projectName string, assignees []models.Member) *CalendarTaskResponse {
+	c.Id = task.ID
+	c.ProjectId = task.ProjectID
+	c.ProjectName = projectName
+	c.Title = task.Title
+	c.Status = task.Status
+	c.Priority = task.Priority
+	if !task.StartDate.IsZero() {
+		c.Start = task.StartDate.Local().Format(time.DateTime)
+	}
+	if !task.DueDate.IsZero() {
+		c.Due = task.DueDate.Local().Format(time.DateTime)
+	}
+	c.Assignees = assignees
+	return c
+}
+
+type CalendarRangeResponse struct {
+	From  string                 `json:"from"`
+	To    string                 `json:"to"`
+	Tasks []CalendarTaskResponse `json:"tasks"`
+}
+
+type CalendarFeedCreateDto struct {
+	ProjectId *uint `json:"project_id" form:"project_id"`
+}
+
+type CalendarFeedIdDto struct {
+	Id uint `json:"id" form:"id" binding:"required"`
+}
+
+type CalendarFeedTokenDto struct {
+	Token string `uri:"token" binding:"required"`
+}
+
+type CalendarFeedResponse struct {
+	Id             uint   `json:"id"`
+	ProjectId      *uint  `json:"project_id"`
+	ProjectName    string `json:"project_name"`
+	URL            string `json:"url,omitempty"`
+	CreatedAt      string `json:"created_at"`
+	LastAccessedAt string `json:"last_accessed_at"`
+}
+
+func (c *CalendarFeedResponse) Set(feed *models.CalendarFeed, projectName string, url string) *CalendarFeedResponse {
+	c.Id = feed.ID
+	c.ProjectId = feed.ProjectID
+	c.ProjectName = projectName
+	c.URL = url
+	c.CreatedAt = feed.CreatedAt.Local().Format(time.DateTime)
+	if feed.LastAccessedAt != nil {
+		c.LastAccessedAt = feed.LastAccessedAt.Local().Format(time.DateTime)
+	}
+	return c
+}
@@ -0,0 +1,62 @@
+package dto
+
+type ChartRangeDto struct {
+	Id   uint   `json:"id" form:"id" binding:"required"`
+	From *int64 `json:"from" form:"from"`
+	To   *int64 `json:"to" form:"to"`
+}
+
+type CumulativeFlowResponse struct {
+	Date       string `json:"date"`
+	Undo       int64  `json:"undo"`
+	InProgress int64  `json:"in_progress"`
+	Done       int64  `json:"done"`
+}
+
+type BurndownResponse struct {
+	Date      string `json:"date"`
+	Total     int64  `json:"total"`
+	Done      int64  `json:"done"`
+	Remaining int64  `json:"remaining"`
+}
+
+type FlowMetricsDto struct {
+	ChartRangeDto
+	UserId *uint `json:"user_id" form:"user_id"`
+}
+
+type DurationStats struct {
+	Count   int     `json:"count"`
+	Average float64 `json:"average"`
+	P50     float64 `json:"p50"`
+	P85     float64 `json:"p85"`
+	P95     float64 `json:"p95"`
+}
+
+type FlowMetrics struct {
+	LeadTime  DurationStats `json:"lead_time"`
+	CycleTime DurationStats `json:"cycle_time"`
+}
+
+type PriorityFlowMetrics struct {
+	Priority int `json:"priority"`
+	FlowMetrics
+}
+
+type AssigneeFlowMetrics struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	FlowMetrics
+}
+
+type ThroughputResponse struct {
+	Week  string `json:"week"`
+	Count int64  `json:"count"`
+}
+
+type FlowMetricsResponse struct {
+	Overall    FlowMetrics           `json:"overall"`
+	ByPriority []PriorityFlowMetrics `json:"by_priority"`
+	ByAssignee []AssigneeFlowMetrics `json:"by_assignee"`
+	Throughput []ThroughputResponse  `json:"throughput"`
+}
@@ -0,0 +1,96 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"server/internal/models"
+)
+
+type GitIntegrationCreateDto struct {
+	ProjectId  uint   `json:"project_id" form:"project_id" binding:"required"`
+	Provider   string `json:"provider" form:"provider" binding:"required,oneof=github gitea gitlab"`
+	Secret     string `json:"secret" form:"secret"`
+	CloseTasks *bool  `json:"close_tasks" form:"close_tasks"`
+}
+
+type GitIntegrationIdDto struct {
+	Id        uint `json:"id" form:"id" binding:"required"`
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+}
+
+type GitIntegrationsDto struct {
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+}
+
+type GitHookUriDto struct {
+	Id uint `uri:"hookId" binding:"required"`
+}
+
+type TaskCommitsDto struct {
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+	TaskId    uint `json:"task_id" form:"task_id" binding:"required"`
+}
+
+type GitIntegrationResponse struct {
+	Id         uint   `json:"id"`
+	ProjectId  uint   `json:"project_id"`
+	Provider   string `json:"provider"`
+	CloseTasks bool   `json:"close_tasks"`
+	HookPath   string `json:"hook_path"`
+	Secret     string `json:"secret,omitempty"`
+	CreatorId  uint   `json:"creator_id"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// 密钥只在创建时返回
+func (g *GitIntegrationResponse) Set(integration *models.GitIntegration, withSecret bool) *GitIntegrationResponse {
+	g.Id = integration.ID
+	g.ProjectId = integration.ProjectID
+	g.Provider = integration.Provider
+	g.CloseTasks = integration.CloseTasks
+	g.HookPath = fmt.Sprintf("/kanboard/git/%d", integration.ID)
+	if withSecret {
+		g.Secret = integration.Secret
+	}
+	g.CreatorId = integration.CreatorID
+	g.CreatedAt = integration.CreatedAt.Local().Format(time.DateTime)
+	return g
+}
+
+type TaskCommitResponse struct {
+	Id          uint   `json:"id"`
+	TaskId      uint   `json:"task_id"`
+	Kind        string `json:"kind"`
+	Ref         string `json:"ref"`
+	Repository  string `json:"repository"`
+	Title       string `json:"title"`
+	URL         string `json:"url"`
+	Author      string `json:"author"`
+	CommittedAt string `json:"committed_at"`
+	CreatedAt   string `json:"created_at"`
+}
+
+func (t *TaskCommitResponse) Set(commit *models.TaskCommit) *TaskCommitResponse {
+	t.Id = commit.ID
+	t.TaskId = commit.TaskID
+	t.Kind = commit.Kind
+	t.Ref = commit.Ref
+	t.Repository = commit.Repository
+	t.Title = commit.Title
+	t.URL = commit.URL
+	t.Author = commit.Author
+	if commit.CommittedAt != nil {
+		t.CommittedAt = commit.CommittedAt.Local().Format(time.DateTime)
+	}
+	t.CreatedAt = commit.CreatedAt.Local().Format(time.DateTime)
+	return t
+}
+
+// 一次推送的处理结果，返回给代码托管平台便于在其投递记录中排查
+type GitHookReport struct {
+	Event  string   `json:"event"`
+	Linked []uint   `json:"linked"`
+	Closed []uint   `json:"closed"`
+	Errors []string `json:"errors,omitempty"`
+}
@@ -1,7 +1,7 @@
 package dto
 
 type MsgMarkReadDto struct {
-	UserId uint   `json:"id" form:"id" binding:"required"`
+	UserId uint   `json:"-" form:"-"`
 	MsgId  string `json:"msg_id" form:"msg_id" binding:"required"`
 }
 
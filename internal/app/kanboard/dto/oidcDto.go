@@ -0,0 +1,16 @@
+package dto
+
+type OIDCAuthorizeRequest struct {
+	Device string `json:"device" form:"device"`
+}
+
+type OIDCAuthorizeResponse struct {
+	URL       string `json:"url"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// OIDCCallbackRequest 前端回调页面收到的 code 与 state
+type OIDCCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
@@ -12,16 +12,28 @@ type ProjectIdDto struct {
 
 type ProjectAddMemberDto struct {
 	ProjectId uint            `json:"project_id" form:"project_id" binding:"required"`
-	UserId    uint            `json:"user_id" form:"user_id" binding:"required"`
+	UserId    uint            `json:"-" form:"-"`
 	Members   []models.Member `json:"members" form:"members" binding:"required"`
+	Role      string          `json:"role" form:"role" binding:"omitempty,oneof=owner maintainer member viewer"`
 }
 
 type ProjectRemoveMemberDto struct {
 	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
-	UserId    uint `json:"user_id" form:"user_id" binding:"required"`
+	UserId    uint `json:"-" form:"-"`
 	MemberId  uint `json:"member_id" form:"member_id" binding:"required"`
 }
 
+type ProjectMemberRoleDto struct {
+	ProjectId uint   `json:"project_id" form:"project_id" binding:"required"`
+	MemberId  uint   `json:"member_id" form:"member_id" binding:"required"`
+	Role      string `json:"role" form:"role" binding:"required,oneof=owner maintainer member viewer"`
+}
+
+type ProjectRoleResponse struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
 type MemberResponse struct {
 	Assignee bool `json:"assignee"`
 	UserResponse
@@ -0,0 +1,41 @@
+package dto
+
+import (
+	"time"
+
+	"server/internal/models"
+)
+
+type RefreshTokenDto struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type SessionIdDto struct {
+	Id string `json:"id" binding:"required"`
+}
+
+// KeepCurrent 为 true 时保留当前会话，只注销其他设备
+type RevokeAllSessionsDto struct {
+	KeepCurrent bool `json:"keep_current"`
+}
+
+type SessionResponse struct {
+	Id         string `json:"id"`
+	Device     string `json:"device"`
+	IP         string `json:"ip"`
+	Current    bool   `json:"current"`
+	LastSeenAt string `json:"last_seen_at"`
+	ExpiresAt  string `json:"expires_at"`
+	CreatedAt  string `json:"created_at"`
+}
+
+func (s *SessionResponse) Set(session *models.Session, currentId string) *SessionResponse {
+	s.Id = session.ID
+	s.Device = session.Device
+	s.IP = session.IP
+	s.Current = session.ID == currentId
+	s.LastSeenAt = session.LastSeenAt.Local().Format(time.DateTime)
+	s.ExpiresAt = session.ExpiresAt.Local().Format(time.DateTime)
+	s.CreatedAt = session.CreatedAt.Local().Format(time.DateTime)
+	return s
+}
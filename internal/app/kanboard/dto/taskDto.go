@@ -1,6 +1,8 @@
 package dto
 
 import (
+	"strconv"
+	"strings"
 	"time"
 
 	"server/internal/models"
@@ -16,11 +18,13 @@ type TaskGetDto struct {
 }
 
 type TaskCreateDto struct {
-	UserId    uint             `json:"user_id" form:"user_id" binding:"required"`
+	UserId    uint             `json:"-" form:"-"`
+	CreatorId uint             `json:"-" form:"-"` // 导入时保留原创建者，为空时为 UserId，权限始终按 UserId 校验
 	ProjectId uint             `json:"project_id" form:"project_id" binding:"required"`
 	Title     string           `json:"title" form:"title" binding:"required"`
 	Desc      string           `json:"desc" form:"desc" binding:"required"`
 	Priority  *int             `json:"priority" form:"priority" binding:"required"`
+	StartDate *int64           `json:"start_date" form:"start_date"`
 	DueDate   *int64           `json:"due_date" form:"due_date"`
 	Assignees *[]models.Member `json:"assignees" form:"assignees" binding:"required"`
 }
@@ -35,9 +39,23 @@ type TaskUpdateDto struct {
 	ProjectId uint    `json:"project_id" form:"project_id" binding:"required"`
 	Desc      *string `json:"desc" form:"desc"`
 	Priority  *int    `json:"priority" form:"priority"`
+	StartDate *int64  `json:"start_date" form:"start_date"`
 	DueDate   *int64  `json:"due_date" form:"due_date"`
 }
 
+type TaskRescheduleDto struct {
+	Id        uint  `json:"id" form:"id" binding:"required"`
+	ProjectId uint  `json:"project_id" form:"project_id" binding:"required"`
+	StartDate int64 `json:"start_date" form:"start_date" binding:"required"`
+	DueDate   int64 `json:"due_date" form:"due_date" binding:"required"`
+}
+
+type TaskDependencyDto struct {
+	Id          uint `json:"id" form:"id" binding:"required"`
+	ProjectId   uint `json:"project_id" form:"project_id" binding:"required"`
+	DependsOnId uint `json:"depends_on_id" form:"depends_on_id" binding:"required"`
+}
+
 type TaskChangeStatusDto struct {
 	Id        uint  `json:"id" form:"id" binding:"required"`
 	ProjectId uint  `json:"project_id" form:"project_id" binding:"required"`
@@ -69,6 +87,118 @@ type TaskSearchDto struct {
 	CreatorId *uint   `json:"creator_id" form:"creator_id"`
 }
 
+type TaskExportDto struct {
+	Format string `json:"format" form:"format"`
+	TaskSearchDto
+}
+
+type TaskExportRecord struct {
+	Id          uint     `json:"id"`
+	Title       string   `json:"title"`
+	Desc        string   `json:"desc"`
+	Status      uint     `json:"status"`
+	Priority    int      `json:"priority"`
+	StartDate   string   `json:"start_date"`
+	DueDate     string   `json:"due_date"`
+	CompletedAt string   `json:"completed_at"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+	ProjectId   uint     `json:"project_id"`
+	ProjectName string   `json:"project_name"`
+	CreatorId   uint     `json:"creator_id"`
+	Creator     string   `json:"creator"`
+	Assignees   []string `json:"assignees"`
+}
+
+var TaskExportHeader = []string{
+	"id", "title", "desc", "status", "priority", "start_date", "due_date", "completed_at",
+	"created_at", "updated_at", "project_id", "project_name", "creator_id", "creator", "assignees",
+}
+
+func (t *TaskExportRecord) Set(task *models.Task, project *models.Project, creator string, assignees []string) *TaskExportRecord {
+	t.Id = task.ID
+	t.Title = task.Title
+	t.Desc = task.Desc
+	t.Status = task.Status
+	t.Priority = task.Priority
+	if !task.StartDate.IsZero() {
+		t.StartDate = task.StartDate.Local().Format(time.DateTime)
+	}
+	if !task.DueDate.IsZero() {
+		t.DueDate = task.DueDate.Local().Format(time.DateTime)
+	}
+	if task.CompletedAt != nil {
+		t.CompletedAt = task.CompletedAt.Local().Format(time.DateTime)
+	}
+	t.CreatedAt = task.CreatedAt.Local().Format(time.DateTime)
+	t.UpdatedAt = task.UpdatedAt.Local().Format(time.DateTime)
+	t.ProjectId = task.ProjectID
+	t.ProjectName = project.Name
+	t.CreatorId = task.CreatorID
+	t.Creator = creator
+	t.Assignees = assignees
+	return t
+}
+
+// csvCell 以公式字符开头的单元格加上单引号，避免在 Excel 等软件中打开时被当作公式执行
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (t *TaskExportRecord) CSV() []string {
+	return []string{
+		strconv.Itoa(int(t.Id)),
+		csvCell(t.Title),
+		csvCell(t.Desc),
+		strconv.Itoa(int(t.Status)),
+		strconv.Itoa(t.Priority),
+		t.StartDate,
+		t.DueDate,
+		t.CompletedAt,
+		t.CreatedAt,
+		t.UpdatedAt,
+		strconv.Itoa(int(t.ProjectId)),
+		csvCell(t.ProjectName),
+		strconv.Itoa(int(t.CreatorId)),
+		csvCell(t.Creator),
+		csvCell(strings.Join(t.Assignees, ";")),
+	}
+}
+
+type TaskImportDto struct {
+	ProjectId  uint   `json:"project_id" form:"project_id" binding:"required"`
+	Format     string `json:"format" form:"format" binding:"required"`
+	Mapping    string `json:"mapping" form:"mapping"`
+	DryRun     bool   `json:"dry_run" form:"dry_run"`
+	AddMembers bool   `json:"add_members" form:"add_members"`
+}
+
+type TaskImportRow struct {
+	Line      int      `json:"line"`
+	Title     string   `json:"title"`
+	Status    uint     `json:"status"`
+	Priority  int      `json:"priority"`
+	StartDate string   `json:"start_date"`
+	DueDate   string   `json:"due_date"`
+	Creator   string   `json:"creator"`
+	Assignees []string `json:"assignees"`
+	TaskId    uint     `json:"task_id,omitempty"`
+	Warnings  []string `json:"warnings"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type TaskImportReport struct {
+	DryRun     bool            `json:"dry_run"`
+	Total      int             `json:"total"`
+	Created    int             `json:"created"`
+	Skipped    int             `json:"skipped"`
+	NewMembers []string        `json:"new_members"`
+	Rows       []TaskImportRow `json:"rows"`
+}
+
 type TaskAssigneeWithAvatar struct {
 	Avatar string `json:"avatar"`
 	models.TaskAssignee
@@ -81,6 +211,7 @@ type TaskResponse struct {
 	Title       string                   `json:"title"`
 	Desc        string                   `json:"desc"`
 	Status      uint                     `json:"status"`
+	StartDate   string                   `json:"start_date"`
 	DueDate     string                   `json:"due_date"`
 	Priority    int                      `json:"priority"`
 	ProjectId   uint                     `json:"project_id"`
@@ -96,6 +227,9 @@ func (t *TaskResponse) Set(task *models.Task, project *models.Project, creator *
 	t.Title = task.Title
 	t.Desc = task.Desc
 	t.Status = task.Status
+	if !task.StartDate.IsZero() {
+		t.StartDate = task.StartDate.Local().Format(time.DateTime)
+	}
 	if !task.DueDate.IsZero() {
 		t.DueDate = task.DueDate.Local().Format(time.DateTime)
 	}
@@ -114,6 +248,7 @@ type TaskWithMemberResponse struct {
 	Title       string         `json:"title"`
 	Desc        string         `json:"desc"`
 	Status      uint           `json:"status"`
+	StartDate   string         `json:"start_date"`
 	DueDate     string         `json:"due_date"`
 	Priority    int            `json:"priority"`
 	ProjectId   uint           `json:"project_id"`
@@ -129,6 +264,9 @@ func (t *TaskWithMemberResponse) Set(task *models.Task, project *models.Project,
 	t.Title = task.Title
 	t.Desc = task.Desc
 	t.Status = task.Status
+	if !task.StartDate.IsZero() {
+		t.StartDate = task.StartDate.Local().Format(time.DateTime)
+	}
 	if !task.DueDate.IsZero() {
 		t.DueDate = task.DueDate.Local().Format(time.DateTime)
 	}
@@ -140,6 +278,33 @@ func (t *TaskWithMemberResponse) Set(task *models.Task, project *models.Project,
 	return t
 }
 
+type TaskTimelineResponse struct {
+	Id           uint            `json:"id"`
+	Title        string          `json:"title"`
+	Status       uint            `json:"status"`
+	Priority     int             `json:"priority"`
+	Start        string          `json:"start"`
+	End          string          `json:"end"`
+	Dependencies []uint          `json:"dependencies"`
+	Assignees    []models.Member `json:"assignees"`
+}
+
+func (t *TaskTimelineResponse) Set(task *models.Task, dependencies []uint, assignees []models.Member) *TaskTimelineResponse {
+	t.Id = task.ID
+	t.Title = task.Title
+	t.Status = task.Status
+	t.Priority = task.Priority
+	if !task.StartDate.IsZero() {
+		t.Start = task.StartDate.Local().Format(time.DateTime)
+	}
+	if !task.DueDate.IsZero() {
+		t.End = task.DueDate.Local().Format(time.DateTime)
+	}
+	t.Dependencies = dependencies
+	t.Assignees = assignees
+	return t
+}
+
 type TaskPageResponse struct {
 	Total     int            `json:"total"`
 	Page      int            `json:"page"`
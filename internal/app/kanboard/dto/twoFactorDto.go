@@ -0,0 +1,33 @@
+package dto
+
+// LoginResponse 开启两步验证时只返回 Challenge，通过 /login/twoFactor 完成登录后返回令牌
+type LoginResponse struct {
+	Token         string        `json:"token,omitempty"`
+	RefreshToken  string        `json:"refresh_token,omitempty"`
+	ExpiresIn     int           `json:"expires_in,omitempty"`
+	User          *UserResponse `json:"user,omitempty"`
+	RecoveryCodes []string      `json:"recovery_codes,omitempty"`
+
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	SetupRequired     bool   `json:"setup_required,omitempty"`
+	Challenge         string `json:"challenge,omitempty"`
+	ChallengeExpires  int    `json:"challenge_expires_in,omitempty"`
+}
+
+type TwoFactorChallengeRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+}
+
+type TwoFactorLoginRequest struct {
+	Challenge string `json:"challenge" binding:"required"`
+	// 验证器中的 6 位验证码或恢复码
+	Code string `json:"code" binding:"required"`
+}
+
+type TwoFactorPasswordRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
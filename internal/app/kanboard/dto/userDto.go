@@ -16,6 +16,28 @@ type UserLoginRequest struct {
 	Password      string `json:"password" binding:"required" from:"password"`
 	CaptchaID     string `json:"captchaId" binding:"required" from:"captchaId"`
 	CaptchaAnswer string `json:"captchaAnswer" binding:"required" from:"captchaAnswer"`
+	Device        string `json:"device" form:"device"`
+}
+
+type ForgotPasswordRequest struct {
+	Email         string `json:"email" binding:"required,email"`
+	CaptchaID     string `json:"captchaId" binding:"required"`
+	CaptchaAnswer string `json:"captchaAnswer" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email         string `json:"email" binding:"required,email"`
+	CaptchaID     string `json:"captchaId" binding:"required"`
+	CaptchaAnswer string `json:"captchaAnswer" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
 type UserRegisterRequest struct {
@@ -29,14 +51,14 @@ type UserRegisterRequest struct {
 }
 
 type UserUpdateRequest struct {
-	ID     uint    `json:"id" form:"id" binding:"required"`
+	ID     uint    `json:"-" form:"-"`
 	Avatar *uint   `json:"avatar" form:"avatar"`
 	Email  *string `json:"email" form:"email"`
 	Mobile *string `json:"mobile" form:"mobile"`
 }
 
 type UserUpdatePasswordRequest struct {
-	ID      uint   `json:"id" form:"id" required:"true"`
+	ID      uint   `json:"-" form:"-"`
 	Current string `json:"current" form:"current" required:"true"`
 	New     string `json:"new" form:"new" required:"true"`
 }
@@ -49,44 +71,55 @@ type ProjectsWithIdAndAssignee struct {
 }
 
 type UserResponse struct {
-	ID         uint                        `json:"id"`
-	CreatedAt  string                      `json:"created_at"`
-	Username   string                      `json:"username"`
-	Avatar     string                      `json:"avatar"`
-	Gender     constant.Gender             `json:"gender"`
-	Email      string                      `json:"email"`
-	Mobile     string                      `json:"mobile"`
-	CreateFrom constant.From               `json:"create_from"`
-	Position   string                      `json:"position"`
-	Projects   []ProjectsWithIdAndAssignee `json:"projects"`
-	Assignee   *bool                       `json:"assignee,omitempty"`
+	ID            uint                        `json:"id"`
+	CreatedAt     string                      `json:"created_at"`
+	Username      string                      `json:"username"`
+	Avatar        string                      `json:"avatar"`
+	Gender        constant.Gender             `json:"gender"`
+	Email         string                      `json:"email"`
+	EmailVerified bool                        `json:"email_verified"`
+	Mobile        string                      `json:"mobile"`
+	CreateFrom    constant.From               `json:"create_from"`
+	Position      string                      `json:"position"`
+	Projects      []ProjectsWithIdAndAssignee `json:"projects"`
+	Assignee      *bool                       `json:"assignee,omitempty"`
 }
 
 func (r *UserResponse) Set(user *models.User, resource *models.Resource, Projects []ProjectsWithIdAndAssignee, assignee *bool) *UserResponse {
 	return &UserResponse{
-		ID:         user.ID,
-		CreatedAt:  user.CreatedAt.Local().Format(time.DateTime),
-		Username:   user.Username,
-		Avatar:     resource.StaticPath,
-		Gender:     user.Gender,
-		Email:      user.Email,
-		Mobile:     user.Mobile,
-		CreateFrom: user.CreateFrom,
-		Position:   user.Position,
-		Projects:   Projects,
-		Assignee:   assignee,
+		ID:            user.ID,
+		CreatedAt:     user.CreatedAt.Local().Format(time.DateTime),
+		Username:      user.Username,
+		Avatar:        resource.StaticPath,
+		Gender:        user.Gender,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified(),
+		Mobile:        user.Mobile,
+		CreateFrom:    user.CreateFrom,
+		Position:      user.Position,
+		Projects:      Projects,
+		Assignee:      assignee,
 	}
 }
 
+type UserStatsRequest struct {
+	From      *int64 `json:"from" form:"from"`
+	To        *int64 `json:"to" form:"to"`
+	WeekStart *int   `json:"week_start" form:"week_start"`
+}
+
 type UserStatsResponse struct {
-	TotalTasks      int64 `json:"total_tasks"`
-	DoneTasks       int64 `json:"done_tasks"`
-	InProgressTasks int64 `json:"in_progress_tasks"`
-	Projects        int64 `json:"projects"`
-	LastWeekTasks   int64 `json:"last_week_tasks"`
-	ThisWeekTasks   int64 `json:"this_week_tasks"`
-	LastMonthTasks  int64 `json:"last_month_tasks"`
-	ThisMonthTasks  int64 `json:"this_month_tasks"`
+	TotalTasks      int64  `json:"total_tasks"`
+	DoneTasks       int64  `json:"done_tasks"`
+	InProgressTasks int64  `json:"in_progress_tasks"`
+	Projects        int64  `json:"projects"`
+	LastWeekTasks   int64  `json:"last_week_tasks"`
+	ThisWeekTasks   int64  `json:"this_week_tasks"`
+	LastMonthTasks  int64  `json:"last_month_tasks"`
+	ThisMonthTasks  int64  `json:"this_month_tasks"`
+	RangeTasks      int64  `json:"range_tasks"`
+	From            string `json:"from"`
+	To              string `json:"to"`
 }
 
 type UserCalendarResponse struct {
@@ -0,0 +1,178 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"server/internal/models"
+)
+
+type WebhookCreateDto struct {
+	ProjectId uint     `json:"project_id" form:"project_id" binding:"required"`
+	URL       string   `json:"url" form:"url" binding:"required"`
+	Events    []string `json:"events" form:"events"`
+	Secret    string   `json:"secret" form:"secret"`
+	Enabled   *bool    `json:"enabled" form:"enabled"`
+}
+
+type WebhookUpdateDto struct {
+	Id           uint      `json:"id" form:"id" binding:"required"`
+	ProjectId    uint      `json:"project_id" form:"project_id" binding:"required"`
+	URL          *string   `json:"url" form:"url"`
+	Events       *[]string `json:"events" form:"events"`
+	Enabled      *bool     `json:"enabled" form:"enabled"`
+	RotateSecret bool      `json:"rotate_secret" form:"rotate_secret"`
+}
+
+type WebhookIdDto struct {
+	Id        uint `json:"id" form:"id" binding:"required"`
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+}
+
+type WebhooksDto struct {
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+}
+
+type WebhookDeliveryDto struct {
+	Id        uint `json:"id" form:"id" binding:"required"`
+	ProjectId uint `json:"project_id" form:"project_id" binding:"required"`
+	PageRequest
+}
+
+type WebhookResponse struct {
+	Id        uint     `json:"id"`
+	ProjectId uint     `json:"project_id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Enabled   bool     `json:"enabled"`
+	Secret    string   `json:"secret,omitempty"`
+	CreatorId uint     `json:"creator_id"`
+	CreatedAt string   `json:"created_at"`
+	UpdatedAt string   `json:"updated_at"`
+}
+
+// 密钥只在创建或重置时返回
+func (w *WebhookResponse) Set(webhook *models.Webhook, withSecret bool) *WebhookResponse {
+	w.Id = webhook.ID
+	w.ProjectId = webhook.ProjectID
+	w.URL = webhook.URL
+	w.Events = []string{}
+	if webhook.Events != "" {
+		w.Events = strings.Split(webhook.Events, ",")
+	}
+	w.Enabled = webhook.Enabled
+	if withSecret {
+		w.Secret = webhook.Secret
+	}
+	w.CreatorId = webhook.CreatorID
+	w.CreatedAt = webhook.CreatedAt.Local().Format(time.DateTime)
+	w.UpdatedAt = webhook.UpdatedAt.Local().Format(time.DateTime)
+	return w
+}
+
+type WebhookDeliveryResponse struct {
+	Id           uint   `json:"id"`
+	WebhookId    uint   `json:"webhook_id"`
+	Event        string `json:"event"`
+	Payload      string `json:"payload"`
+	Status       string `json:"status"`
+	Attempts     int    `json:"attempts"`
+	ResponseCode int    `json:"response_code"`
+	Error        string `json:"error"`
+	NextRetryAt  string `json:"next_retry_at"`
+	DeliveredAt  string `json:"delivered_at"`
+	CreatedAt    string `json:"created_at"`
+}
+
+func (w *WebhookDeliveryResponse) Set(delivery *models.WebhookDelivery) *WebhookDeliveryResponse {
+	w.Id = delivery.ID
+	w.WebhookId = delivery.WebhookID
+	w.Event = delivery.Event
+	w.Payload = delivery.Payload
+	w.Status = delivery.Status
+	w.Attempts = delivery.Attempts
+	w.ResponseCode = delivery.ResponseCode
+	w.Error = delivery.Error
+	if delivery.NextRetryAt != nil {
+		w.NextRetryAt = delivery.NextRetryAt.Local().Format(time.DateTime)
+	}
+	if delivery.DeliveredAt != nil {
+		w.DeliveredAt = delivery.DeliveredAt.Local().Format(time.DateTime)
+	}
+	w.CreatedAt = delivery.CreatedAt.Local().Format(time.DateTime)
+	return w
+}
+
+type WebhookDeliveryPageResponse struct {
+	Total     int                       `json:"total"`
+	Page      int                       `json:"page"`
+	PageSize  int                       `json:"size"`
+	TotalPage int                       `json:"total_page"`
+	Data      []WebhookDeliveryResponse `json:"data"`
+}
+
+func (w *WebhookDeliveryPageResponse) Set(total int64, page int, pageSize int, data []WebhookDeliveryResponse) *WebhookDeliveryPageResponse {
+	w.Total = int(total)
+	w.Page = page
+	w.PageSize = pageSize
+	totalPage := int(float64(total) / float64(pageSize))
+	if total%int64(pageSize) != 0 {
+		totalPage++
+	}
+	w.TotalPage = totalPage
+	w.Data = data
+	return w
+}
+
+// 投递给订阅方的请求体，时间字段使用 RFC 3339 格式
+type WebhookPayload struct {
+	Event      string                 `json:"event"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Project    WebhookProjectPayload  `json:"project"`
+	Task       *WebhookTaskPayload    `json:"task,omitempty"`
+	ActorId    *uint                  `json:"actor_id,omitempty"`
+	Content    string                 `json:"content,omitempty"`
+	Members    []models.ProjectMember `json:"members,omitempty"`
+}
+
+type WebhookProjectPayload struct {
+	Id   uint   `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebhookTaskPayload struct {
+	Id          uint            `json:"id"`
+	Title       string          `json:"title"`
+	Desc        string          `json:"desc"`
+	Status      uint            `json:"status"`
+	Priority    int             `json:"priority"`
+	StartDate   *time.Time      `json:"start_date"`
+	DueDate     *time.Time      `json:"due_date"`
+	CompletedAt *time.Time      `json:"completed_at"`
+	CreatorId   uint            `json:"creator_id"`
+	Assignees   []models.Member `json:"assignees"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Deleted     bool            `json:"deleted"`
+}
+
+func (w *WebhookTaskPayload) Set(task *models.Task, assignees []models.Member) *WebhookTaskPayload {
+	w.Id = task.ID
+	w.Title = task.Title
+	w.Desc = task.Desc
+	w.Status = task.Status
+	w.Priority = task.Priority
+	if !task.StartDate.IsZero() {
+		w.StartDate = &task.StartDate
+	}
+	if !task.DueDate.IsZero() {
+		w.DueDate = &task.DueDate
+	}
+	w.CompletedAt = task.CompletedAt
+	w.CreatorId = task.CreatorID
+	w.Assignees = assignees
+	w.CreatedAt = task.CreatedAt
+	w.UpdatedAt = task.UpdatedAt
+	w.Deleted = task.DeletedAt.Valid
+	return w
+}
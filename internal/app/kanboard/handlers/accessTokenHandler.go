@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccessTokenHandler struct {
+	accessTokenService *services.AccessTokenService
+}
+
+var accessTokenHandler *AccessTokenHandler
+
+func NewAccessTokenHandler() *AccessTokenHandler {
+	if accessTokenHandler == nil {
+		accessTokenHandler = &AccessTokenHandler{
+			accessTokenService: services.NewAccessTokenService(),
+		}
+	}
+	return accessTokenHandler
+}
+
+func (a AccessTokenHandler) GetScopes(ctx *gin.Context) {
+	common.Ok(ctx, common.RspOpts{
+		Data: a.accessTokenService.GetScopes(),
+	})
+}
+
+func (a AccessTokenHandler) GetAccessTokens(ctx *gin.Context) {
+	data, err := a.accessTokenService.GetAccessTokens(utils.GetUserId(ctx))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (a AccessTokenHandler) CreateAccessToken(ctx *gin.Context) {
+	var request dto.AccessTokenCreateDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := a.accessTokenService.CreateAccessToken(request, utils.GetPrincipal(ctx))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "创建成功",
+	})
+}
+
+func (a AccessTokenHandler) RevokeAccessToken(ctx *gin.Context) {
+	var request dto.AccessTokenIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := a.accessTokenService.RevokeAccessToken(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "吊销成功",
+	})
+}
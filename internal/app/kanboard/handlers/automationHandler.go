@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AutomationHandler struct {
+	automationService *services.AutomationService
+}
+
+var automationHandler *AutomationHandler
+
+func NewAutomationHandler() *AutomationHandler {
+	if automationHandler == nil {
+		automationHandler = &AutomationHandler{
+			automationService: services.NewAutomationService(),
+		}
+	}
+
+	return automationHandler
+}
+
+func (a AutomationHandler) GetRules(ctx *gin.Context) {
+	var request dto.AutomationRulesDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := a.automationService.GetRules(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (a AutomationHandler) CreateRule(ctx *gin.Context) {
+	var request dto.AutomationRuleCreateDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := a.automationService.CreateRule(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "创建成功",
+	})
+}
+
+func (a AutomationHandler) UpdateRule(ctx *gin.Context) {
+	var request dto.AutomationRuleUpdateDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	if err := a.automationService.UpdateRule(request, userId); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "更新成功",
+	})
+}
+
+func (a AutomationHandler) DeleteRule(ctx *gin.Context) {
+	var request dto.AutomationRuleIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	if err := a.automationService.DeleteRule(request, userId); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "删除成功",
+	})
+}
+
+func (a AutomationHandler) GetLogs(ctx *gin.Context) {
+	var request dto.AutomationLogDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := a.automationService.GetLogs(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
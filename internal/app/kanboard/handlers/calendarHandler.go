@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarHandler struct {
+	calendarService *services.CalendarService
+}
+
+var calendarHandler *CalendarHandler
+
+func NewCalendarHandler() *CalendarHandler {
+	if calendarHandler == nil {
+		calendarHandler = &CalendarHandler{
+			calendarService: services.NewCalendarService(),
+		}
+	}
+
+	return calendarHandler
+}
+
+func (c CalendarHandler) GetCalendarTasks(ctx *gin.Context) {
+	var request dto.CalendarRangeDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := c.calendarService.GetCalendarTasks(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (c CalendarHandler) CreateFeed(ctx *gin.Context) {
+	var request dto.CalendarFeedCreateDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := c.calendarService.CreateFeed(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "生成成功",
+	})
+}
+
+func (c CalendarHandler) GetFeeds(ctx *gin.Context) {
+	userId := utils.GetUserId(ctx)
+
+	data, err := c.calendarService.GetFeeds(userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (c CalendarHandler) RevokeFeed(ctx *gin.Context) {
+	var request dto.CalendarFeedIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	if err := c.calendarService.RevokeFeed(request, userId); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "撤销成功",
+	})
+}
+
+// 日历客户端轮询的订阅地址，使用订阅令牌而非登录令牌鉴权
+func (c CalendarHandler) GetFeed(ctx *gin.Context) {
+	var request dto.CalendarFeedTokenDto
+
+	if err := utils.BindUri(ctx, &request); err != nil {
+		return
+	}
+
+	calendar, err := c.calendarService.GetFeedCalendar(request.Token)
+	if err != nil {
+		common.NotFound(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	ctx.Header("Content-Type", "text/calendar; charset=utf-8")
+	ctx.Header("Content-Disposition", "inline; filename=calendar.ics")
+	ctx.Header("Cache-Control", "no-cache")
+	if _, err := calendar.WriteTo(ctx.Writer); err != nil {
+		ctx.Error(err)
+	}
+}
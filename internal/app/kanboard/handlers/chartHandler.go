@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChartHandler struct {
+	chartService     *services.ChartService
+	analyticsService *services.AnalyticsService
+}
+
+var chartHandler *ChartHandler
+
+func NewChartHandler() *ChartHandler {
+	if chartHandler == nil {
+		chartHandler = &ChartHandler{
+			chartService:     services.NewChartService(),
+			analyticsService: services.NewAnalyticsService(),
+		}
+	}
+
+	return chartHandler
+}
+
+func (c ChartHandler) GetCumulativeFlow(ctx *gin.Context) {
+	var request dto.ChartRangeDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := c.chartService.GetCumulativeFlow(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (c ChartHandler) GetBurndown(ctx *gin.Context) {
+	var request dto.ChartRangeDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := c.chartService.GetBurndown(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (c ChartHandler) GetFlowMetrics(ctx *gin.Context) {
+	var request dto.FlowMetricsDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := c.analyticsService.GetFlowMetrics(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
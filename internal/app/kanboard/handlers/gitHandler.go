@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 代码托管平台推送内容的大小上限
+const GIT_HOOK_MAX_BODY = 5 << 20
+
+type GitHandler struct {
+	gitService *services.GitService
+}
+
+var gitHandler *GitHandler
+
+func NewGitHandler() *GitHandler {
+	if gitHandler == nil {
+		gitHandler = &GitHandler{
+			gitService: services.NewGitService(),
+		}
+	}
+
+	return gitHandler
+}
+
+func (g GitHandler) GetIntegrations(ctx *gin.Context) {
+	var request dto.GitIntegrationsDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := g.gitService.GetIntegrations(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (g GitHandler) CreateIntegration(ctx *gin.Context) {
+	var request dto.GitIntegrationCreateDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := g.gitService.CreateIntegration(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "创建成功",
+	})
+}
+
+func (g GitHandler) DeleteIntegration(ctx *gin.Context) {
+	var request dto.GitIntegrationIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	if err := g.gitService.DeleteIntegration(request, userId); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "删除成功",
+	})
+}
+
+func (g GitHandler) GetTaskCommits(ctx *gin.Context) {
+	var request dto.TaskCommitsDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := g.gitService.GetTaskCommits(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+// 代码托管平台回调地址，使用接入密钥校验签名而非登录令牌鉴权
+func (g GitHandler) ReceiveHook(ctx *gin.Context) {
+	var request dto.GitHookUriDto
+
+	if err := utils.BindUri(ctx, &request); err != nil {
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, GIT_HOOK_MAX_BODY))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	data, err := g.gitService.HandleHook(request.Id, ctx.Request.Header, body)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
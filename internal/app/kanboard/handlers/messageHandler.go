@@ -20,13 +20,9 @@ func NewMessageHandler() *MessageHandler {
 }
 
 func (m MessageHandler) GetUnReadMsgs(ctx *gin.Context) {
-	var request dto.UserIDRequest
+	userId := utils.GetUserId(ctx)
 
-	if err := utils.BindUri(ctx, &request); err != nil {
-		return
-	}
-
-	data := m.messageService.GetUnReadMsgs(request.ID)
+	data := m.messageService.GetUnReadMsgs(userId)
 
 	common.Ok(ctx, common.RspOpts{
 		Data: data,
@@ -34,13 +30,9 @@ func (m MessageHandler) GetUnReadMsgs(ctx *gin.Context) {
 }
 
 func (m MessageHandler) GetReadedMsgs(ctx *gin.Context) {
-	var request dto.UserIDRequest
-
-	if err := utils.BindUri(ctx, &request); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	data := m.messageService.GetReadedMsgs(request.ID)
+	data := m.messageService.GetReadedMsgs(userId)
 
 	common.Ok(ctx, common.RspOpts{
 		Data: data,
@@ -53,6 +45,7 @@ func (m MessageHandler) MarkReadMsg(ctx *gin.Context) {
 	if err := utils.BindRequest(ctx, &request); err != nil {
 		return
 	}
+	request.UserId = utils.GetUserId(ctx)
 
 	err := m.messageService.MarkReadMsg(request.UserId, request.MsgId)
 	if err != nil {
@@ -72,7 +65,7 @@ func (m MessageHandler) GetMsgsByProjectId(ctx *gin.Context) {
 		return
 	}
 
-	data, err := m.messageService.GetMsgsByProjectId(request.Id)
+	data, err := m.messageService.GetMsgsByProjectId(request.Id, utils.GetUserId(ctx))
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
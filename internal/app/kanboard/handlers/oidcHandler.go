@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/session"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	oidcService *services.OIDCService
+}
+
+var oidcHandler *OIDCHandler
+
+func NewOIDCHandler() *OIDCHandler {
+	if oidcHandler == nil {
+		oidcHandler = &OIDCHandler{
+			oidcService: services.NewOIDCService(),
+		}
+	}
+	return oidcHandler
+}
+
+func (o OIDCHandler) Authorize(ctx *gin.Context) {
+	var request dto.OIDCAuthorizeRequest
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := o.oidcService.Authorize(session.NewClient(ctx, request.Device))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (o OIDCHandler) Callback(ctx *gin.Context) {
+	var request dto.OIDCCallbackRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := o.oidcService.Callback(request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg:  "登录成功",
+		Data: data,
+	})
+}
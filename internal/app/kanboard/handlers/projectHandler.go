@@ -32,7 +32,9 @@ func (p ProjectHandler) GetProjectById(ctx *gin.Context) {
 		return
 	}
 
-	data, err := p.projectService.GetProjectById(projectIdRequest.Id)
+	userId := utils.GetUserId(ctx)
+
+	data, err := p.projectService.GetProjectById(projectIdRequest.Id, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -51,6 +53,7 @@ func (p ProjectHandler) AddProjectMember(ctx *gin.Context) {
 	if err := utils.BindRequest(ctx, &request); err != nil {
 		return
 	}
+	request.UserId = utils.GetUserId(ctx)
 
 	err := p.projectService.AddProjectMember(&request)
 	if err != nil {
@@ -71,6 +74,7 @@ func (p ProjectHandler) RemoveProjectMember(ctx *gin.Context) {
 	if err := utils.BindRequest(ctx, &request); err != nil {
 		return
 	}
+	request.UserId = utils.GetUserId(ctx)
 
 	err := p.projectService.RemoveProjectMember(&request)
 	if err != nil {
@@ -85,19 +89,37 @@ func (p ProjectHandler) RemoveProjectMember(ctx *gin.Context) {
 	})
 }
 
-func (p ProjectHandler) GetProjectMembers(ctx *gin.Context) {
-	var request dto.ProjectIdDto
+func (p ProjectHandler) ChangeMemberRole(ctx *gin.Context) {
+	var request dto.ProjectMemberRoleDto
 
 	if err := utils.BindRequest(ctx, &request); err != nil {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
+	userId := utils.GetUserId(ctx)
+
+	if err := p.projectService.ChangeMemberRole(request, userId); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "修改成员角色成功",
+	})
+}
+
+func (p ProjectHandler) GetProjectRole(ctx *gin.Context) {
+	var request dto.ProjectIdDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
 		return
 	}
 
-	members, err := p.projectService.GetProjectMembers(request.Id, userIdRequest.ID)
+	userId := utils.GetUserId(ctx)
+
+	data, err := p.projectService.GetProjectRole(request.Id, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -106,23 +128,42 @@ func (p ProjectHandler) GetProjectMembers(ctx *gin.Context) {
 	}
 
 	common.Ok(ctx, common.RspOpts{
-		Data: members,
+		Data: data,
 	})
 }
 
-func (p ProjectHandler) GetMembers(ctx *gin.Context) {
+func (p ProjectHandler) GetProjectMembers(ctx *gin.Context) {
 	var request dto.ProjectIdDto
 
 	if err := utils.BindRequest(ctx, &request); err != nil {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
+	userId := utils.GetUserId(ctx)
+
+	members, err := p.projectService.GetProjectMembers(request.Id, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
 		return
 	}
 
-	members, err := p.projectService.GetMembers(request.Id, userIdRequest.ID)
+	common.Ok(ctx, common.RspOpts{
+		Data: members,
+	})
+}
+
+func (p ProjectHandler) GetMembers(ctx *gin.Context) {
+	var request dto.ProjectIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	members, err := p.projectService.GetMembers(request.Id, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
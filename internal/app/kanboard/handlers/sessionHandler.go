@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/session"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService *services.SessionService
+}
+
+var sessionHandler *SessionHandler
+
+func NewSessionHandler() *SessionHandler {
+	if sessionHandler == nil {
+		sessionHandler = &SessionHandler{
+			sessionService: services.NewSessionService(),
+		}
+	}
+	return sessionHandler
+}
+
+func (s SessionHandler) RefreshToken(ctx *gin.Context) {
+	var request dto.RefreshTokenDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := s.sessionService.RefreshToken(request, session.NewClient(ctx, ""))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (s SessionHandler) GetSessions(ctx *gin.Context) {
+	data, err := s.sessionService.GetSessions(utils.GetUserId(ctx), utils.GetSessionId(ctx))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (s SessionHandler) RevokeSession(ctx *gin.Context) {
+	var request dto.SessionIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := s.sessionService.RevokeSession(request, utils.GetUserId(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "注销成功",
+	})
+}
+
+func (s SessionHandler) RevokeAllSessions(ctx *gin.Context) {
+	var request dto.RevokeAllSessionsDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	err := s.sessionService.RevokeAllSessions(request, utils.GetUserId(ctx), utils.GetSessionId(ctx))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "注销成功",
+	})
+}
+
+func (s SessionHandler) Logout(ctx *gin.Context) {
+	if err := s.sessionService.Logout(utils.GetPrincipal(ctx)); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "退出成功",
+	})
+}
@@ -10,7 +10,9 @@ import (
 )
 
 type TaskHandler struct {
-	taskService *services.TaskService
+	taskService   *services.TaskService
+	exportService *services.ExportService
+	importService *services.ImportService
 }
 
 var taskHandler *TaskHandler
@@ -18,7 +20,9 @@ var taskHandler *TaskHandler
 func NewTaskHandler() *TaskHandler {
 	if taskHandler == nil {
 		taskHandler = &TaskHandler{
-			taskService: services.NewTaskService(),
+			taskService:   services.NewTaskService(),
+			exportService: services.NewExportService(),
+			importService: services.NewImportService(),
 		}
 	}
 
@@ -32,12 +36,9 @@ func (t TaskHandler) GetTaskListByProjectId(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	data, err := t.taskService.GetProjectTaskList(taskIdRequest, userIdRequest.ID)
+	data, err := t.taskService.GetProjectTaskList(taskIdRequest, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -57,12 +58,9 @@ func (t TaskHandler) GetTaskByProjectId(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	data, err := t.taskService.GetProjectTask(taskIdRequest, userIdRequest.ID)
+	data, err := t.taskService.GetProjectTask(taskIdRequest, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -76,13 +74,16 @@ func (t TaskHandler) GetTaskByProjectId(ctx *gin.Context) {
 }
 
 func (t TaskHandler) GetTaskListByUserId(ctx *gin.Context) {
-	var taskIdRequest dto.TaskGetDto
+	var pageRequest dto.PageRequest
 
-	if err := utils.BindQuery(ctx, &taskIdRequest); err != nil {
+	if err := utils.BindQuery(ctx, &pageRequest); err != nil {
 		return
 	}
 
-	data, err := t.taskService.GetTaskListByUserId(taskIdRequest)
+	data, err := t.taskService.GetTaskListByUserId(dto.TaskGetDto{
+		Id:          utils.GetUserId(ctx),
+		PageRequest: pageRequest,
+	})
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -96,13 +97,7 @@ func (t TaskHandler) GetTaskListByUserId(ctx *gin.Context) {
 }
 
 func (t TaskHandler) GetTaskByUserId(ctx *gin.Context) {
-	var taskIdRequest dto.TasksDTO
-
-	if err := utils.BindQuery(ctx, &taskIdRequest); err != nil {
-		return
-	}
-
-	data, err := t.taskService.GetTaskByUserId(taskIdRequest)
+	data, err := t.taskService.GetTaskByUserId(dto.TasksDTO{Id: utils.GetUserId(ctx)})
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -121,6 +116,7 @@ func (t TaskHandler) CreateTask(ctx *gin.Context) {
 	if err := utils.BindRequest(ctx, &createRequest); err != nil {
 		return
 	}
+	createRequest.UserId = utils.GetUserId(ctx)
 
 	data, err := t.taskService.CreateTask(createRequest)
 	if err != nil {
@@ -143,12 +139,9 @@ func (t TaskHandler) UpdateTask(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	err := t.taskService.UpdateTask(updateRequest, userIdRequest.ID)
+	err := t.taskService.UpdateTask(updateRequest, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -168,12 +161,9 @@ func (t TaskHandler) UpdateTaskStatus(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	err := t.taskService.UpdateTaskStatus(updateRequest, userIdRequest.ID)
+	err := t.taskService.UpdateTaskStatus(updateRequest, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -193,12 +183,9 @@ func (t TaskHandler) DeleteTask(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	err := t.taskService.DeleteTask(deleteRequest, userIdRequest.ID)
+	err := t.taskService.DeleteTask(deleteRequest, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -218,12 +205,9 @@ func (t TaskHandler) GetTask(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	data, err := t.taskService.GetTaskInfo(getTaskRequest, userIdRequest.ID)
+	data, err := t.taskService.GetTaskInfo(getTaskRequest, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -243,12 +227,9 @@ func (t TaskHandler) AddTaskAssignee(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	err := t.taskService.AddTaskAssignee(request, userIdRequest.ID)
+	err := t.taskService.AddTaskAssignee(request, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -268,12 +249,9 @@ func (t TaskHandler) RemoveTaskAssignee(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	err := t.taskService.RemoveTaskAssignee(request, userIdRequest.ID)
+	err := t.taskService.RemoveTaskAssignee(request, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -293,12 +271,31 @@ func (t TaskHandler) SearchTask(ctx *gin.Context) {
 		return
 	}
 
-	var userIdRequest dto.UserIDRequest
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
+	userId := utils.GetUserId(ctx)
+
+	tasks, err := t.taskService.SearchTask(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
 		return
 	}
 
-	tasks, err := t.taskService.SearchTask(request, userIdRequest.ID)
+	common.Ok(ctx, common.RspOpts{
+		Data: tasks,
+	})
+}
+
+func (t TaskHandler) GetProjectTimeline(ctx *gin.Context) {
+	var request dto.TasksDTO
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := t.taskService.GetProjectTimeline(request, userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -307,6 +304,129 @@ func (t TaskHandler) SearchTask(ctx *gin.Context) {
 	}
 
 	common.Ok(ctx, common.RspOpts{
-		Data: tasks,
+		Data: data,
+	})
+}
+
+func (t TaskHandler) RescheduleTask(ctx *gin.Context) {
+	var request dto.TaskRescheduleDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	err := t.taskService.RescheduleTask(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "更新任务时间成功",
+	})
+}
+
+func (t TaskHandler) AddTaskDependency(ctx *gin.Context) {
+	var request dto.TaskDependencyDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	err := t.taskService.AddTaskDependency(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "添加成功",
+	})
+}
+
+func (t TaskHandler) RemoveTaskDependency(ctx *gin.Context) {
+	var request dto.TaskDependencyDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	err := t.taskService.RemoveTaskDependency(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "移除成功",
+	})
+}
+
+func (t TaskHandler) ExportTasks(ctx *gin.Context) {
+	var request dto.TaskExportDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	err := t.exportService.ExportTasks(ctx, request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+}
+
+func (t TaskHandler) ImportTasks(ctx *gin.Context) {
+	var request dto.TaskImportDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: "请上传导入文件",
+		})
+		return
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+	defer file.Close()
+
+	data, err := t.importService.ImportTasks(request, file, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "导入完成",
 	})
 }
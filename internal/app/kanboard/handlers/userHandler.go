@@ -4,14 +4,18 @@ import (
 	"server/internal/app/kanboard/dto"
 	"server/internal/app/kanboard/services"
 	"server/internal/common"
+	"server/internal/session"
 	"server/internal/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userService   *services.UserService
-	publicService *services.PublicService
+	userService     *services.UserService
+	publicService   *services.PublicService
+	passwordService *services.PasswordService
+
+	verificationService *services.VerificationService
 }
 
 var userHandler *UserHandler
@@ -19,8 +23,11 @@ var userHandler *UserHandler
 func NewUserHandler() *UserHandler {
 	if userHandler == nil {
 		userHandler = &UserHandler{
-			userService:   services.NewUserService(),
-			publicService: services.NewPublicService(),
+			userService:     services.NewUserService(),
+			publicService:   services.NewPublicService(),
+			passwordService: services.NewPasswordService(),
+
+			verificationService: services.NewVerificationService(),
 		}
 	}
 
@@ -42,7 +49,53 @@ func (u UserHandler) Login(ctx *gin.Context) {
 		return
 	}
 
-	data, token, err := u.userService.Login(loginRequest)
+	data, err := u.userService.Login(loginRequest, session.NewClient(ctx, loginRequest.Device))
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	msg := "登录成功"
+	if data.TwoFactorRequired {
+		msg = "请完成两步验证"
+	}
+	common.Ok(ctx, common.RspOpts{
+		Msg:  msg,
+		Data: data,
+	})
+}
+
+func (u UserHandler) LoginTwoFactor(ctx *gin.Context) {
+	var request dto.TwoFactorLoginRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := u.userService.LoginTwoFactor(request)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg:  "登录成功",
+		Data: data,
+	})
+}
+
+func (u UserHandler) LoginTwoFactorSetup(ctx *gin.Context) {
+	var request dto.TwoFactorChallengeRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	data, err := u.userService.LoginTwoFactorSetup(request)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -51,11 +104,7 @@ func (u UserHandler) Login(ctx *gin.Context) {
 	}
 
 	common.Ok(ctx, common.RspOpts{
-		Msg: "登录成功",
-		Data: gin.H{
-			"token": token,
-			"user":  data,
-		},
+		Data: data,
 	})
 }
 
@@ -88,14 +137,100 @@ func (u UserHandler) Register(ctx *gin.Context) {
 	})
 }
 
-func (u UserHandler) GetUserById(ctx *gin.Context) {
-	var userIdRequest dto.UserIDRequest
+func (u UserHandler) ForgotPassword(ctx *gin.Context) {
+	var request dto.ForgotPasswordRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	captchaResult := u.publicService.VerifyCaptcha(request.CaptchaID, request.CaptchaAnswer)
+	if !captchaResult {
+		common.Fail(ctx, common.RspOpts{
+			Msg: "验证码错误或者过期",
+		})
+		return
+	}
 
-	if err := utils.BindUri(ctx, &userIdRequest); err != nil {
+	if err := u.passwordService.ForgotPassword(request); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
 		return
 	}
 
-	data, err := u.userService.GetUserInfo(userIdRequest)
+	common.Ok(ctx, common.RspOpts{
+		Msg: "如果该邮箱已注册，重置邮件已发送",
+	})
+}
+
+func (u UserHandler) ResetPassword(ctx *gin.Context) {
+	var request dto.ResetPasswordRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := u.passwordService.ResetPassword(request); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "密码重置成功，请重新登录",
+	})
+}
+
+func (u UserHandler) ResendVerification(ctx *gin.Context) {
+	var request dto.ResendVerificationRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	captchaResult := u.publicService.VerifyCaptcha(request.CaptchaID, request.CaptchaAnswer)
+	if !captchaResult {
+		common.Fail(ctx, common.RspOpts{
+			Msg: "验证码错误或者过期",
+		})
+		return
+	}
+
+	if err := u.verificationService.ResendVerification(request); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "如果该邮箱已注册且未验证，验证邮件已发送",
+	})
+}
+
+func (u UserHandler) VerifyEmail(ctx *gin.Context) {
+	var request dto.VerifyEmailRequest
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	if err := u.verificationService.VerifyEmail(request); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "邮箱验证成功",
+	})
+}
+
+func (u UserHandler) GetUserById(ctx *gin.Context) {
+	data, err := u.userService.GetUserInfo(dto.UserIDRequest{ID: utils.GetUserId(ctx)})
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -110,15 +245,11 @@ func (u UserHandler) GetUserById(ctx *gin.Context) {
 
 func (u UserHandler) UpdateInfo(ctx *gin.Context) {
 	var updateRequest dto.UserUpdateRequest
-	var idRequest dto.UserIDRequest
 
-	if err := utils.BindUri(ctx, &idRequest); err != nil {
-		return
-	}
 	if err := utils.BindRequest(ctx, &updateRequest); err != nil {
 		return
 	}
-	updateRequest.ID = idRequest.ID
+	updateRequest.ID = utils.GetUserId(ctx)
 
 	data, err := u.userService.UpdateInfo(updateRequest)
 	if err != nil {
@@ -136,15 +267,11 @@ func (u UserHandler) UpdateInfo(ctx *gin.Context) {
 
 func (u UserHandler) UpdatePassword(ctx *gin.Context) {
 	var updatePasswordRequest dto.UserUpdatePasswordRequest
-	var idRequest dto.UserIDRequest
 
-	if err := utils.BindUri(ctx, &idRequest); err != nil {
-		return
-	}
 	if err := utils.BindRequest(ctx, &updatePasswordRequest); err != nil {
 		return
 	}
-	updatePasswordRequest.ID = idRequest.ID
+	updatePasswordRequest.ID = utils.GetUserId(ctx)
 
 	if updatePasswordRequest.Current == "" || updatePasswordRequest.New == "" {
 		common.Fail(ctx, common.RspOpts{
@@ -167,12 +294,14 @@ func (u UserHandler) UpdatePassword(ctx *gin.Context) {
 }
 
 func (u UserHandler) GetStatistics(ctx *gin.Context) {
-	var userIdRequest dto.UserIDRequest
-	if utils.BindUri(ctx, &userIdRequest) != nil {
+	userId := utils.GetUserId(ctx)
+
+	var request dto.UserStatsRequest
+	if utils.BindQuery(ctx, &request) != nil {
 		return
 	}
 
-	data, err := u.userService.GetStatistics(userIdRequest.ID)
+	data, err := u.userService.GetStatistics(userId, request)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
@@ -186,12 +315,9 @@ func (u UserHandler) GetStatistics(ctx *gin.Context) {
 }
 
 func (u UserHandler) GetCalendar(ctx *gin.Context) {
-	var userIdRequest dto.UserIDRequest
-	if utils.BindUri(ctx, &userIdRequest) != nil {
-		return
-	}
+	userId := utils.GetUserId(ctx)
 
-	data, err := u.userService.GetCalendar(userIdRequest.ID)
+	data, err := u.userService.GetCalendar(userId)
 	if err != nil {
 		common.Fail(ctx, common.RspOpts{
 			Msg: err.Error(),
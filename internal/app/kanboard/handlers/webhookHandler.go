@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"server/internal/app/kanboard/dto"
+	"server/internal/app/kanboard/services"
+	"server/internal/common"
+	"server/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	webhookService *services.WebhookService
+}
+
+var webhookHandler *WebhookHandler
+
+func NewWebhookHandler() *WebhookHandler {
+	if webhookHandler == nil {
+		webhookHandler = &WebhookHandler{
+			webhookService: services.NewWebhookService(),
+		}
+	}
+
+	return webhookHandler
+}
+
+func (w WebhookHandler) GetWebhooks(ctx *gin.Context) {
+	var request dto.WebhooksDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := w.webhookService.GetWebhooks(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (w WebhookHandler) CreateWebhook(ctx *gin.Context) {
+	var request dto.WebhookCreateDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := w.webhookService.CreateWebhook(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "创建成功",
+	})
+}
+
+func (w WebhookHandler) UpdateWebhook(ctx *gin.Context) {
+	var request dto.WebhookUpdateDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := w.webhookService.UpdateWebhook(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+		Msg:  "更新成功",
+	})
+}
+
+func (w WebhookHandler) DeleteWebhook(ctx *gin.Context) {
+	var request dto.WebhookIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	if err := w.webhookService.DeleteWebhook(request, userId); err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Msg: "删除成功",
+	})
+}
+
+func (w WebhookHandler) GetDeliveries(ctx *gin.Context) {
+	var request dto.WebhookDeliveryDto
+
+	if err := utils.BindQuery(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	data, err := w.webhookService.GetDeliveries(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: data,
+	})
+}
+
+func (w WebhookHandler) Redeliver(ctx *gin.Context) {
+	var request dto.WebhookIdDto
+
+	if err := utils.BindRequest(ctx, &request); err != nil {
+		return
+	}
+
+	userId := utils.GetUserId(ctx)
+
+	id, err := w.webhookService.Redeliver(request, userId)
+	if err != nil {
+		common.Fail(ctx, common.RspOpts{
+			Msg: err.Error(),
+		})
+		return
+	}
+
+	common.Ok(ctx, common.RspOpts{
+		Data: gin.H{"id": id},
+		Msg:  "已重新投递",
+	})
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"server/internal/app/kanboard/dto"
 	"server/internal/app/kanboard/services"
 	"server/internal/utils"
 
@@ -24,11 +23,7 @@ func NewWSHandler() *WSHandler {
 }
 
 func (w WSHandler) WebSocket(ctx *gin.Context) {
-	var request dto.UserIDRequest
+	userId := utils.GetUserId(ctx)
 
-	if err := utils.BindUri(ctx, &request); err != nil {
-		return
-	}
-
-	w.wsService.HandleWebsocket(ctx, request.ID)
+	w.wsService.HandleWebsocket(ctx, userId)
 }
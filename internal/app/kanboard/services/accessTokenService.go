@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"server/internal/accesstoken"
+	"server/internal/app/kanboard/dto"
+	"server/internal/types"
+)
+
+type AccessTokenService struct{}
+
+var accessTokenService *AccessTokenService
+
+func NewAccessTokenService() *AccessTokenService {
+	if accessTokenService == nil {
+		accessTokenService = &AccessTokenService{}
+	}
+	return accessTokenService
+}
+
+func (a *AccessTokenService) GetScopes() []string {
+	return accesstoken.Scopes
+}
+
+func (a *AccessTokenService) GetAccessTokens(userId uint) ([]dto.AccessTokenResponse, error) {
+	accessTokens, err := accesstoken.List(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []dto.AccessTokenResponse{}
+	for _, item := range *accessTokens {
+		var response dto.AccessTokenResponse
+		data = append(data, *response.Set(&item))
+	}
+	return data, nil
+}
+
+// CreateAccessToken 管理员代为操作时不能为用户创建令牌
+func (a *AccessTokenService) CreateAccessToken(request dto.AccessTokenCreateDto, principal *types.Principal) (*dto.AccessTokenCreateResponse, error) {
+	if principal.OnBehalf {
+		return nil, errors.New("代为操作时不能创建访问令牌")
+	}
+
+	var expiresAt *time.Time
+	if request.ExpiresInDays != nil {
+		expires := time.Now().AddDate(0, 0, *request.ExpiresInDays)
+		expiresAt = &expires
+	}
+	token, accessToken, err := accesstoken.Create(principal.UserID, request.Name, request.Scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.AccessTokenCreateResponse{Token: token}
+	response.Set(accessToken)
+	return response, nil
+}
+
+func (a *AccessTokenService) RevokeAccessToken(request dto.AccessTokenIdDto, userId uint) error {
+	return accesstoken.Revoke(userId, request.Id)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/models"
+	"server/internal/repositories"
+)
+
+type AnalyticsService struct {
+	taskRepo           *repositories.TaskRepo
+	taskAssigneeRepo   *repositories.TaskAssigneeRepo
+	taskTransitionRepo *repositories.TaskTransitionRepo
+	policyService      *PolicyService
+	userRepo           *repositories.UserRepo
+}
+
+var analyticsService *AnalyticsService
+
+func NewAnalyticsService() *AnalyticsService {
+	if analyticsService == nil {
+		analyticsService = &AnalyticsService{
+			taskRepo:           repositories.NewTaskRepo(),
+			taskAssigneeRepo:   repositories.NewTaskAssigneeRepo(),
+			taskTransitionRepo: repositories.NewTaskTransitionRepo(),
+			policyService:      NewPolicyService(),
+			userRepo:           repositories.NewUserRepo(),
+		}
+	}
+	return analyticsService
+}
+
+type taskFlow struct {
+	task        models.Task
+	assignees   []models.TaskAssignee
+	completedAt time.Time
+	leadTime    time.Duration
+	cycleTime   *time.Duration
+}
+
+func (a *AnalyticsService) canViewAnalytics(projectId uint, userId uint) bool {
+	if a.policyService.Can(projectId, userId, constant.PERMISSION_EDIT_ANY) {
+		return true
+	}
+	user, err := a.userRepo.GetUserById(userId)
+	return err == nil && user.IsAdmin == constant.IS_ADMIN
+}
+
+func (a *AnalyticsService) GetFlowMetrics(request dto.FlowMetricsDto, userId uint) (*dto.FlowMetricsResponse, error) {
+	if !a.canViewAnalytics(request.Id, userId) {
+		return nil, errors.New("没有权限")
+	}
+	start, end, err := parseChartRange(request.From, request.To)
+	if err != nil {
+		return nil, err
+	}
+	rangeEnd := end.AddDate(0, 0, 1)
+
+	tasks, err := a.taskRepo.GetTaskByProjectId(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := a.taskTransitionRepo.GetTransitionsByProjectId(request.Id, rangeEnd)
+	if err != nil {
+		return nil, err
+	}
+	taskAssignees, err := a.taskAssigneeRepo.GetTaskAssigneesByProjectId(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	taskTransitions := make(map[uint][]models.TaskTransition)
+	for _, transition := range *transitions {
+		taskTransitions[transition.TaskID] = append(taskTransitions[transition.TaskID], transition)
+	}
+	assigneeMap := make(map[uint][]models.TaskAssignee)
+	for _, assignee := range *taskAssignees {
+		assigneeMap[assignee.TaskID] = append(assigneeMap[assignee.TaskID], assignee)
+	}
+
+	flows := []taskFlow{}
+	for _, task := range *tasks {
+		flow, ok := buildTaskFlow(task, taskTransitions[task.ID], assigneeMap[task.ID])
+		if !ok || flow.completedAt.Before(start) {
+			continue
+		}
+		if request.UserId != nil && !hasAssignee(flow.assignees, *request.UserId) {
+			continue
+		}
+		flows = append(flows, flow)
+	}
+
+	response := &dto.FlowMetricsResponse{
+		Overall:    summarizeFlows(flows),
+		ByPriority: []dto.PriorityFlowMetrics{},
+		ByAssignee: []dto.AssigneeFlowMetrics{},
+		Throughput: []dto.ThroughputResponse{},
+	}
+
+	for _, priority := range []int{constant.TASK_PRIORITY_HIGH, constant.TASK_PRIORITY_MEDIUM, constant.TASK_PRIORITY_LOW} {
+		group := []taskFlow{}
+		for _, flow := range flows {
+			if flow.task.Priority == priority {
+				group = append(group, flow)
+			}
+		}
+		response.ByPriority = append(response.ByPriority, dto.PriorityFlowMetrics{
+			Priority:    priority,
+			FlowMetrics: summarizeFlows(group),
+		})
+	}
+
+	assigneeFlows := make(map[uint][]taskFlow)
+	assigneeNames := make(map[uint]string)
+	for _, flow := range flows {
+		for _, assignee := range flow.assignees {
+			assigneeFlows[assignee.UserID] = append(assigneeFlows[assignee.UserID], flow)
+			assigneeNames[assignee.UserID] = assignee.Username
+		}
+	}
+	for assigneeId, group := range assigneeFlows {
+		response.ByAssignee = append(response.ByAssignee, dto.AssigneeFlowMetrics{
+			UserID:      assigneeId,
+			Username:    assigneeNames[assigneeId],
+			FlowMetrics: summarizeFlows(group),
+		})
+	}
+	sort.Slice(response.ByAssignee, func(i, j int) bool {
+		return response.ByAssignee[i].UserID < response.ByAssignee[j].UserID
+	})
+
+	weekCounts := make(map[string]int64)
+	for _, flow := range flows {
+		weekCounts[getWeekStart(flow.completedAt.Local(), constant.StatisticsConfig.WeekStart).Format(time.DateOnly)]++
+	}
+	for week := getWeekStart(start, constant.StatisticsConfig.WeekStart); week.Before(rangeEnd); week = week.AddDate(0, 0, 7) {
+		key := week.Format(time.DateOnly)
+		response.Throughput = append(response.Throughput, dto.ThroughputResponse{
+			Week:  key,
+			Count: weekCounts[key],
+		})
+	}
+
+	return response, nil
+}
+
+// buildTaskFlow 根据状态记录计算已完成任务的前置时间和周期时间
+func buildTaskFlow(task models.Task, history []models.TaskTransition, assignees []models.TaskAssignee) (taskFlow, bool) {
+	if len(history) == 0 || history[len(history)-1].ToStatus != constant.TASK_STATUS_DONE {
+		return taskFlow{}, false
+	}
+	completedAt := history[len(history)-1].CreatedAt
+	flow := taskFlow{
+		task:        task,
+		assignees:   assignees,
+		completedAt: completedAt,
+		leadTime:    completedAt.Sub(task.CreatedAt),
+	}
+	for _, transition := range history {
+		if transition.ToStatus == constant.TASK_STATUS_IN_PROGRESS {
+			cycleTime := completedAt.Sub(transition.CreatedAt)
+			flow.cycleTime = &cycleTime
+			break
+		}
+	}
+	return flow, true
+}
+
+func summarizeFlows(flows []taskFlow) dto.FlowMetrics {
+	leadTimes := []time.Duration{}
+	cycleTimes := []time.Duration{}
+	for _, flow := range flows {
+		leadTimes = append(leadTimes, flow.leadTime)
+		if flow.cycleTime != nil {
+			cycleTimes = append(cycleTimes, *flow.cycleTime)
+		}
+	}
+	return dto.FlowMetrics{
+		LeadTime:  summarizeDurations(leadTimes),
+		CycleTime: summarizeDurations(cycleTimes),
+	}
+}
+
+// summarizeDurations 统计耗时，单位为小时
+func summarizeDurations(durations []time.Duration) dto.DurationStats {
+	stats := dto.DurationStats{Count: len(durations)}
+	if len(durations) == 0 {
+		return stats
+	}
+	sort.Slice(durations, func(i, j int) bool {
+		return durations[i] < durations[j]
+	})
+
+	var total time.Duration
+	for _, duration := range durations {
+		total += duration
+	}
+	stats.Average = roundHours(total / time.Duration(len(durations)))
+	stats.P50 = roundHours(percentile(durations, 50))
+	stats.P85 = roundHours(percentile(durations, 85))
+	stats.P95 = roundHours(percentile(durations, 95))
+	return stats
+}
+
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := int(math.Ceil(float64(p) / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func roundHours(duration time.Duration) float64 {
+	return math.Round(duration.Hours()*100) / 100
+}
+
+func hasAssignee(assignees []models.TaskAssignee, userId uint) bool {
+	for _, assignee := range assignees {
+		if assignee.UserID == userId {
+			return true
+		}
+	}
+	return false
+}
+
+func getWeekStart(t time.Time, weekStart time.Weekday) time.Time {
+	offset := (int(t.Weekday()) - int(weekStart) + 7) % 7
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()).AddDate(0, 0, -offset)
+}
@@ -0,0 +1,433 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/event"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/utils"
+)
+
+const (
+	// 同一任务上由规则连续引发的事件超过该次数即视为循环
+	AUTOMATION_MAX_CHAIN   = 5
+	AUTOMATION_CHAIN_TTL   = time.Minute
+	AUTOMATION_SCAN_EVERY  = time.Minute
+	AUTOMATION_OVERDUE_TTL = 48 * time.Hour
+)
+
+var automationTriggers = map[constant.EventType]string{
+	constant.TASK_CREATED_EVENT:        constant.AUTOMATION_TRIGGER_TASK_CREATED,
+	constant.TASK_STATUS_CHANGED_EVENT: constant.AUTOMATION_TRIGGER_TASK_STATUS_CHANGED,
+	constant.TASK_OVERDUE_EVENT:        constant.AUTOMATION_TRIGGER_TASK_OVERDUE,
+}
+
+func init() {
+	event.KanboardSubscribe(func(event event.Event) {
+		if event.EventType == nil || event.ProjectID == nil || event.TaskID == nil {
+			return
+		}
+		trigger, ok := automationTriggers[*event.EventType]
+		if !ok {
+			return
+		}
+		NewAutomationService().HandleEvent(trigger, event)
+	})
+}
+
+type AutomationService struct {
+	automationRepo    *repositories.AutomationRepo
+	taskRepo          *repositories.TaskRepo
+	taskAssigneeRepo  *repositories.TaskAssigneeRepo
+	userRepo          *repositories.UserRepo
+	projectMemberRepo *repositories.ProjectMemberRepo
+	policyService     *PolicyService
+}
+
+var automationService *AutomationService
+
+func NewAutomationService() *AutomationService {
+	if automationService == nil {
+		automationService = &AutomationService{
+			automationRepo:    repositories.NewAutomationRepo(),
+			taskRepo:          repositories.NewTaskRepo(),
+			taskAssigneeRepo:  repositories.NewTaskAssigneeRepo(),
+			userRepo:          repositories.NewUserRepo(),
+			projectMemberRepo: repositories.NewProjectMemberRepo(),
+			policyService:     NewPolicyService(),
+		}
+	}
+	return automationService
+}
+
+func (a *AutomationService) GetRules(request dto.AutomationRulesDto, userId uint) ([]dto.AutomationRuleResponse, error) {
+	if err := a.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
+	rules, err := a.automationRepo.GetRulesByProjectId(request.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.AutomationRuleResponse{}
+	for _, rule := range *rules {
+		var response dto.AutomationRuleResponse
+		responses = append(responses, *response.Set(&rule))
+	}
+	return responses, nil
+}
+
+func (a *AutomationService) CreateRule(request dto.AutomationRuleCreateDto, userId uint) (uint, error) {
+	if err := a.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return 0, err
+	}
+	if err := a.validateRule(request.ProjectId, request.Trigger, request.Conditions, request.Action, request.Params); err != nil {
+		return 0, err
+	}
+	conditions, err := json.Marshal(request.Conditions)
+	if err != nil {
+		return 0, err
+	}
+	params, err := json.Marshal(request.Params)
+	if err != nil {
+		return 0, err
+	}
+
+	rule := models.AutomationRule{
+		ProjectID:  request.ProjectId,
+		Name:       request.Name,
+		Trigger:    request.Trigger,
+		Conditions: string(conditions),
+		Action:     request.Action,
+		Params:     string(params),
+		Enabled:    true,
+		CreatorID:  userId,
+	}
+	if request.Enabled != nil {
+		rule.Enabled = *request.Enabled
+	}
+	created, err := a.automationRepo.CreateRule(rule)
+	if err != nil {
+		return 0, err
+	}
+	return created.ID, nil
+}
+
+func (a *AutomationService) UpdateRule(request dto.AutomationRuleUpdateDto, userId uint) error {
+	if err := a.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return err
+	}
+	rule, err := a.automationRepo.GetRuleById(request.Id, request.ProjectId)
+	if err != nil {
+		return err
+	}
+	if rule.ID == 0 {
+		return errors.New("规则不存在")
+	}
+
+	var current dto.AutomationRuleResponse
+	current.Set(rule)
+	trigger, conditions, action, params := current.Trigger, current.Conditions, current.Action, current.Params
+	if request.Trigger != nil {
+		trigger = *request.Trigger
+	}
+	if request.Conditions != nil {
+		conditions = *request.Conditions
+	}
+	if request.Action != nil {
+		action = *request.Action
+	}
+	if request.Params != nil {
+		params = *request.Params
+	}
+	if err := a.validateRule(request.ProjectId, trigger, conditions, action, params); err != nil {
+		return err
+	}
+
+	conditionsJson, err := json.Marshal(conditions)
+	if err != nil {
+		return err
+	}
+	paramsJson, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	values := map[string]any{
+		"trigger":    trigger,
+		"conditions": string(conditionsJson),
+		"action":     action,
+		"params":     string(paramsJson),
+	}
+	if request.Name != nil {
+		values["name"] = *request.Name
+	}
+	if request.Enabled != nil {
+		values["enabled"] = *request.Enabled
+	}
+	return a.automationRepo.UpdateRule(values, request.Id, request.ProjectId)
+}
+
+func (a *AutomationService) DeleteRule(request dto.AutomationRuleIdDto, userId uint) error {
+	if err := a.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return err
+	}
+	return a.automationRepo.DeleteRule(request.Id, request.ProjectId)
+}
+
+func (a *AutomationService) GetLogs(request dto.AutomationLogDto, userId uint) (*dto.AutomationLogPageResponse, error) {
+	if err := a.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
+	rule, err := a.automationRepo.GetRuleById(request.Id, request.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if rule.ID == 0 {
+		return nil, errors.New("规则不存在")
+	}
+
+	total, err := a.automationRepo.GetLogCountByRuleId(rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	logs, err := a.automationRepo.GetLogsByRuleId(rule.ID, request.Page, request.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	data := []dto.AutomationLogResponse{}
+	for _, log := range *logs {
+		var response dto.AutomationLogResponse
+		data = append(data, *response.Set(&log))
+	}
+
+	var pageResponse dto.AutomationLogPageResponse
+	return pageResponse.Set(total, request.Page, request.PageSize, data), nil
+}
+
+func (a *AutomationService) validateRule(projectId uint, trigger string, conditions dto.AutomationConditions, action string, params dto.AutomationParams) error {
+	switch trigger {
+	case constant.AUTOMATION_TRIGGER_TASK_CREATED, constant.AUTOMATION_TRIGGER_TASK_STATUS_CHANGED, constant.AUTOMATION_TRIGGER_TASK_OVERDUE:
+	default:
+		return errors.New("不支持的触发条件")
+	}
+	if conditions.Status != nil && !isValidStatus(*conditions.Status) {
+		return errors.New("状态条件错误")
+	}
+	if conditions.Priority != nil && !isValidPriority(*conditions.Priority) {
+		return errors.New("优先级条件错误")
+	}
+
+	switch action {
+	case constant.AUTOMATION_ACTION_ASSIGN_USER:
+		if params.UserId == nil {
+			return errors.New("请选择负责人")
+		}
+		if !a.projectMemberRepo.CheckProjectMemberExist(projectId, *params.UserId) {
+			return errors.New("用户不是项目成员")
+		}
+	case constant.AUTOMATION_ACTION_SET_PRIORITY:
+		if params.Priority == nil || !isValidPriority(*params.Priority) {
+			return errors.New("优先级错误")
+		}
+	case constant.AUTOMATION_ACTION_SET_STATUS:
+		if params.Status == nil || !isValidStatus(*params.Status) {
+			return errors.New("状态错误")
+		}
+		// 状态变更为同一状态时规则会反复命中
+		if trigger == constant.AUTOMATION_TRIGGER_TASK_STATUS_CHANGED && conditions.Status != nil && *conditions.Status == *params.Status {
+			return errors.New("目标状态不能与触发状态相同")
+		}
+	case constant.AUTOMATION_ACTION_SEND_MESSAGE:
+		if strings.TrimSpace(params.Content) == "" {
+			return errors.New("消息内容不能为空")
+		}
+		switch params.Recipients {
+		case constant.AUTOMATION_RECIPIENT_ASSIGNEES, constant.AUTOMATION_RECIPIENT_MEMBERS, constant.AUTOMATION_RECIPIENT_CREATOR:
+		default:
+			return errors.New("消息接收人错误")
+		}
+	default:
+		return errors.New("不支持的动作")
+	}
+	return nil
+}
+
+func (a *AutomationService) HandleEvent(trigger string, e event.Event) {
+	projectId, taskId := *e.ProjectID, *e.TaskID
+	rules, err := a.automationRepo.GetEnabledRules(projectId, trigger)
+	if err != nil {
+		global.Logger.Errorw("get automation rules error", "error", err)
+		return
+	}
+	if len(*rules) == 0 {
+		return
+	}
+
+	// 由规则动作引起的事件累加链路深度，用户操作则重新计数
+	chainKey := strconv.Itoa(int(taskId))
+	var depth int64
+	if e.ActorID != nil && *e.ActorID == constant.AUTOMATION_ACTOR_ID {
+		depth = global.Redis.Incr(constant.AUTOMATION_CHAIN, chainKey, AUTOMATION_CHAIN_TTL)
+	} else {
+		global.Redis.Delete(constant.AUTOMATION_CHAIN, chainKey)
+	}
+
+	task, err := a.taskRepo.GetTaskById(taskId)
+	if err != nil || task.ID == 0 {
+		global.Logger.Warnw("automation task not found", "task", taskId, "trigger", trigger, "error", err)
+		return
+	}
+
+	for _, rule := range *rules {
+		var current dto.AutomationRuleResponse
+		current.Set(&rule)
+		if !matchAutomationConditions(current.Conditions, task, e) {
+			continue
+		}
+
+		log := models.AutomationLog{
+			RuleID:    rule.ID,
+			ProjectID: projectId,
+			TaskID:    taskId,
+			Trigger:   trigger,
+			Result:    constant.AUTOMATION_RESULT_SUCCESS,
+		}
+		if depth > AUTOMATION_MAX_CHAIN {
+			log.Result = constant.AUTOMATION_RESULT_SKIPPED
+			log.Message = fmt.Sprintf("规则连续触发超过 %d 次，疑似循环，已停止执行", AUTOMATION_MAX_CHAIN)
+		} else if message, err := a.executeAction(current, task); err != nil {
+			log.Result = constant.AUTOMATION_RESULT_FAILED
+			log.Message = err.Error()
+		} else {
+			log.Message = message
+		}
+
+		if err := a.automationRepo.CreateLog(log); err != nil {
+			global.Logger.Errorw("create automation log error", "error", err)
+		}
+	}
+}
+
+func matchAutomationConditions(conditions dto.AutomationConditions, task *models.Task, e event.Event) bool {
+	if conditions.Status != nil {
+		status := task.Status
+		if e.Status != nil {
+			status = *e.Status
+		}
+		if status != *conditions.Status {
+			return false
+		}
+	}
+	if conditions.Priority != nil && task.Priority != *conditions.Priority {
+		return false
+	}
+	return true
+}
+
+func (a *AutomationService) executeAction(rule dto.AutomationRuleResponse, task *models.Task) (string, error) {
+	params := rule.Params
+	switch rule.Action {
+	case constant.AUTOMATION_ACTION_ASSIGN_USER:
+		if !a.projectMemberRepo.CheckProjectMemberExist(task.ProjectID, *params.UserId) {
+			return "", errors.New("用户不是项目成员")
+		}
+		user, err := a.userRepo.GetUserById(*params.UserId)
+		if err != nil {
+			return "", err
+		}
+		assignee := models.TaskAssignee{UserID: user.ID, Username: user.Username}
+		if err := a.taskAssigneeRepo.AddTaskAssignee([]models.TaskAssignee{assignee}, task.ProjectID, task.ID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("添加负责人『%s』", user.Username), nil
+
+	case constant.AUTOMATION_ACTION_SET_PRIORITY:
+		if task.Priority == *params.Priority {
+			return "优先级未变化", nil
+		}
+		if err := a.taskRepo.UpdateTask(map[string]any{"priority": *params.Priority}, task.ID, task.ProjectID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("优先级修改为 %d", *params.Priority), nil
+
+	case constant.AUTOMATION_ACTION_SET_STATUS:
+		if err := a.taskRepo.UpdateTaskStatus(task.ID, task.ProjectID, *params.Status, constant.AUTOMATION_ACTOR_ID); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("状态修改为 %d", *params.Status), nil
+
+	case constant.AUTOMATION_ACTION_SEND_MESSAGE:
+		var recipients []uint
+		switch params.Recipients {
+		case constant.AUTOMATION_RECIPIENT_ASSIGNEES:
+			ids, err := a.taskAssigneeRepo.GetAllAssigneeIdByTaskId(task.ID)
+			if err != nil {
+				return "", err
+			}
+			recipients = *ids
+		case constant.AUTOMATION_RECIPIENT_MEMBERS:
+			ids, err := a.projectMemberRepo.GetAllMemberIdByProjectId(task.ProjectID)
+			if err != nil {
+				return "", err
+			}
+			recipients = *ids
+		case constant.AUTOMATION_RECIPIENT_CREATOR:
+			recipients = []uint{task.CreatorID}
+		}
+		recipients = utils.UniqueUintSlice(recipients)
+		if len(recipients) == 0 {
+			return "没有消息接收人", nil
+		}
+		content := strings.ReplaceAll(params.Content, "{task}", task.Title)
+		NewMessageService().SendMsg(content, recipients, task.ID, task.ProjectID, "")
+		return fmt.Sprintf("发送消息给 %d 人", len(recipients)), nil
+	}
+	return "", errors.New("不支持的动作")
+}
+
+// StartAutomationScheduler 定时扫描到期未完成的任务并发布到期事件
+// 每次扫描截止时间在 AUTOMATION_OVERDUE_TTL 内的全部任务，停机期间到期或改到过去时间的任务同样会被发现，
+// 重复的任务由 AUTOMATION_OVERDUE 去重，去重记录的有效期与扫描范围一致，因此不会重复发布
+func StartAutomationScheduler() {
+	go func() {
+		ticker := time.NewTicker(AUTOMATION_SCAN_EVERY)
+		defer ticker.Stop()
+		for now := time.Now(); ; now = <-ticker.C {
+			publishOverdueTasks(now)
+		}
+	}()
+}
+
+func publishOverdueTasks(now time.Time) {
+	tasks, err := repositories.NewTaskRepo().GetOverdueTasks(now.Add(-AUTOMATION_OVERDUE_TTL), now)
+	if err != nil {
+		global.Logger.Errorw("get overdue tasks error", "error", err)
+		return
+	}
+	for _, task := range *tasks {
+		// 多实例部署时同一任务的同一截止时间只发布一次
+		key := fmt.Sprintf("%d:%d", task.ID, task.DueDate.Unix())
+		if !global.Redis.SetNX(constant.AUTOMATION_OVERDUE, key, 1, AUTOMATION_OVERDUE_TTL) {
+			continue
+		}
+		eventType := constant.TASK_OVERDUE_EVENT
+		event.KanboardPublish(event.Event{EventType: &eventType, ProjectID: &task.ProjectID, TaskID: &task.ID, Status: &task.Status})
+	}
+}
+
+func isValidStatus(status uint) bool {
+	return status == constant.TASK_STATUS_UNDO || status == constant.TASK_STATUS_IN_PROGRESS || status == constant.TASK_STATUS_DONE
+}
+
+func isValidPriority(priority int) bool {
+	return priority == constant.TASK_PRIORITY_LOW || priority == constant.TASK_PRIORITY_MEDIUM || priority == constant.TASK_PRIORITY_HIGH
+}
@@ -0,0 +1,373 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/crypto"
+	"server/pkg/ics"
+)
+
+const (
+	CALENDAR_FEED_TOKEN_SIZE = 32
+	CALENDAR_MAX_DAYS        = 366
+)
+
+type CalendarService struct {
+	feedRepo          *repositories.CalendarFeedRepo
+	taskRepo          *repositories.TaskRepo
+	taskAssigneeRepo  *repositories.TaskAssigneeRepo
+	userRepo          *repositories.UserRepo
+	projectRepo       *repositories.ProjectRepo
+	projectMemberRepo *repositories.ProjectMemberRepo
+	policyService     *PolicyService
+}
+
+var calendarService *CalendarService
+
+func NewCalendarService() *CalendarService {
+	if calendarService == nil {
+		calendarService = &CalendarService{
+			feedRepo:          repositories.NewCalendarFeedRepo(),
+			taskRepo:          repositories.NewTaskRepo(),
+			taskAssigneeRepo:  repositories.NewTaskAssigneeRepo(),
+			userRepo:          repositories.NewUserRepo(),
+			projectRepo:       repositories.NewProjectRepo(),
+			projectMemberRepo: repositories.NewProjectMemberRepo(),
+			policyService:     NewPolicyService(),
+		}
+	}
+	return calendarService
+}
+
+func (c *CalendarService) GetCalendarTasks(request dto.CalendarRangeDto, userId uint) (*dto.CalendarRangeResponse, error) {
+	start, end, err := parseCalendarRange(request.From, request.To)
+	if err != nil {
+		return nil, err
+	}
+
+	query := make(map[string]any)
+	if request.ProjectId != nil {
+		if err := c.policyService.Authorize(*request.ProjectId, userId, constant.PERMISSION_VIEW); err != nil {
+			return nil, err
+		}
+		query["projectId"] = *request.ProjectId
+		if request.UserId != nil {
+			query["userId"] = *request.UserId
+		}
+	} else {
+		query["userId"] = userId
+	}
+	if request.Status != nil {
+		query["status"] = *request.Status
+	}
+
+	tasks, err := c.taskRepo.GetTasksInRange(query, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	taskIds := []uint{}
+	projectIds := []uint{}
+	for _, task := range *tasks {
+		taskIds = append(taskIds, task.ID)
+		projectIds = append(projectIds, task.ProjectID)
+	}
+	projectNames, err := c.getProjectNames(projectIds)
+	if err != nil {
+		return nil, err
+	}
+	taskAssignees := make(map[uint][]models.Member)
+	if len(taskIds) > 0 {
+		assignees, err := c.taskAssigneeRepo.GetTaskAssigneesByTaskIds(taskIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, assignee := range *assignees {
+			taskAssignees[assignee.TaskID] = append(taskAssignees[assignee.TaskID], models.Member{
+				UserID:   assignee.UserID,
+				Username: assignee.Username,
+			})
+		}
+	}
+
+	response := &dto.CalendarRangeResponse{
+		From:  start.Format(time.DateTime),
+		To:    end.Format(time.DateTime),
+		Tasks: []dto.CalendarTaskResponse{},
+	}
+	for _, task := range *tasks {
+		assignees := taskAssignees[task.ID]
+		if assignees == nil {
+			assignees = []models.Member{}
+		}
+		var taskResponse dto.CalendarTaskResponse
+		response.Tasks = append(response.Tasks, *taskResponse.Set(&task, projectNames[task.ProjectID], assignees))
+	}
+	return response, nil
+}
+
+// 默认返回当月，from 与 to 均为毫秒时间戳
+func parseCalendarRange(from *int64, to *int64) (time.Time, time.Time, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	if from != nil {
+		start = time.UnixMilli(*from).Local()
+	}
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	if to != nil {
+		end = time.UnixMilli(*to).Local()
+	}
+	if start.After(end) {
+		return start, end, errors.New("开始时间不能晚于结束时间")
+	}
+	if end.Sub(start) > CALENDAR_MAX_DAYS*24*time.Hour {
+		return start, end, errors.New("时间范围过大")
+	}
+	return start, end, nil
+}
+
+func (c *CalendarService) CreateFeed(request dto.CalendarFeedCreateDto, userId uint) (*dto.CalendarFeedResponse, error) {
+	var projectName string
+	if request.ProjectId != nil {
+		if err := c.policyService.Authorize(*request.ProjectId, userId, constant.PERMISSION_VIEW); err != nil {
+			return nil, err
+		}
+		project, err := c.projectRepo.GetProjectById(*request.ProjectId)
+		if err != nil {
+			return nil, err
+		}
+		projectName = project.Name
+	}
+
+	token, err := crypto.GenerateRandomToken(CALENDAR_FEED_TOKEN_SIZE)
+	if err != nil {
+		return nil, err
+	}
+	feed, err := c.feedRepo.ReplaceFeed(models.CalendarFeed{
+		UserID:    userId,
+		ProjectID: request.ProjectId,
+		TokenHash: crypto.HashToken(token),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 令牌只在生成时返回一次
+	url := fmt.Sprintf("%s/%s.ics", constant.CalendarConfig.FeedURL, token)
+	var response dto.CalendarFeedResponse
+	return response.Set(feed, projectName, url), nil
+}
+
+func (c *CalendarService) GetFeeds(userId uint) ([]dto.CalendarFeedResponse, error) {
+	feeds, err := c.feedRepo.GetFeedsByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	projectIds := []uint{}
+	for _, feed := range *feeds {
+		if feed.ProjectID != nil {
+			projectIds = append(projectIds, *feed.ProjectID)
+		}
+	}
+	projectNames, err := c.getProjectNames(projectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.CalendarFeedResponse{}
+	for _, feed := range *feeds {
+		var projectName string
+		if feed.ProjectID != nil {
+			projectName = projectNames[*feed.ProjectID]
+		}
+		var response dto.CalendarFeedResponse
+		responses = append(responses, *response.Set(&feed, projectName, ""))
+	}
+	return responses, nil
+}
+
+func (c *CalendarService) RevokeFeed(request dto.CalendarFeedIdDto, userId uint) error {
+	count, err := c.feedRepo.DeleteFeed(request.Id, userId)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("订阅不存在")
+	}
+	return nil
+}
+
+func (c *CalendarService) GetFeedCalendar(token string) (*ics.Calendar, error) {
+	token = strings.TrimSuffix(token, ".ics")
+	feed, err := c.feedRepo.GetFeedByTokenHash(crypto.HashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if feed.ID == 0 {
+		return nil, errors.New("订阅不存在")
+	}
+	user, err := c.userRepo.GetUserById(feed.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == 0 || !user.Loginable {
+		return nil, errors.New("订阅不存在")
+	}
+
+	since := time.Now().AddDate(0, 0, -constant.CalendarConfig.PastDays)
+	calendar := &ics.Calendar{
+		ProdID:  fmt.Sprintf("-//%s//Calendar Feed//ZH", constant.ServerConfig.Name),
+		Refresh: constant.CalendarConfig.Refresh,
+	}
+
+	var tasks *[]models.Task
+	if feed.ProjectID != nil {
+		// 退出项目后订阅随之失效
+		if !c.projectMemberRepo.CheckProjectMemberExist(*feed.ProjectID, feed.UserID) {
+			return nil, errors.New("订阅不存在")
+		}
+		project, err := c.projectRepo.GetProjectById(*feed.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		calendar.Name = project.Name
+		if project.Desc != nil {
+			calendar.Desc = *project.Desc
+		}
+		tasks, err = c.taskRepo.GetCalendarTasksByProjectId(*feed.ProjectID, since)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		calendar.Name = fmt.Sprintf("%s 的任务", user.Username)
+		tasks, err = c.taskRepo.GetCalendarTasksByUserId(feed.UserID, since)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	projectIds := []uint{}
+	for _, task := range *tasks {
+		projectIds = append(projectIds, task.ProjectID)
+	}
+	projectNames, err := c.getProjectNames(projectIds)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, task := range *tasks {
+		calendar.Components = append(calendar.Components, buildCalendarComponents(&task, projectNames[task.ProjectID], feed.ProjectID == nil, now)...)
+	}
+
+	if err := c.feedRepo.TouchFeed(feed.ID, now); err != nil {
+		global.Logger.Errorw("touch calendar feed error", "error", err)
+	}
+
+	return calendar, nil
+}
+
+func (c *CalendarService) getProjectNames(projectIds []uint) (map[uint]string, error) {
+	names := make(map[uint]string)
+	if len(projectIds) == 0 {
+		return names, nil
+	}
+	projects, err := c.projectRepo.GetProjectsByIds(projectIds)
+	if err != nil {
+		return nil, err
+	}
+	for _, project := range *projects {
+		names[project.ID] = project.Name
+	}
+	return names, nil
+}
+
+// 每个任务生成一个 VTODO，有截止时间的任务额外生成 VEVENT 以便只支持事件的客户端显示
+func buildCalendarComponents(task *models.Task, projectName string, withProject bool, now time.Time) []ics.Component {
+	summary := task.Title
+	if withProject && projectName != "" {
+		summary = fmt.Sprintf("[%s] %s", projectName, task.Title)
+	}
+	todo := ics.Component{
+		Type:       ics.COMPONENT_TODO,
+		UID:        fmt.Sprintf("task-%d@%s", task.ID, constant.ServerConfig.Name),
+		Stamp:      now,
+		Created:    task.CreatedAt,
+		Modified:   task.UpdatedAt,
+		Summary:    summary,
+		Desc:       task.Desc,
+		URL:        getTaskLink(task),
+		Priority:   getCalendarPriority(task.Priority),
+		Start:      task.StartDate,
+		Due:        task.DueDate,
+		Categories: []string{},
+	}
+	if projectName != "" {
+		todo.Categories = append(todo.Categories, projectName)
+	}
+
+	var percent int
+	switch task.Status {
+	case constant.TASK_STATUS_DONE:
+		todo.Status = ics.TODO_COMPLETED
+		todo.Completed = task.CompletedAt
+		percent = 100
+	case constant.TASK_STATUS_IN_PROGRESS:
+		todo.Status = ics.TODO_IN_PROCESS
+		percent = 50
+	default:
+		todo.Status = ics.TODO_NEEDS_ACTION
+	}
+	todo.PercentDone = &percent
+
+	components := []ics.Component{todo}
+	if !task.DueDate.IsZero() {
+		event := todo
+		event.Type = ics.COMPONENT_EVENT
+		event.UID = fmt.Sprintf("task-%d-due@%s", task.ID, constant.ServerConfig.Name)
+		event.Status = ics.EVENT_CONFIRMED
+		event.Due = time.Time{}
+		event.Completed = nil
+		event.PercentDone = nil
+		event.Start = task.DueDate
+		if !task.StartDate.IsZero() && task.StartDate.Before(task.DueDate) {
+			event.Start = task.StartDate
+			event.End = task.DueDate
+		}
+		components = append(components, event)
+	}
+	return components
+}
+
+func getTaskLink(task *models.Task) string {
+	if constant.CalendarConfig.TaskLink == "" {
+		return ""
+	}
+	replacer := strings.NewReplacer(
+		"{project}", strconv.Itoa(int(task.ProjectID)),
+		"{task}", strconv.Itoa(int(task.ID)),
+	)
+	return replacer.Replace(constant.CalendarConfig.TaskLink)
+}
+
+// iCalendar 优先级 1 最高，9 最低
+func getCalendarPriority(priority int) int {
+	switch priority {
+	case constant.TASK_PRIORITY_HIGH:
+		return 1
+	case constant.TASK_PRIORITY_LOW:
+		return 9
+	default:
+		return 5
+	}
+}
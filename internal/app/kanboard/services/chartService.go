@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/models"
+	"server/internal/repositories"
+)
+
+const (
+	CHART_DEFAULT_DAYS = 30
+	CHART_MAX_DAYS     = 366
+)
+
+type ChartService struct {
+	taskRepo           *repositories.TaskRepo
+	taskTransitionRepo *repositories.TaskTransitionRepo
+	policyService      *PolicyService
+}
+
+var chartService *ChartService
+
+func NewChartService() *ChartService {
+	if chartService == nil {
+		chartService = &ChartService{
+			taskRepo:           repositories.NewTaskRepo(),
+			taskTransitionRepo: repositories.NewTaskTransitionRepo(),
+			policyService:      NewPolicyService(),
+		}
+	}
+	return chartService
+}
+
+type dailyStatusCount struct {
+	date   time.Time
+	counts map[uint]int64
+}
+
+func parseChartRange(from *int64, to *int64) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if to != nil {
+		t := time.UnixMilli(*to).Local()
+		end = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	start := end.AddDate(0, 0, -(CHART_DEFAULT_DAYS - 1))
+	if from != nil {
+		t := time.UnixMilli(*from).Local()
+		start = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	}
+	if start.After(end) {
+		return start, end, errors.New("开始时间不能晚于结束时间")
+	}
+	if end.Sub(start) >= CHART_MAX_DAYS*24*time.Hour {
+		return start, end, errors.New("时间范围过大")
+	}
+	return start, end, nil
+}
+
+// getDailyStatusCounts 按天统计每个状态下的任务数，统计时刻为当天结束
+// 已删除的任务只计入删除之前的日期，删除任务不会改变历史数据
+func (c *ChartService) getDailyStatusCounts(projectId uint, start time.Time, end time.Time) ([]dailyStatusCount, error) {
+	tasks, err := c.taskRepo.GetTaskHistoryByProjectId(projectId, start)
+	if err != nil {
+		return nil, err
+	}
+	transitions, err := c.taskTransitionRepo.GetTransitionsByProjectId(projectId, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	taskTransitions := make(map[uint][]models.TaskTransition)
+	for _, transition := range *transitions {
+		taskTransitions[transition.TaskID] = append(taskTransitions[transition.TaskID], transition)
+	}
+	for _, task := range *tasks {
+		if _, ok := taskTransitions[task.ID]; !ok {
+			// 没有状态记录的历史任务，以当前状态作为创建时的状态
+			taskTransitions[task.ID] = []models.TaskTransition{{
+				TaskID:    task.ID,
+				ToStatus:  task.Status,
+				CreatedAt: task.CreatedAt,
+			}}
+		}
+	}
+
+	cursors := make(map[uint]int)
+	result := []dailyStatusCount{}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		dayEnd := day.AddDate(0, 0, 1)
+		counts := map[uint]int64{
+			constant.TASK_STATUS_UNDO:        0,
+			constant.TASK_STATUS_IN_PROGRESS: 0,
+			constant.TASK_STATUS_DONE:        0,
+		}
+		for _, task := range *tasks {
+			if task.DeletedAt.Valid && task.DeletedAt.Time.Before(dayEnd) {
+				continue
+			}
+			history := taskTransitions[task.ID]
+			cursor := cursors[task.ID]
+			for cursor < len(history) && history[cursor].CreatedAt.Before(dayEnd) {
+				cursor++
+			}
+			cursors[task.ID] = cursor
+			if cursor == 0 {
+				continue
+			}
+			counts[history[cursor-1].ToStatus]++
+		}
+		result = append(result, dailyStatusCount{date: day, counts: counts})
+	}
+	return result, nil
+}
+
+func (c *ChartService) GetCumulativeFlow(request dto.ChartRangeDto, userId uint) ([]dto.CumulativeFlowResponse, error) {
+	if err := c.policyService.Authorize(request.Id, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
+	start, end, err := parseChartRange(request.From, request.To)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := c.getDailyStatusCounts(request.Id, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []dto.CumulativeFlowResponse{}
+	for _, item := range daily {
+		data = append(data, dto.CumulativeFlowResponse{
+			Date:       item.date.Format(time.DateOnly),
+			Undo:       item.counts[constant.TASK_STATUS_UNDO],
+			InProgress: item.counts[constant.TASK_STATUS_IN_PROGRESS],
+			Done:       item.counts[constant.TASK_STATUS_DONE],
+		})
+	}
+	return data, nil
+}
+
+func (c *ChartService) GetBurndown(request dto.ChartRangeDto, userId uint) ([]dto.BurndownResponse, error) {
+	if err := c.policyService.Authorize(request.Id, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
+	start, end, err := parseChartRange(request.From, request.To)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := c.getDailyStatusCounts(request.Id, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []dto.BurndownResponse{}
+	for _, item := range daily {
+		var total int64
+		for _, count := range item.counts {
+			total += count
+		}
+		done := item.counts[constant.TASK_STATUS_DONE]
+		data = append(data, dto.BurndownResponse{
+			Date:      item.date.Format(time.DateOnly),
+			Total:     total,
+			Done:      done,
+			Remaining: total - done,
+		})
+	}
+	return data, nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+const EXPORT_BATCH_SIZE = 200
+
+type ExportService struct {
+	taskRepo         *repositories.TaskRepo
+	taskAssigneeRepo *repositories.TaskAssigneeRepo
+	userRepo         *repositories.UserRepo
+	projectRepo      *repositories.ProjectRepo
+	policyService    *PolicyService
+}
+
+var exportService *ExportService
+
+func NewExportService() *ExportService {
+	if exportService == nil {
+		exportService = &ExportService{
+			taskRepo:         repositories.NewTaskRepo(),
+			taskAssigneeRepo: repositories.NewTaskAssigneeRepo(),
+			userRepo:         repositories.NewUserRepo(),
+			projectRepo:      repositories.NewProjectRepo(),
+			policyService:    NewPolicyService(),
+		}
+	}
+	return exportService
+}
+
+func (e *ExportService) ExportTasks(ctx *gin.Context, request dto.TaskExportDto, userId uint) error {
+	if request.ProjectId == nil {
+		return errors.New("项目不能为空")
+	}
+	projectId := *request.ProjectId
+	if request.Format == "" {
+		request.Format = constant.EXPORT_FORMAT_CSV
+	}
+	if request.Format != constant.EXPORT_FORMAT_CSV && request.Format != constant.EXPORT_FORMAT_JSONL {
+		return errors.New("不支持的导出格式")
+	}
+	if err := e.policyService.Authorize(projectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return err
+	}
+	project, err := e.projectRepo.GetProjectById(projectId)
+	if err != nil {
+		return err
+	}
+
+	query := make(map[string]any)
+	if request.Priority != nil {
+		query["priority"] = *request.Priority
+	}
+	if request.Title != nil {
+		query["title"] = *request.Title
+	}
+	if request.CreatorId != nil {
+		query["creatorId"] = *request.CreatorId
+	}
+	if request.UserId != nil {
+		query["userId"] = *request.UserId
+	}
+
+	filename := fmt.Sprintf("project-%d-tasks-%s.%s", projectId, time.Now().Format("20060102150405"), request.Format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	if request.Format == constant.EXPORT_FORMAT_CSV {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		ctx.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+	}
+	ctx.Status(http.StatusOK)
+
+	csvWriter := csv.NewWriter(ctx.Writer)
+	jsonEncoder := json.NewEncoder(ctx.Writer)
+	if request.Format == constant.EXPORT_FORMAT_CSV {
+		// 写入 BOM，便于 Excel 识别 UTF-8
+		ctx.Writer.WriteString("\xEF\xBB\xBF")
+		csvWriter.Write(dto.TaskExportHeader)
+	}
+
+	creators := make(map[uint]string)
+	err = e.taskRepo.StreamSearchTask(query, projectId, EXPORT_BATCH_SIZE, func(tasks []models.Task) error {
+		records, err := e.buildExportRecords(tasks, project, creators)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if request.Format == constant.EXPORT_FORMAT_CSV {
+				if err := csvWriter.Write(record.CSV()); err != nil {
+					return err
+				}
+			} else if err := jsonEncoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		csvWriter.Flush()
+		ctx.Writer.Flush()
+		return csvWriter.Error()
+	})
+	if err != nil {
+		// 响应头已发送，只能记录错误
+		global.Logger.Errorw("export tasks error", "project", projectId, "error", err)
+	}
+	return nil
+}
+
+func (e *ExportService) buildExportRecords(tasks []models.Task, project *models.Project, creators map[uint]string) ([]dto.TaskExportRecord, error) {
+	taskIds := []uint{}
+	missingCreatorIds := []uint{}
+	for _, task := range tasks {
+		taskIds = append(taskIds, task.ID)
+		if _, ok := creators[task.CreatorID]; !ok {
+			missingCreatorIds = append(missingCreatorIds, task.CreatorID)
+		}
+	}
+
+	if len(missingCreatorIds) > 0 {
+		users, err := e.userRepo.GetUsersByIds(missingCreatorIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range *users {
+			creators[user.ID] = user.Username
+		}
+	}
+
+	taskAssignees, err := e.taskAssigneeRepo.GetTaskAssigneesByTaskIds(taskIds)
+	if err != nil {
+		return nil, err
+	}
+	assigneeMap := make(map[uint][]string)
+	for _, assignee := range *taskAssignees {
+		assigneeMap[assignee.TaskID] = append(assigneeMap[assignee.TaskID], assignee.Username)
+	}
+
+	records := []dto.TaskExportRecord{}
+	for _, task := range tasks {
+		assignees := assigneeMap[task.ID]
+		if assignees == nil {
+			assignees = []string{}
+		}
+		var record dto.TaskExportRecord
+		records = append(records, *record.Set(&task, project, creators[task.CreatorID], assignees))
+	}
+	return records, nil
+}
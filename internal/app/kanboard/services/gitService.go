@@ -0,0 +1,223 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/crypto"
+	"server/pkg/githook"
+
+	"gorm.io/gorm"
+)
+
+const GIT_SECRET_SIZE = 20
+
+type GitService struct {
+	gitIntegrationRepo *repositories.GitIntegrationRepo
+	taskRepo           *repositories.TaskRepo
+	projectMemberRepo  *repositories.ProjectMemberRepo
+	policyService      *PolicyService
+	taskService        *TaskService
+}
+
+var gitService *GitService
+
+func NewGitService() *GitService {
+	if gitService == nil {
+		gitService = &GitService{
+			gitIntegrationRepo: repositories.NewGitIntegrationRepo(),
+			taskRepo:           repositories.NewTaskRepo(),
+			projectMemberRepo:  repositories.NewProjectMemberRepo(),
+			policyService:      NewPolicyService(),
+			taskService:        NewTaskService(),
+		}
+	}
+	return gitService
+}
+
+func (g *GitService) GetIntegrations(request dto.GitIntegrationsDto, userId uint) ([]dto.GitIntegrationResponse, error) {
+	if err := g.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return nil, err
+	}
+	integrations, err := g.gitIntegrationRepo.GetIntegrationsByProjectId(request.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.GitIntegrationResponse{}
+	for _, integration := range *integrations {
+		var response dto.GitIntegrationResponse
+		responses = append(responses, *response.Set(&integration, false))
+	}
+	return responses, nil
+}
+
+func (g *GitService) CreateIntegration(request dto.GitIntegrationCreateDto, userId uint) (*dto.GitIntegrationResponse, error) {
+	if err := g.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return nil, err
+	}
+	secret := request.Secret
+	if secret == "" {
+		var err error
+		secret, err = crypto.GenerateRandomToken(GIT_SECRET_SIZE)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	integration := models.GitIntegration{
+		ProjectID:  request.ProjectId,
+		Provider:   request.Provider,
+		Secret:     secret,
+		CloseTasks: true,
+		CreatorID:  userId,
+	}
+	if request.CloseTasks != nil {
+		integration.CloseTasks = *request.CloseTasks
+	}
+	created, err := g.gitIntegrationRepo.CreateIntegration(integration)
+	if err != nil {
+		return nil, err
+	}
+
+	var response dto.GitIntegrationResponse
+	return response.Set(created, true), nil
+}
+
+func (g *GitService) DeleteIntegration(request dto.GitIntegrationIdDto, userId uint) error {
+	if err := g.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return err
+	}
+	return g.gitIntegrationRepo.DeleteIntegration(request.Id, request.ProjectId)
+}
+
+func (g *GitService) GetTaskCommits(request dto.TaskCommitsDto, userId uint) ([]dto.TaskCommitResponse, error) {
+	if err := g.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
+	if _, err := g.taskRepo.GetTaskByIdAndProjectId(request.TaskId, request.ProjectId); err != nil {
+		return nil, errors.New("任务不存在")
+	}
+	commits, err := g.gitIntegrationRepo.GetTaskCommitsByTaskId(request.TaskId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.TaskCommitResponse{}
+	for _, commit := range *commits {
+		var response dto.TaskCommitResponse
+		responses = append(responses, *response.Set(&commit))
+	}
+	return responses, nil
+}
+
+// 推送中的一条提交或一个合并请求
+type gitLink struct {
+	commit     models.TaskCommit
+	text       string
+	closeTasks bool
+}
+
+// HandleHook 处理代码托管平台推送的事件，校验签名后关联引用的任务，
+// 合入默认分支且带有关闭关键字时通过 UpdateTaskStatus 将任务标记为完成
+func (g *GitService) HandleHook(id uint, header http.Header, body []byte) (*dto.GitHookReport, error) {
+	integration, err := g.gitIntegrationRepo.GetIntegrationById(id)
+	if err != nil {
+		return nil, err
+	}
+	if integration.ID == 0 {
+		return nil, errors.New("接入不存在")
+	}
+	if !githook.Verify(integration.Provider, integration.Secret, header, body) {
+		return nil, errors.New("签名校验失败")
+	}
+
+	report := dto.GitHookReport{Linked: []uint{}, Closed: []uint{}}
+	event, err := githook.Parse(integration.Provider, header, body)
+	if errors.Is(err, githook.ErrUnsupportedEvent) {
+		return &report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report.Event = event.Type
+
+	links := []gitLink{}
+	if event.Type == githook.EVENT_PUSH {
+		for _, commit := range event.Commits {
+			links = append(links, gitLink{
+				commit: models.TaskCommit{
+					Kind:        githook.EVENT_PUSH,
+					Ref:         commit.ID,
+					Title:       strings.SplitN(commit.Message, "\n", 2)[0],
+					URL:         commit.URL,
+					Author:      commit.Author,
+					CommittedAt: commit.Timestamp,
+				},
+				text:       commit.Message,
+				closeTasks: event.OnDefaultBranch(),
+			})
+		}
+	} else if request := event.MergeRequest; request != nil {
+		links = append(links, gitLink{
+			commit: models.TaskCommit{
+				Kind:        githook.EVENT_MERGE_REQUEST,
+				Ref:         strconv.Itoa(request.Number),
+				Title:       request.Title,
+				URL:         request.URL,
+				Author:      request.Author,
+				CommittedAt: request.MergedAt,
+			},
+			text:       request.Title + "\n" + request.Description,
+			closeTasks: request.Merged && event.OnDefaultBranch(),
+		})
+	}
+
+	for _, link := range links {
+		for _, reference := range githook.ParseReferences(link.text) {
+			task, err := g.taskRepo.GetTaskByIdAndProjectId(reference.TaskID, integration.ProjectID)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+
+			commit := link.commit
+			commit.TaskID = task.ID
+			commit.ProjectID = integration.ProjectID
+			commit.IntegrationID = integration.ID
+			commit.Repository = event.Repository
+			created, err := g.gitIntegrationRepo.CreateTaskCommit(commit)
+			if err != nil {
+				report.Errors = append(report.Errors, err.Error())
+				continue
+			}
+			if created {
+				report.Linked = append(report.Linked, task.ID)
+			}
+
+			if !reference.Close || !link.closeTasks || !integration.CloseTasks || task.Status == constant.TASK_STATUS_DONE {
+				continue
+			}
+			status := uint(constant.TASK_STATUS_DONE)
+			if err := g.taskService.UpdateTaskStatus(dto.TaskChangeStatusDto{
+				Id:        task.ID,
+				ProjectId: task.ProjectID,
+				Status:    &status,
+			}, integration.CreatorID); err != nil {
+				report.Errors = append(report.Errors, "任务 #"+strconv.Itoa(int(task.ID))+": "+err.Error())
+				continue
+			}
+			report.Closed = append(report.Closed, task.ID)
+		}
+	}
+	return &report, nil
+}
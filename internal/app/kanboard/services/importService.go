@@ -0,0 +1,333 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/importer"
+)
+
+const IMPORT_MAX_ROWS = 5000
+
+type ImportService struct {
+	taskService       *TaskService
+	userRepo          *repositories.UserRepo
+	projectRepo       *repositories.ProjectRepo
+	projectMemberRepo *repositories.ProjectMemberRepo
+	policyService     *PolicyService
+}
+
+var importService *ImportService
+
+func NewImportService() *ImportService {
+	if importService == nil {
+		importService = &ImportService{
+			taskService:       NewTaskService(),
+			userRepo:          repositories.NewUserRepo(),
+			projectRepo:       repositories.NewProjectRepo(),
+			projectMemberRepo: repositories.NewProjectMemberRepo(),
+			policyService:     NewPolicyService(),
+		}
+	}
+	return importService
+}
+
+var importStatus = map[string]uint{
+	"0":           constant.TASK_STATUS_UNDO,
+	"undo":        constant.TASK_STATUS_UNDO,
+	"todo":        constant.TASK_STATUS_UNDO,
+	"to do":       constant.TASK_STATUS_UNDO,
+	"open":        constant.TASK_STATUS_UNDO,
+	"backlog":     constant.TASK_STATUS_UNDO,
+	"未完成":         constant.TASK_STATUS_UNDO,
+	"待办":          constant.TASK_STATUS_UNDO,
+	"1":           constant.TASK_STATUS_IN_PROGRESS,
+	"in progress": constant.TASK_STATUS_IN_PROGRESS,
+	"doing":       constant.TASK_STATUS_IN_PROGRESS,
+	"in review":   constant.TASK_STATUS_IN_PROGRESS,
+	"进行中":         constant.TASK_STATUS_IN_PROGRESS,
+	"2":           constant.TASK_STATUS_DONE,
+	"done":        constant.TASK_STATUS_DONE,
+	"closed":      constant.TASK_STATUS_DONE,
+	"resolved":    constant.TASK_STATUS_DONE,
+	"complete":    constant.TASK_STATUS_DONE,
+	"completed":   constant.TASK_STATUS_DONE,
+	"已完成":         constant.TASK_STATUS_DONE,
+	"完成":          constant.TASK_STATUS_DONE,
+}
+
+var importPriority = map[string]int{
+	"1":        constant.TASK_PRIORITY_HIGH,
+	"high":     constant.TASK_PRIORITY_HIGH,
+	"highest":  constant.TASK_PRIORITY_HIGH,
+	"critical": constant.TASK_PRIORITY_HIGH,
+	"blocker":  constant.TASK_PRIORITY_HIGH,
+	"高":        constant.TASK_PRIORITY_HIGH,
+	"0":        constant.TASK_PRIORITY_MEDIUM,
+	"medium":   constant.TASK_PRIORITY_MEDIUM,
+	"normal":   constant.TASK_PRIORITY_MEDIUM,
+	"major":    constant.TASK_PRIORITY_MEDIUM,
+	"中":        constant.TASK_PRIORITY_MEDIUM,
+	"-1":       constant.TASK_PRIORITY_LOW,
+	"low":      constant.TASK_PRIORITY_LOW,
+	"lowest":   constant.TASK_PRIORITY_LOW,
+	"minor":    constant.TASK_PRIORITY_LOW,
+	"trivial":  constant.TASK_PRIORITY_LOW,
+	"低":        constant.TASK_PRIORITY_LOW,
+}
+
+type importTask struct {
+	rowIndex  int
+	record    importer.Record
+	creatorId uint
+	assignees []models.Member
+	startDate *int64
+	dueDate   *int64
+}
+
+func (i *ImportService) ImportTasks(request dto.TaskImportDto, reader io.Reader, userId uint) (*dto.TaskImportReport, error) {
+	if !i.projectRepo.CheckProjectExistById(request.ProjectId) {
+		return nil, errors.New("项目不存在")
+	}
+	if err := i.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_CREATE); err != nil {
+		return nil, err
+	}
+	if request.AddMembers {
+		if err := i.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_MEMBERS); err != nil {
+			return nil, err
+		}
+	}
+
+	mapping := map[string]string{}
+	if request.Mapping != "" {
+		if err := json.Unmarshal([]byte(request.Mapping), &mapping); err != nil {
+			return nil, errors.New("字段映射格式错误")
+		}
+	}
+	records, err := importer.Parse(request.Format, reader, mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) > IMPORT_MAX_ROWS {
+		return nil, fmt.Errorf("单次最多导入 %d 条任务", IMPORT_MAX_ROWS)
+	}
+
+	users, err := i.getUsersByRecords(records)
+	if err != nil {
+		return nil, err
+	}
+	memberIds, err := i.projectMemberRepo.GetAllMemberIdByProjectId(request.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	members := make(map[uint]bool)
+	for _, memberId := range *memberIds {
+		members[memberId] = true
+	}
+
+	report := &dto.TaskImportReport{
+		DryRun:     request.DryRun,
+		Total:      len(records),
+		NewMembers: []string{},
+		Rows:       []dto.TaskImportRow{},
+	}
+	newMembers := []models.Member{}
+	resolveMember := func(username string, row *dto.TaskImportRow) (*models.User, bool) {
+		user, ok := users[username]
+		if !ok {
+			row.Warnings = append(row.Warnings, fmt.Sprintf("用户『%s』不存在", username))
+			return nil, false
+		}
+		if !members[user.ID] {
+			if !request.AddMembers {
+				row.Warnings = append(row.Warnings, fmt.Sprintf("用户『%s』不是项目成员", username))
+				return nil, false
+			}
+			members[user.ID] = true
+			newMembers = append(newMembers, models.Member{UserID: user.ID, Username: user.Username})
+			report.NewMembers = append(report.NewMembers, user.Username)
+		}
+		return user, true
+	}
+
+	tasks := []importTask{}
+	for _, record := range records {
+		row := dto.TaskImportRow{
+			Line:      record.Line,
+			Title:     record.Title,
+			Creator:   record.Creator,
+			Assignees: []string{},
+			Warnings:  []string{},
+		}
+		task := importTask{record: record, creatorId: userId}
+
+		if record.Title == "" {
+			row.Error = "标题不能为空"
+			report.Rows = append(report.Rows, row)
+			continue
+		}
+
+		row.Status = constant.TASK_STATUS_UNDO
+		if record.Status != "" {
+			if status, ok := importStatus[strings.ToLower(strings.TrimSpace(record.Status))]; ok {
+				row.Status = status
+			} else {
+				row.Warnings = append(row.Warnings, fmt.Sprintf("无法识别的状态『%s』，按未完成导入", record.Status))
+			}
+		}
+
+		row.Priority = constant.TASK_PRIORITY_MEDIUM
+		if priority, ok := parseImportPriority(record); ok {
+			row.Priority = priority
+		} else if record.Priority != "" {
+			row.Warnings = append(row.Warnings, fmt.Sprintf("无法识别的优先级『%s』，按中优先级导入", record.Priority))
+		}
+
+		if record.StartDate != "" {
+			if startDate, err := importer.ParseTime(record.StartDate); err == nil {
+				ms := startDate.UnixMilli()
+				task.startDate = &ms
+				row.StartDate = startDate.Format(time.DateTime)
+			} else {
+				row.Warnings = append(row.Warnings, fmt.Sprintf("无法识别的开始时间『%s』", record.StartDate))
+			}
+		}
+		if record.DueDate != "" {
+			if dueDate, err := importer.ParseTime(record.DueDate); err == nil {
+				ms := dueDate.UnixMilli()
+				task.dueDate = &ms
+				row.DueDate = dueDate.Format(time.DateTime)
+			} else {
+				row.Warnings = append(row.Warnings, fmt.Sprintf("无法识别的截止时间『%s』", record.DueDate))
+			}
+		}
+		if task.startDate != nil && task.dueDate != nil && *task.startDate > *task.dueDate {
+			row.Warnings = append(row.Warnings, "开始时间晚于截止时间，已忽略开始时间")
+			task.startDate = nil
+			row.StartDate = ""
+		}
+
+		if record.Creator != "" {
+			if user, ok := resolveMember(record.Creator, &row); ok {
+				task.creatorId = user.ID
+			}
+		}
+		for _, username := range record.Assignees {
+			if user, ok := resolveMember(username, &row); ok {
+				task.assignees = append(task.assignees, models.Member{UserID: user.ID, Username: user.Username})
+				row.Assignees = append(row.Assignees, user.Username)
+			}
+		}
+
+		report.Rows = append(report.Rows, row)
+		task.rowIndex = len(report.Rows) - 1
+		tasks = append(tasks, task)
+	}
+
+	if err := i.userRepo.CheckMembersVerified(newMembers); err != nil {
+		return nil, err
+	}
+
+	if request.DryRun {
+		report.Created = len(tasks)
+		report.Skipped = report.Total - report.Created
+		return report, nil
+	}
+
+	if len(newMembers) > 0 {
+		if err := i.projectMemberRepo.AddProjectMember(newMembers, request.ProjectId); err != nil {
+			return nil, err
+		}
+	}
+
+	for index := range tasks {
+		task := &tasks[index]
+		row := &report.Rows[task.rowIndex]
+
+		assignees := task.assignees
+		if assignees == nil {
+			assignees = []models.Member{}
+		}
+		priority := row.Priority
+		taskId, err := i.taskService.CreateTask(dto.TaskCreateDto{
+			UserId:    userId,
+			CreatorId: task.creatorId,
+			ProjectId: request.ProjectId,
+			Title:     task.record.Title,
+			Desc:      task.record.Desc,
+			Priority:  &priority,
+			StartDate: task.startDate,
+			DueDate:   task.dueDate,
+			Assignees: &assignees,
+		})
+		if err != nil {
+			row.Error = err.Error()
+			continue
+		}
+		row.TaskId = taskId
+
+		if row.Status != constant.TASK_STATUS_UNDO {
+			status := row.Status
+			err := i.taskService.UpdateTaskStatus(dto.TaskChangeStatusDto{
+				Id:        taskId,
+				ProjectId: request.ProjectId,
+				Status:    &status,
+			}, userId)
+			if err != nil {
+				row.Warnings = append(row.Warnings, fmt.Sprintf("状态更新失败：%s", err.Error()))
+			}
+		}
+		report.Created++
+	}
+	report.Skipped = report.Total - report.Created
+
+	return report, nil
+}
+
+func (i *ImportService) getUsersByRecords(records []importer.Record) (map[string]*models.User, error) {
+	names := []string{}
+	seen := make(map[string]bool)
+	for _, record := range records {
+		for _, name := range append([]string{record.Creator}, record.Assignees...) {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	users := make(map[string]*models.User)
+	if len(names) == 0 {
+		return users, nil
+	}
+	result, err := i.userRepo.GetUsersByNames(names)
+	if err != nil {
+		return nil, err
+	}
+	for index := range *result {
+		user := &(*result)[index]
+		users[user.Username] = user
+	}
+	return users, nil
+}
+
+func parseImportPriority(record importer.Record) (int, bool) {
+	if record.Priority != "" {
+		priority, ok := importPriority[strings.ToLower(strings.TrimSpace(record.Priority))]
+		return priority, ok
+	}
+	for _, label := range record.Labels {
+		if priority, ok := importPriority[strings.ToLower(strings.TrimSpace(label))]; ok {
+			return priority, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"server/internal/constant"
+	"server/internal/global"
+	"server/pkg/mail"
+)
+
+const MAIL_TRANSPORT_SMTP = "smtp"
+
+type MailService struct {
+	sender mail.Sender
+}
+
+var mailService *MailService
+
+func NewMailService() *MailService {
+	if mailService == nil {
+		var sender mail.Sender = logSender{}
+		if constant.MailConfig.Transport == MAIL_TRANSPORT_SMTP {
+			config := constant.MailConfig
+			sender = mail.NewSMTPSender(config.Host, config.Port, config.Username, config.Password, config.From)
+		}
+		mailService = &MailService{
+			sender: sender,
+		}
+	}
+	return mailService
+}
+
+// SendAsync 在后台发送，失败只记录日志，不影响请求本身
+func (m *MailService) SendAsync(to string, subject string, body string) {
+	go func() {
+		message := mail.Message{To: []string{to}, Subject: subject, Body: body}
+		if err := m.sender.Send(message); err != nil {
+			global.Logger.Errorw("send mail error", "to", to, "subject", subject, "error", err)
+		}
+	}()
+}
+
+// logSender 只将邮件写入日志，用于未配置 SMTP 的开发环境
+type logSender struct{}
+
+func (logSender) Send(message mail.Message) error {
+	global.Logger.Infow("mail", "to", message.To, "subject", message.Subject, "body", message.Body)
+	return nil
+}
@@ -28,6 +28,9 @@ func init() {
 		if event.EventType == nil {
 			return
 		}
+		if *event.EventType != constant.PROJECT_EVENT && *event.EventType != constant.TASK_EVENT {
+			return
+		}
 
 		msgService := NewMessageService()
 
@@ -73,6 +76,7 @@ type MessageService struct {
 	resource          *repositories.ResourceRepo
 	project           *repositories.ProjectRepo
 	projectMemberRepo *repositories.ProjectMemberRepo
+	policyService     *PolicyService
 }
 
 var messageService *MessageService
@@ -85,6 +89,7 @@ func NewMessageService() *MessageService {
 			resource:          repositories.NewResourceRepo(),
 			project:           repositories.NewProjectRepo(),
 			projectMemberRepo: repositories.NewProjectMemberRepo(),
+			policyService:     NewPolicyService(),
 		}
 	}
 	return messageService
@@ -171,7 +176,10 @@ func (m *MessageService) MarkReadMsg(userID uint, msgID string) error {
 	return nil
 }
 
-func (m *MessageService) GetMsgsByProjectId(projectId uint) ([]dto.MsgResponse, error) {
+func (m *MessageService) GetMsgsByProjectId(projectId uint, userId uint) ([]dto.MsgResponse, error) {
+	if err := m.policyService.Authorize(projectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
 	messages, err := m.msgRepo.GetMsgsByProjectId(10, projectId)
 	if err != nil {
 		return nil, err
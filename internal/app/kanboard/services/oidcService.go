@@ -0,0 +1,241 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/session"
+	"server/pkg/crypto"
+	"server/pkg/oidc"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OIDC_STATE_EXPIRATION = 10 * time.Minute
+	OIDC_STATE_SIZE       = 32
+	// 单点登录用户不使用本地密码，创建时设置为随机值
+	OIDC_PASSWORD_SIZE = 32
+)
+
+type OIDCService struct {
+	userRepo         *repositories.UserRepo
+	userIdentityRepo *repositories.UserIdentityRepo
+	userService      *UserService
+
+	provider *oidc.Provider
+}
+
+var oidcService *OIDCService
+
+// 发起登录时保存在 Redis 中，回调时校验并取出
+type oidcState struct {
+	Verifier string `json:"verifier"`
+	Nonce    string `json:"nonce"`
+	Device   string `json:"device"`
+	IP       string `json:"ip"`
+}
+
+func NewOIDCService() *OIDCService {
+	if oidcService == nil {
+		config := constant.OIDCConfig
+		oidcService = &OIDCService{
+			userRepo:         repositories.NewUserRepo(),
+			userIdentityRepo: repositories.NewUserIdentityRepo(),
+			userService:      NewUserService(),
+
+			provider: oidc.NewProvider(oidc.Config{
+				Issuer:       config.Issuer,
+				ClientID:     config.ClientID,
+				ClientSecret: config.ClientSecret,
+				RedirectURL:  config.RedirectURL,
+				Scopes:       config.Scopes,
+				Timeout:      config.Timeout,
+			}),
+		}
+	}
+	return oidcService
+}
+
+// Authorize 生成带 PKCE 参数的授权地址，由前端跳转到身份提供方
+func (o *OIDCService) Authorize(client session.Client) (*dto.OIDCAuthorizeResponse, error) {
+	if !constant.OIDCConfig.Enabled {
+		return nil, errors.New("未开启单点登录")
+	}
+
+	state, err := crypto.GenerateRandomToken(OIDC_STATE_SIZE)
+	if err != nil {
+		return nil, errors.New("state生成失败")
+	}
+	nonce, err := crypto.GenerateRandomToken(OIDC_STATE_SIZE)
+	if err != nil {
+		return nil, errors.New("nonce生成失败")
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return nil, errors.New("code_verifier生成失败")
+	}
+
+	url, err := o.provider.AuthCodeURL(state, nonce, verifier)
+	if err != nil {
+		global.Logger.Errorw("oidc authorize error", "error", err)
+		return nil, errors.New("身份提供方不可用")
+	}
+
+	data, err := json.Marshal(oidcState{Verifier: verifier, Nonce: nonce, Device: client.Device, IP: client.IP})
+	if err != nil {
+		return nil, err
+	}
+	global.Redis.Set(constant.OIDC_STATE, crypto.HashToken(state), string(data), OIDC_STATE_EXPIRATION)
+
+	return &dto.OIDCAuthorizeResponse{
+		URL:       url,
+		ExpiresIn: int(OIDC_STATE_EXPIRATION.Seconds()),
+	}, nil
+}
+
+// Callback 使用授权码换取 ID Token 并登录，首次登录时创建用户
+// 两步验证由身份提供方负责，这里不再要求
+func (o *OIDCService) Callback(request dto.OIDCCallbackRequest) (*dto.LoginResponse, error) {
+	if !constant.OIDCConfig.Enabled {
+		return nil, errors.New("未开启单点登录")
+	}
+
+	value := global.Redis.GetDel(constant.OIDC_STATE, crypto.HashToken(request.State))
+	var state oidcState
+	if value == "" || json.Unmarshal([]byte(value), &state) != nil {
+		return nil, errors.New("登录已过期，请重新登录")
+	}
+
+	token, err := o.provider.Exchange(request.Code, state.Verifier)
+	if err != nil {
+		global.Logger.Errorw("oidc exchange error", "error", err)
+		return nil, errors.New("单点登录失败")
+	}
+	claims, err := o.provider.VerifyIDToken(token.IDToken, state.Nonce)
+	if err != nil {
+		global.Logger.Errorw("oidc verify error", "error", err)
+		return nil, errors.New("单点登录失败")
+	}
+
+	user, err := o.getOrCreateUser(claims)
+	if err != nil {
+		return nil, err
+	}
+	user, err = o.syncAdmin(user, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return o.userService.completeLogin(user, session.Client{Device: state.Device, IP: state.IP})
+}
+
+func (o *OIDCService) getOrCreateUser(claims oidc.Claims) (*models.User, error) {
+	provider := claims.Issuer()
+	identity, err := o.userIdentityRepo.GetIdentity(provider, claims.Subject())
+	if err != nil {
+		return nil, err
+	}
+	if identity.ID != 0 {
+		user, err := o.userRepo.GetUserById(identity.UserID)
+		if err == nil {
+			return user, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		// 本地用户已被删除，重新创建
+		if err := o.userIdentityRepo.DeleteIdentityById(identity.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := o.findOrProvision(claims)
+	if err != nil {
+		return nil, err
+	}
+	_, err = o.userIdentityRepo.CreateIdentity(models.UserIdentity{
+		UserID:   user.ID,
+		Provider: provider,
+		Subject:  claims.Subject(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (o *OIDCService) findOrProvision(claims oidc.Claims) (*models.User, error) {
+	config := constant.OIDCConfig
+	username := claims.String(config.UsernameClaim)
+	email := claims.String(config.EmailClaim)
+	emailVerified := email != "" && claims.Bool("email_verified")
+
+	if config.LinkByEmail && emailVerified {
+		user, err := o.userRepo.GetUserByEmail(email)
+		if err != nil {
+			return nil, err
+		}
+		if user.ID != 0 && user.EmailVerified() {
+			return user, nil
+		}
+	}
+
+	if username == "" {
+		return nil, errors.New("身份提供方未返回用户名")
+	}
+	var emailPtr *string
+	if email != "" {
+		emailPtr = &email
+	}
+	if isExist, err := o.userService.checkUserInfoExist(username, emailPtr, nil); isExist {
+		return nil, err
+	}
+
+	password, err := crypto.GenerateRandomToken(OIDC_PASSWORD_SIZE)
+	if err != nil {
+		return nil, errors.New("用户创建失败")
+	}
+	createUser := models.User{
+		Username:   username,
+		Password:   password,
+		Email:      email,
+		CreateFrom: constant.OIDC,
+		Loginable:  true,
+	}
+	if emailVerified {
+		now := time.Now()
+		createUser.EmailVerifiedAt = &now
+	}
+	return o.userRepo.CreateUser(createUser)
+}
+
+// syncAdmin 按身份提供方中的分组同步管理员标识
+func (o *OIDCService) syncAdmin(user *models.User, claims oidc.Claims) (*models.User, error) {
+	config := constant.OIDCConfig
+	if config.GroupsClaim == "" {
+		return user, nil
+	}
+	isAdmin := slices.ContainsFunc(claims.Strings(config.GroupsClaim), func(group string) bool {
+		return slices.Contains(config.AdminGroups, group)
+	})
+	if isAdmin == user.IsAdmin {
+		return user, nil
+	}
+	user, err := o.userRepo.UpdateUserById(map[string]any{"is_admin": isAdmin}, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 移出管理员分组后注销管理端会话
+	if !isAdmin {
+		session.RevokeAll(user.ID, constant.ADMIN_TOKEN, "")
+	}
+	return user, nil
+}
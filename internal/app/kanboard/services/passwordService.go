@@ -0,0 +1,88 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/repositories"
+	"server/internal/session"
+	"server/pkg/crypto"
+)
+
+const (
+	PASSWORD_RESET_EXPIRATION = 30 * time.Minute
+	// 同一用户两次发送重置邮件的最小间隔
+	PASSWORD_RESET_INTERVAL   = time.Minute
+	PASSWORD_RESET_TOKEN_SIZE = 32
+)
+
+type PasswordService struct {
+	userRepo    *repositories.UserRepo
+	mailService *MailService
+}
+
+var passwordService *PasswordService
+
+func NewPasswordService() *PasswordService {
+	if passwordService == nil {
+		passwordService = &PasswordService{
+			userRepo:    repositories.NewUserRepo(),
+			mailService: NewMailService(),
+		}
+	}
+	return passwordService
+}
+
+// ForgotPassword 无论邮箱是否注册都返回成功，避免被用来探测邮箱
+// 重新申请时之前的重置链接失效
+func (p *PasswordService) ForgotPassword(request dto.ForgotPasswordRequest) error {
+	user, err := p.userRepo.GetUserByEmail(request.Email)
+	if err != nil || user.ID == 0 {
+		return nil
+	}
+	userId := strconv.Itoa(int(user.ID))
+	if !global.Redis.SetNX(constant.PASSWORD_RESET_LIMIT, userId, 1, PASSWORD_RESET_INTERVAL) {
+		return nil
+	}
+
+	token, err := crypto.GenerateRandomToken(PASSWORD_RESET_TOKEN_SIZE)
+	if err != nil {
+		return errors.New("重置链接生成失败")
+	}
+	hash := crypto.HashToken(token)
+	if previous, _ := global.Redis.Get(constant.PASSWORD_RESET_USER, userId).(string); previous != "" {
+		global.Redis.Delete(constant.PASSWORD_RESET_TOKEN, previous)
+	}
+	global.Redis.Set(constant.PASSWORD_RESET_TOKEN, hash, userId, PASSWORD_RESET_EXPIRATION)
+	global.Redis.Set(constant.PASSWORD_RESET_USER, userId, hash, PASSWORD_RESET_EXPIRATION)
+
+	link := strings.ReplaceAll(constant.MailConfig.ResetLink, "{token}", token)
+	body := fmt.Sprintf("%s，您好：\n\n我们收到了重置密码的请求，请在 %d 分钟内打开以下链接设置新密码：\n\n%s\n\n如果不是您本人操作，请忽略此邮件，您的密码不会改变。\n",
+		user.Username, int(PASSWORD_RESET_EXPIRATION.Minutes()), link)
+	p.mailService.SendAsync(user.Email, "重置密码", body)
+	return nil
+}
+
+// ResetPassword 重置令牌只能使用一次，重置后注销该用户的全部会话
+func (p *PasswordService) ResetPassword(request dto.ResetPasswordRequest) error {
+	userId := global.Redis.GetDel(constant.PASSWORD_RESET_TOKEN, crypto.HashToken(request.Token))
+	if userId == "" {
+		return errors.New("重置链接无效或已过期")
+	}
+	global.Redis.Delete(constant.PASSWORD_RESET_USER, userId)
+
+	id, err := strconv.ParseUint(userId, 10, 0)
+	if err != nil {
+		return errors.New("重置链接无效或已过期")
+	}
+	if err := p.userRepo.UpdatePasswordById(request.Password, uint(id)); err != nil {
+		return err
+	}
+	return session.RevokeAll(uint(id), "", "")
+}
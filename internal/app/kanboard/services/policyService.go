@@ -0,0 +1,45 @@
+package services
+
+import (
+	"errors"
+
+	"server/internal/constant"
+	"server/internal/models"
+	"server/internal/policy"
+	"server/internal/repositories"
+)
+
+// PolicyService 按项目角色统一校验权限，角色与权限的对应关系见 policy 包
+type PolicyService struct {
+	projectMemberRepo *repositories.ProjectMemberRepo
+}
+
+var policyService *PolicyService
+
+func NewPolicyService() *PolicyService {
+	if policyService == nil {
+		policyService = &PolicyService{
+			projectMemberRepo: repositories.NewProjectMemberRepo(),
+		}
+	}
+	return policyService
+}
+
+func (p *PolicyService) Can(projectId uint, userId uint, permission string) bool {
+	return policy.Can(p.projectMemberRepo.GetMemberRole(projectId, userId), permission)
+}
+
+func (p *PolicyService) Authorize(projectId uint, userId uint, permission string) error {
+	if !p.Can(projectId, userId, permission) {
+		return errors.New("没有权限")
+	}
+	return nil
+}
+
+// AuthorizeTask 编辑或删除自己创建的任务只需要创建权限
+func (p *PolicyService) AuthorizeTask(task *models.Task, userId uint, permission string) error {
+	if task.CreatorID == userId {
+		permission = constant.PERMISSION_CREATE
+	}
+	return p.Authorize(task.ProjectID, userId, permission)
+}
@@ -4,7 +4,9 @@ import (
 	"errors"
 
 	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
 	"server/internal/models"
+	"server/internal/policy"
 	"server/internal/repositories"
 )
 
@@ -14,6 +16,7 @@ type ProjectService struct {
 	userRepo          *repositories.UserRepo
 	taskRepo          *repositories.TaskRepo
 	resourceRepo      *repositories.ResourceRepo
+	policyService     *PolicyService
 }
 
 var projectService *ProjectService
@@ -26,12 +29,16 @@ func NewProjectService() *ProjectService {
 			userRepo:          repositories.NewUserRepo(),
 			taskRepo:          repositories.NewTaskRepo(),
 			resourceRepo:      repositories.NewResourceRepo(),
+			policyService:     NewPolicyService(),
 		}
 	}
 	return projectService
 }
 
-func (p *ProjectService) GetProjectById(id uint) (*dto.ProjectWithUserResponse, error) {
+func (p *ProjectService) GetProjectById(id uint, userId uint) (*dto.ProjectWithUserResponse, error) {
+	if err := p.policyService.Authorize(id, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
 	project, err := p.projectRepo.GetProjectById(id)
 	if err != nil {
 		return nil, err
@@ -86,8 +93,12 @@ func (p *ProjectService) AddProjectMember(request *dto.ProjectAddMemberDto) erro
 	if !p.projectRepo.CheckProjectExistById(request.ProjectId) {
 		return errors.New("项目不存在")
 	}
-	if !p.projectMemberRepo.CheckAssignee(request.ProjectId, request.UserId) {
-		return errors.New("没有权限")
+	role := request.Role
+	if role == "" {
+		role = constant.PROJECT_ROLE_MEMBER
+	}
+	if err := p.authorizeRoleChange(request.ProjectId, request.UserId, role); err != nil {
+		return err
 	}
 	projectId := request.ProjectId
 	var members []models.Member
@@ -96,7 +107,10 @@ func (p *ProjectService) AddProjectMember(request *dto.ProjectAddMemberDto) erro
 			members = append(members, member)
 		}
 	}
-	if err := p.projectMemberRepo.AddProjectMember(members, projectId); err != nil {
+	if err := p.userRepo.CheckMembersVerified(members); err != nil {
+		return err
+	}
+	if err := p.projectMemberRepo.AddProjectMemberWithRole(members, projectId, role); err != nil {
 		return err
 	}
 
@@ -107,21 +121,88 @@ func (p *ProjectService) RemoveProjectMember(request *dto.ProjectRemoveMemberDto
 	if !p.projectRepo.CheckProjectExistById(request.ProjectId) {
 		return errors.New("项目不存在")
 	}
-	if !p.projectMemberRepo.CheckAssignee(request.ProjectId, request.UserId) {
-		return errors.New("没有权限")
-	}
 	projectId := request.ProjectId
 	memberId := request.MemberId
+	role := p.projectMemberRepo.GetMemberRole(projectId, memberId)
+	if role == "" {
+		return errors.New("用户不是项目成员")
+	}
+	if err := p.authorizeRoleChange(projectId, request.UserId, role); err != nil {
+		return err
+	}
+	if err := p.checkLastOwner(projectId, role); err != nil {
+		return err
+	}
 	err := p.projectMemberRepo.RemoveProjectMemberById(memberId, projectId)
 	return err
 }
 
+func (p *ProjectService) ChangeMemberRole(request dto.ProjectMemberRoleDto, userId uint) error {
+	if !p.projectRepo.CheckProjectExistById(request.ProjectId) {
+		return errors.New("项目不存在")
+	}
+	role := p.projectMemberRepo.GetMemberRole(request.ProjectId, request.MemberId)
+	if role == "" {
+		return errors.New("用户不是项目成员")
+	}
+	if role == request.Role {
+		return nil
+	}
+	if err := p.authorizeRoleChange(request.ProjectId, userId, role); err != nil {
+		return err
+	}
+	if err := p.authorizeRoleChange(request.ProjectId, userId, request.Role); err != nil {
+		return err
+	}
+	if err := p.checkLastOwner(request.ProjectId, role); err != nil {
+		return err
+	}
+	return p.projectMemberRepo.ChangeRole(request.MemberId, request.ProjectId, request.Role)
+}
+
+func (p *ProjectService) GetProjectRole(projectId uint, userId uint) (*dto.ProjectRoleResponse, error) {
+	role := p.projectMemberRepo.GetMemberRole(projectId, userId)
+	if role == "" {
+		return nil, errors.New("没有权限")
+	}
+	return &dto.ProjectRoleResponse{
+		Role:        role,
+		Permissions: policy.Permissions(role),
+	}, nil
+}
+
+// authorizeRoleChange 管理成员需要 manage_members 权限，授予或变更负责人只能由负责人操作
+func (p *ProjectService) authorizeRoleChange(projectId uint, userId uint, role string) error {
+	if err := p.policyService.Authorize(projectId, userId, constant.PERMISSION_MANAGE_MEMBERS); err != nil {
+		return err
+	}
+	if role == constant.PROJECT_ROLE_OWNER && p.projectMemberRepo.GetMemberRole(projectId, userId) != constant.PROJECT_ROLE_OWNER {
+		return errors.New("只有负责人可以变更负责人")
+	}
+	return nil
+}
+
+// 项目至少保留一名负责人
+func (p *ProjectService) checkLastOwner(projectId uint, role string) error {
+	if role != constant.PROJECT_ROLE_OWNER {
+		return nil
+	}
+	count, err := p.projectMemberRepo.GetMemberCountByRole(projectId, constant.PROJECT_ROLE_OWNER)
+	if err != nil {
+		return err
+	}
+	if count <= 1 {
+		return errors.New("项目至少需要一名负责人")
+	}
+	return nil
+}
+
 func (p *ProjectService) GetProjectMembers(projectId uint, userId uint) ([]models.ProjectMember, error) {
 	if !p.projectRepo.CheckProjectExistById(projectId) {
 		return nil, errors.New("项目不存在")
 	}
-	if !p.projectMemberRepo.CheckProjectMemberExist(projectId, userId) {
-		return nil, errors.New("没有权限")
+	if err := p.policyService.Authorize(projectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
 	}
 	_members, err := p.projectMemberRepo.GetMemberListByProjectId(projectId)
 	if err != nil {
@@ -135,9 +216,8 @@ func (p *ProjectService) GetProjectMembers(projectId uint, userId uint) ([]model
 }
 
 func (p *ProjectService) GetMembers(projectId uint, useId uint) ([]models.Member, error) {
-	isAssignee := p.projectMemberRepo.CheckAssignee(projectId, useId)
-	if !isAssignee {
-		return nil, errors.New("没有权限")
+	if err := p.policyService.Authorize(projectId, useId, constant.PERMISSION_MANAGE_MEMBERS); err != nil {
+		return nil, err
 	}
 	users, err := p.userRepo.GetAllUsers()
 	if err != nil {
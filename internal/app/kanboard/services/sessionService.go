@@ -0,0 +1,67 @@
+package services
+
+import (
+	"errors"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/session"
+	"server/internal/types"
+)
+
+type SessionService struct{}
+
+var sessionService *SessionService
+
+func NewSessionService() *SessionService {
+	if sessionService == nil {
+		sessionService = &SessionService{}
+	}
+	return sessionService
+}
+
+func (s *SessionService) RefreshToken(request dto.RefreshTokenDto, client session.Client) (*session.TokenPair, error) {
+	return session.Refresh(constant.KANBOARD_TOKEN, request.RefreshToken, client)
+}
+
+// GetSessions currentId 为当前请求所属的会话
+func (s *SessionService) GetSessions(userId uint, currentId string) ([]dto.SessionResponse, error) {
+	sessions, err := session.List(userId, constant.KANBOARD_TOKEN)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []dto.SessionResponse{}
+	for _, item := range *sessions {
+		var response dto.SessionResponse
+		data = append(data, *response.Set(&item, currentId))
+	}
+	return data, nil
+}
+
+func (s *SessionService) RevokeSession(request dto.SessionIdDto, userId uint) error {
+	target, err := session.Get(request.Id)
+	if err != nil {
+		return err
+	}
+	if target.ID == "" || target.UserID != userId || target.Namespace != constant.KANBOARD_TOKEN {
+		return errors.New("会话不存在")
+	}
+	return session.Revoke(target)
+}
+
+func (s *SessionService) RevokeAllSessions(request dto.RevokeAllSessionsDto, userId uint, currentId string) error {
+	exceptId := ""
+	if request.KeepCurrent {
+		exceptId = currentId
+	}
+	return session.RevokeAll(userId, constant.KANBOARD_TOKEN, exceptId)
+}
+
+// Logout 管理员代为操作时携带的是管理员自己的会话，不允许在此注销
+func (s *SessionService) Logout(principal *types.Principal) error {
+	if principal.OnBehalf {
+		return errors.New("代为操作时不能退出登录")
+	}
+	return session.Logout(principal.UserID, principal.SessionID, principal.TokenID)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
 	"server/internal/models"
 	"server/internal/repositories"
 )
@@ -16,6 +17,8 @@ type TaskService struct {
 	resourceRepo      *repositories.ResourceRepo
 	projectRepo       *repositories.ProjectRepo
 	projectMemberRepo *repositories.ProjectMemberRepo
+	dependencyRepo    *repositories.TaskDependencyRepo
+	policyService     *PolicyService
 }
 
 var taskService *TaskService
@@ -29,14 +32,16 @@ func NewTaskService() *TaskService {
 			resourceRepo:      repositories.NewResourceRepo(),
 			projectRepo:       repositories.NewProjectRepo(),
 			projectMemberRepo: repositories.NewProjectMemberRepo(),
+			dependencyRepo:    repositories.NewTaskDependencyRepo(),
+			policyService:     NewPolicyService(),
 		}
 	}
 	return taskService
 }
 
 func (t *TaskService) GetProjectTaskList(request dto.TaskGetDto, userId uint) (*dto.TaskPageResponse, error) {
-	if !t.projectMemberRepo.CheckProjectMemberExist(request.Id, userId) {
-		return nil, errors.New("没有权限")
+	if err := t.policyService.Authorize(request.Id, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
 	}
 	task, err := t.taskRepo.GetTaskByProjectIdLimt(request.Id, request.Page, request.PageSize)
 	if err != nil {
@@ -95,8 +100,8 @@ func (t *TaskService) GetProjectTaskList(request dto.TaskGetDto, userId uint) (*
 }
 
 func (t *TaskService) GetProjectTask(request dto.TasksDTO, userId uint) ([]dto.TaskResponse, error) {
-	if !t.projectMemberRepo.CheckProjectMemberExist(request.Id, userId) {
-		return nil, errors.New("没有权限")
+	if err := t.policyService.Authorize(request.Id, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
 	}
 	task, err := t.taskRepo.GetTaskByProjectId(request.Id)
 	if err != nil {
@@ -267,21 +272,30 @@ func (t *TaskService) GetTaskByUserId(request dto.TasksDTO) ([]dto.TaskResponse,
 }
 
 func (t *TaskService) CreateTask(request dto.TaskCreateDto) (uint, error) {
-	if !t.projectMemberRepo.CheckProjectMemberExist(request.ProjectId, request.UserId) {
-		return 0, errors.New("没有权限")
+	if err := t.policyService.Authorize(request.ProjectId, request.UserId, constant.PERMISSION_CREATE); err != nil {
+		return 0, err
 	}
 	var createTask models.Task
 
 	createTask.CreatorID = request.UserId
+	if request.CreatorId != 0 {
+		createTask.CreatorID = request.CreatorId
+	}
 	createTask.Title = request.Title
 	createTask.Desc = request.Desc
 	createTask.ProjectID = request.ProjectId
 	if request.Priority != nil {
 		createTask.Priority = *request.Priority
 	}
+	if request.StartDate != nil {
+		createTask.StartDate = time.UnixMilli(*request.StartDate)
+	}
 	if request.DueDate != nil {
 		createTask.DueDate = time.UnixMilli(*request.DueDate)
 	}
+	if !createTask.StartDate.IsZero() && !createTask.DueDate.IsZero() && createTask.StartDate.After(createTask.DueDate) {
+		return 0, errors.New("开始时间不能晚于截止时间")
+	}
 	task, err := t.taskRepo.CreateTask(createTask)
 	if err != nil {
 		return 0, err
@@ -310,9 +324,8 @@ func (t *TaskService) DeleteTask(request dto.TaskDeleteDto, userId uint) error {
 	if err != nil {
 		return err
 	}
-	isAssignee := t.projectMemberRepo.CheckAssignee(task.ProjectID, userId)
-	if task.CreatorID != userId && !isAssignee {
-		return errors.New("没有权限")
+	if err := t.policyService.AuthorizeTask(task, userId, constant.PERMISSION_DELETE); err != nil {
+		return err
 	}
 	if err := t.taskRepo.DeleteTaskById(request.Id); err != nil {
 		return err
@@ -320,18 +333,17 @@ func (t *TaskService) DeleteTask(request dto.TaskDeleteDto, userId uint) error {
 	if err := t.taskAssigneeRepo.DeleteTaskAssigneeById(request.Id); err != nil {
 		return err
 	}
+	if err := t.dependencyRepo.DeleteDependencyByTaskId(request.Id); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (t *TaskService) UpdateTaskStatus(request dto.TaskChangeStatusDto, userId uint) error {
-	if !t.projectMemberRepo.CheckProjectMemberExist(request.ProjectId, userId) {
-		return errors.New("没有权限")
-	}
-	values := make(map[string]any)
-	if request.Status != nil {
-		values["status"] = request.Status
+	if err := t.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_CREATE); err != nil {
+		return err
 	}
-	err := t.taskRepo.UpdateTask(values, request.Id, request.ProjectId)
+	err := t.taskRepo.UpdateTaskStatus(request.Id, request.ProjectId, *request.Status, userId)
 	if err != nil {
 		return err
 	}
@@ -343,9 +355,8 @@ func (t *TaskService) UpdateTask(request dto.TaskUpdateDto, userId uint) error {
 	if err != nil {
 		return err
 	}
-	isAssignee := t.projectMemberRepo.CheckAssignee(task.ProjectID, userId)
-	if task.CreatorID != userId && !isAssignee {
-		return errors.New("没有权限")
+	if err := t.policyService.AuthorizeTask(task, userId, constant.PERMISSION_EDIT_ANY); err != nil {
+		return err
 	}
 	values := make(map[string]any)
 	if request.Desc != nil {
@@ -354,8 +365,18 @@ func (t *TaskService) UpdateTask(request dto.TaskUpdateDto, userId uint) error {
 	if request.Priority != nil {
 		values["priority"] = *request.Priority
 	}
+	// 只修改其中一个日期时与已保存的另一个日期比较
+	startDate, dueDate := task.StartDate, task.DueDate
+	if request.StartDate != nil {
+		startDate = time.UnixMilli(*request.StartDate)
+		values["start_date"] = startDate
+	}
 	if request.DueDate != nil {
-		values["due_date"] = time.UnixMilli(*request.DueDate)
+		dueDate = time.UnixMilli(*request.DueDate)
+		values["due_date"] = dueDate
+	}
+	if !startDate.IsZero() && !dueDate.IsZero() && startDate.After(dueDate) {
+		return errors.New("开始时间不能晚于截止时间")
 	}
 	err = t.taskRepo.UpdateTask(values, request.Id, request.ProjectId)
 	if err != nil {
@@ -369,9 +390,8 @@ func (t *TaskService) AddTaskAssignee(request dto.TaskAddAssigneeDto, userId uin
 	if err != nil {
 		return err
 	}
-	isAssignee := t.projectMemberRepo.CheckAssignee(task.ProjectID, userId)
-	if task.CreatorID != userId && !isAssignee {
-		return errors.New("没有权限")
+	if err := t.policyService.AuthorizeTask(task, userId, constant.PERMISSION_EDIT_ANY); err != nil {
+		return err
 	}
 	createTasks := []models.TaskAssignee{}
 	for _, assignee := range request.Assignees {
@@ -396,9 +416,8 @@ func (t *TaskService) RemoveTaskAssignee(request dto.TaskRemoveAssigneeDto, user
 	if err != nil {
 		return err
 	}
-	isAssignee := t.projectMemberRepo.CheckAssignee(task.ProjectID, userId)
-	if task.CreatorID != userId && !isAssignee {
-		return errors.New("没有权限")
+	if err := t.policyService.AuthorizeTask(task, userId, constant.PERMISSION_EDIT_ANY); err != nil {
+		return err
 	}
 	err = t.taskAssigneeRepo.RemoveAssignee(request.Id, request.ProjectId, request.UserId)
 	if err != nil {
@@ -408,8 +427,8 @@ func (t *TaskService) RemoveTaskAssignee(request dto.TaskRemoveAssigneeDto, user
 }
 
 func (t *TaskService) GetTaskInfo(request dto.TaskGetInfoDto, userId uint) (*dto.TaskWithMemberResponse, error) {
-	if !t.projectMemberRepo.CheckProjectMemberExist(request.ProjectId, userId) {
-		return nil, errors.New("没有权限")
+	if err := t.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
 	}
 	task, err := t.taskRepo.GetTaskByIdAndProjectId(request.TaskId, request.ProjectId)
 	if err != nil {
@@ -465,8 +484,8 @@ func (t *TaskService) SearchTask(request dto.TaskSearchDto, userId uint) ([]dto.
 		return taskResponses, nil
 	}
 	projectId := *request.ProjectId
-	if !t.projectMemberRepo.CheckProjectMemberExist(projectId, userId) {
-		return nil, errors.New("没有权限")
+	if err := t.policyService.Authorize(projectId, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
 	}
 
 	query := make(map[string]any)
@@ -570,3 +589,132 @@ func (t *TaskService) SearchTask(request dto.TaskSearchDto, userId uint) ([]dto.
 	}
 	return data, err
 }
+
+func (t *TaskService) GetProjectTimeline(request dto.TasksDTO, userId uint) ([]dto.TaskTimelineResponse, error) {
+	if err := t.policyService.Authorize(request.Id, userId, constant.PERMISSION_VIEW); err != nil {
+		return nil, err
+	}
+	tasks, err := t.taskRepo.GetTaskByProjectId(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	taskAssignees, err := t.taskAssigneeRepo.GetTaskAssigneesByProjectId(request.Id)
+	if err != nil {
+		return nil, err
+	}
+	dependencies, err := t.dependencyRepo.GetDependenciesByProjectId(request.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	assigneeMap := make(map[uint][]models.Member)
+	for _, assignee := range *taskAssignees {
+		assigneeMap[assignee.TaskID] = append(assigneeMap[assignee.TaskID], models.Member{
+			UserID:   assignee.UserID,
+			Username: assignee.Username,
+		})
+	}
+	dependencyMap := make(map[uint][]uint)
+	for _, dependency := range *dependencies {
+		dependencyMap[dependency.TaskID] = append(dependencyMap[dependency.TaskID], dependency.DependsOnID)
+	}
+
+	data := []dto.TaskTimelineResponse{}
+	for _, task := range *tasks {
+		taskDependencies := dependencyMap[task.ID]
+		if taskDependencies == nil {
+			taskDependencies = []uint{}
+		}
+		assignees := assigneeMap[task.ID]
+		if assignees == nil {
+			assignees = []models.Member{}
+		}
+		var timelineResponse dto.TaskTimelineResponse
+		data = append(data, *timelineResponse.Set(&task, taskDependencies, assignees))
+	}
+	return data, nil
+}
+
+func (t *TaskService) RescheduleTask(request dto.TaskRescheduleDto, userId uint) error {
+	task, err := t.taskRepo.GetTaskByIdAndProjectId(request.Id, request.ProjectId)
+	if err != nil {
+		return err
+	}
+	if err := t.policyService.AuthorizeTask(task, userId, constant.PERMISSION_EDIT_ANY); err != nil {
+		return err
+	}
+	startDate := time.UnixMilli(request.StartDate)
+	dueDate := time.UnixMilli(request.DueDate)
+	if startDate.After(dueDate) {
+		return errors.New("开始时间不能晚于截止时间")
+	}
+	return t.taskRepo.RescheduleTask(request.Id, request.ProjectId, startDate, dueDate)
+}
+
+func (t *TaskService) AddTaskDependency(request dto.TaskDependencyDto, userId uint) error {
+	task, err := t.taskRepo.GetTaskByIdAndProjectId(request.Id, request.ProjectId)
+	if err != nil {
+		return err
+	}
+	if err := t.policyService.AuthorizeTask(task, userId, constant.PERMISSION_EDIT_ANY); err != nil {
+		return err
+	}
+	if request.Id == request.DependsOnId {
+		return errors.New("任务不能依赖自身")
+	}
+	if _, err := t.taskRepo.GetTaskByIdAndProjectId(request.DependsOnId, request.ProjectId); err != nil {
+		return errors.New("依赖的任务不存在")
+	}
+	if t.dependencyRepo.CheckDependencyExist(request.Id, request.DependsOnId) {
+		return nil
+	}
+
+	dependencies, err := t.dependencyRepo.GetDependenciesByProjectId(request.ProjectId)
+	if err != nil {
+		return err
+	}
+	if hasDependencyPath(*dependencies, request.DependsOnId, request.Id) {
+		return errors.New("任务依赖不能形成循环")
+	}
+
+	return t.dependencyRepo.CreateDependency(models.TaskDependency{
+		ProjectID:   request.ProjectId,
+		TaskID:      request.Id,
+		DependsOnID: request.DependsOnId,
+	})
+}
+
+func (t *TaskService) RemoveTaskDependency(request dto.TaskDependencyDto, userId uint) error {
+	task, err := t.taskRepo.GetTaskByIdAndProjectId(request.Id, request.ProjectId)
+	if err != nil {
+		return err
+	}
+	if err := t.policyService.AuthorizeTask(task, userId, constant.PERMISSION_EDIT_ANY); err != nil {
+		return err
+	}
+	return t.dependencyRepo.RemoveDependency(request.Id, request.DependsOnId)
+}
+
+// hasDependencyPath 判断 from 是否（间接）依赖 to
+func hasDependencyPath(dependencies []models.TaskDependency, from uint, to uint) bool {
+	graph := make(map[uint][]uint)
+	for _, dependency := range dependencies {
+		graph[dependency.TaskID] = append(graph[dependency.TaskID], dependency.DependsOnID)
+	}
+
+	visited := make(map[uint]bool)
+	stack := []uint{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == to {
+			return true
+		}
+		if visited[current] {
+			continue
+		}
+		visited[current] = true
+		stack = append(stack, graph[current]...)
+	}
+	return false
+}
@@ -2,14 +2,16 @@ package services
 
 import (
 	"errors"
-	"strconv"
 	"time"
 
 	"server/internal/app/kanboard/dto"
+	"server/internal/auth"
 	"server/internal/constant"
 	"server/internal/global"
 	"server/internal/models"
 	"server/internal/repositories"
+	"server/internal/session"
+	"server/internal/twofactor"
 	"server/pkg/crypto"
 )
 
@@ -20,6 +22,8 @@ type UserService struct {
 	projectRepo       *repositories.ProjectRepo
 	taskRepo          *repositories.TaskRepo
 	taskAssigneeRepo  *repositories.TaskAssigneeRepo
+
+	verificationService *VerificationService
 }
 
 var userService *UserService
@@ -33,6 +37,8 @@ func NewUserService() *UserService {
 			projectRepo:       repositories.NewProjectRepo(),
 			taskRepo:          repositories.NewTaskRepo(),
 			taskAssigneeRepo:  repositories.NewTaskAssigneeRepo(),
+
+			verificationService: NewVerificationService(),
 		}
 	}
 	return userService
@@ -61,39 +67,84 @@ func (u *UserService) checkUserInfoExist(username string, email, mobile *string)
 	return false, nil
 }
 
-func GenerateToken(id uint, username string) (string, error) {
-	token, err := crypto.GenerateJWTToAdmin(id, username)
-	global.Redis.Set(constant.KANBOARD_TOKEN, strconv.Itoa(int(id)), token, constant.JWTConfig.KanboardTokenExpiration)
-	return token, err
+// Login 开启两步验证的用户只返回 challenge，由 LoginTwoFactor 完成登录
+func (u *UserService) Login(request dto.UserLoginRequest, client session.Client) (*dto.LoginResponse, error) {
+	user, err := auth.Authenticate(request.Username, request.Password, client.IP)
+	if err != nil {
+		return nil, err
+	}
+
+	// 在两步验证前提示，completeLogin 中会再次校验
+	if err := checkLoginVerified(user); err != nil {
+		return nil, err
+	}
+
+	challenge, err := twofactor.LoginChallenge(user, constant.KANBOARD_TOKEN, client)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &dto.LoginResponse{
+			TwoFactorRequired: true,
+			SetupRequired:     challenge.SetupRequired,
+			Challenge:         challenge.Token,
+			ChallengeExpires:  challenge.ExpiresIn,
+		}, nil
+	}
+
+	return u.completeLogin(user, client)
 }
 
-func (u *UserService) Login(request dto.UserLoginRequest) (*dto.UserResponse, *string, error) {
-	user, err := u.userRepo.GetUserByName(request.Username)
-	comp := crypto.CheckPasswordHash(user.Password, request.Password)
+// LoginTwoFactorSetup 登录过程中尚未绑定两步验证的用户获取密钥
+func (u *UserService) LoginTwoFactorSetup(request dto.TwoFactorChallengeRequest) (*twofactor.Setup, error) {
+	return twofactor.SetupChallenge(constant.KANBOARD_TOKEN, request.Challenge)
+}
 
-	if !comp {
-		return nil, nil, errors.New("用户名或密码错误")
+func (u *UserService) LoginTwoFactor(request dto.TwoFactorLoginRequest) (*dto.LoginResponse, error) {
+	user, client, recoveryCodes, err := twofactor.CompleteChallenge(constant.KANBOARD_TOKEN, request.Challenge, request.Code)
+	if err != nil {
+		return nil, err
+	}
+	response, err := u.completeLogin(user, client)
+	if err != nil {
+		return nil, err
+	}
+	response.RecoveryCodes = recoveryCodes
+	return response, nil
+}
+
+// checkLoginVerified 开启登录前验证时，邮箱未验证的用户不能登录
+func checkLoginVerified(user *models.User) error {
+	if constant.MailConfig.RequireVerification == constant.EMAIL_VERIFICATION_LOGIN && !user.EmailVerified() {
+		return errors.New("邮箱未验证，请先完成邮箱验证")
 	}
+	return nil
+}
 
-	token, err := GenerateToken(user.ID, user.Username)
+// completeLogin 密码、两步验证与单点登录最终都经过此处签发令牌
+func (u *UserService) completeLogin(user *models.User, client session.Client) (*dto.LoginResponse, error) {
+	if err := checkLoginVerified(user); err != nil {
+		return nil, err
+	}
+	tokens, err := session.Issue(constant.KANBOARD_TOKEN, user, client)
 	if err != nil {
-		return nil, nil, errors.New("token生成失败")
+		return nil, err
 	}
 
 	var userResponse *dto.UserResponse
 	resource, err := u.resourceRepo.GetResourceById(user.Avatar)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	projectMembers, err := u.projectMemberRepo.GetProjectByUserId(user.ID)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	projects := []dto.ProjectsWithIdAndAssignee{}
 	for _, projectMember := range projectMembers {
 		projectModel, err := u.projectRepo.GetProjectById(projectMember.ProjectID)
 		if err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 		project := dto.ProjectsWithIdAndAssignee{
 			ProjectID:   projectMember.ProjectID,
@@ -104,7 +155,12 @@ func (u *UserService) Login(request dto.UserLoginRequest) (*dto.UserResponse, *s
 		projects = append(projects, project)
 	}
 
-	return userResponse.Set(user, resource, projects, nil), &token, nil
+	return &dto.LoginResponse{
+		Token:        tokens.Token,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         userResponse.Set(user, resource, projects, nil),
+	}, nil
 }
 
 func (u *UserService) RegisterKanboard(request dto.UserRegisterRequest) (*dto.UserResponse, error) {
@@ -112,6 +168,9 @@ func (u *UserService) RegisterKanboard(request dto.UserRegisterRequest) (*dto.Us
 	if isExist {
 		return nil, err
 	}
+	if constant.MailConfig.RequireVerification != "" && (request.Email == nil || *request.Email == "") {
+		return nil, errors.New("请填写邮箱")
+	}
 
 	var createUser models.User
 
@@ -128,6 +187,12 @@ func (u *UserService) RegisterKanboard(request dto.UserRegisterRequest) (*dto.Us
 	createUser.Loginable = true
 
 	user, err := u.userRepo.CreateUser(createUser)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.verificationService.SendVerification(user); err != nil {
+		global.Logger.Errorw("send verification error", "error", err)
+	}
 
 	var userResponse *dto.UserResponse
 	resource, err := u.resourceRepo.GetResourceById(user.Avatar)
@@ -190,6 +255,8 @@ func (u *UserService) UpdateInfo(request dto.UserUpdateRequest) (*dto.UserRespon
 			return nil, errors.New("邮箱已存在")
 		}
 		updateData["email"] = request.Email
+		// 更换邮箱后需要重新验证
+		updateData["email_verified_at"] = nil
 	}
 
 	if request.Mobile != nil {
@@ -208,6 +275,11 @@ func (u *UserService) UpdateInfo(request dto.UserUpdateRequest) (*dto.UserRespon
 	if err != nil {
 		return nil, err
 	}
+	if request.Email != nil {
+		if err := u.verificationService.SendVerification(user); err != nil {
+			global.Logger.Errorw("send verification error", "error", err)
+		}
+	}
 
 	var userResponse *dto.UserResponse
 	resource, err := u.resourceRepo.GetResourceById(user.Avatar)
@@ -249,88 +321,61 @@ func (u *UserService) UpdatePassword(request dto.UserUpdatePasswordRequest) erro
 	return err
 }
 
-func (u *UserService) GetStatistics(userId uint) (*dto.UserStatsResponse, error) {
-	totalTasks, err := u.taskAssigneeRepo.GetTaskCountByUserId(userId)
+func (u *UserService) GetStatistics(userId uint, request dto.UserStatsRequest) (*dto.UserStatsResponse, error) {
+	weekStart := constant.StatisticsConfig.WeekStart
+	if request.WeekStart != nil {
+		if *request.WeekStart < 0 || *request.WeekStart > 6 {
+			return nil, errors.New("week_start 取值范围为 0-6")
+		}
+		weekStart = time.Weekday(*request.WeekStart)
+	}
+	rangeStart, rangeEnd, err := parseChartRange(request.From, request.To)
 	if err != nil {
 		return nil, err
 	}
-	projects, err := u.projectMemberRepo.GetProjectByUserId(userId)
+
+	now := time.Now()
+	thisWeekStart := getWeekStart(now, weekStart)
+	thisMonthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	lastWeek := repositories.TimeRange{Start: thisWeekStart.AddDate(0, 0, -7), End: thisWeekStart}
+	thisWeek := repositories.TimeRange{Start: thisWeekStart, End: thisWeekStart.AddDate(0, 0, 7)}
+	lastMonth := repositories.TimeRange{Start: thisMonthStart.AddDate(0, -1, 0), End: thisMonthStart}
+	thisMonth := repositories.TimeRange{Start: thisMonthStart, End: thisMonthStart.AddDate(0, 1, 0)}
+	custom := repositories.TimeRange{Start: rangeStart, End: rangeEnd.AddDate(0, 0, 1)}
+
+	statistics, err := u.taskAssigneeRepo.GetUserTaskStatistics(userId, lastWeek, thisWeek, lastMonth, thisMonth, custom)
 	if err != nil {
 		return nil, err
 	}
-	var totalProjects int64 = int64(len(projects))
-
-	var doneTasks int64 = 0
-	var inProgressTasks int64 = 0
-	var lastWeekDoneTasks int64 = 0
-	var thisWeekDoneTasks int64 = 0
-	var lastMonthDoneTasks int64 = 0
-	var thisMonthDoneTasks int64 = 0
-	taskAssignees, err := u.taskAssigneeRepo.GetTaskByUserId(userId)
-	for _, taskAssignee := range *taskAssignees {
-		if u.taskRepo.CheckTaskDoneById(taskAssignee.TaskID) {
-			doneTasks++
-
-			task, err := u.taskRepo.GetTaskById(taskAssignee.TaskID)
-			if err != nil {
-				return nil, err
-			}
-
-			now := time.Now()
-			lastMonth := now.AddDate(0, -1, 0)
-			weekday := int(now.Weekday())
-			if weekday == 0 {
-				weekday = 7 // 周日为 0，转换为 7
-			}
-			thisWeekStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -weekday+1)
-			lastWeekStart := thisWeekStart.AddDate(0, 0, -7)
-			lastWeekEnd := thisWeekStart.AddDate(0, 0, -1).Add(time.Hour*23 + time.Minute*59 + time.Second*59)
-
-			if task.CreatedAt.Year() == now.Year() && task.CreatedAt.Month() == now.Month() {
-				thisMonthDoneTasks++
-			}
-			if task.CreatedAt.Year() == lastMonth.Year() && task.CreatedAt.Month() == lastMonth.Month() {
-				lastMonthDoneTasks++
-			}
-			if !task.CreatedAt.Before(lastWeekStart) && !task.CreatedAt.After(lastWeekEnd) {
-				lastWeekDoneTasks++
-			}
-			if !task.CreatedAt.Before(thisWeekStart) && !task.CreatedAt.After(now) {
-				thisWeekDoneTasks++
-			}
-			continue
-		}
-		if u.taskRepo.CheckTaskInProgressById(taskAssignee.TaskID) {
-			inProgressTasks++
-		}
+	totalProjects, err := u.projectMemberRepo.GetProjectCountByUserId(userId)
+	if err != nil {
+		return nil, err
 	}
 
 	userStatsResponse := &dto.UserStatsResponse{
-		TotalTasks:      totalTasks,
-		DoneTasks:       doneTasks,
-		InProgressTasks: inProgressTasks,
+		TotalTasks:      statistics.TotalTasks,
+		DoneTasks:       statistics.DoneTasks,
+		InProgressTasks: statistics.InProgressTasks,
 		Projects:        totalProjects,
-		LastWeekTasks:   lastWeekDoneTasks,
-		ThisWeekTasks:   thisWeekDoneTasks,
-		LastMonthTasks:  lastMonthDoneTasks,
-		ThisMonthTasks:  thisMonthDoneTasks,
+		LastWeekTasks:   statistics.LastWeekTasks,
+		ThisWeekTasks:   statistics.ThisWeekTasks,
+		LastMonthTasks:  statistics.LastMonthTasks,
+		ThisMonthTasks:  statistics.ThisMonthTasks,
+		RangeTasks:      statistics.RangeTasks,
+		From:            rangeStart.Format(time.DateOnly),
+		To:              rangeEnd.Format(time.DateOnly),
 	}
 	return userStatsResponse, nil
 }
 
 func (u *UserService) GetCalendar(userId uint) ([]dto.UserCalendarResponse, error) {
-	taskAssignees, err := u.taskAssigneeRepo.GetTaskByUserId(userId)
+	tasks, err := u.taskRepo.GetDueTasksByUserId(userId)
 	if err != nil {
 		return nil, err
 	}
 
 	responses := []dto.UserCalendarResponse{}
-	for _, taskAssignee := range *taskAssignees {
-		task, err := u.taskRepo.GetDueDateByTaskId(taskAssignee.TaskID)
-		if err != nil {
-			continue
-		}
-
+	for _, task := range *tasks {
 		response := dto.UserCalendarResponse{
 			Id:    task.ID,
 			Title: task.Title,
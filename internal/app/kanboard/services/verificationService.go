@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/crypto"
+)
+
+const (
+	EMAIL_VERIFY_EXPIRATION = 24 * time.Hour
+	// 同一用户两次发送验证邮件的最小间隔
+	EMAIL_VERIFY_INTERVAL   = time.Minute
+	EMAIL_VERIFY_TOKEN_SIZE = 32
+)
+
+type VerificationService struct {
+	userRepo    *repositories.UserRepo
+	mailService *MailService
+}
+
+var verificationService *VerificationService
+
+func NewVerificationService() *VerificationService {
+	if verificationService == nil {
+		verificationService = &VerificationService{
+			userRepo:    repositories.NewUserRepo(),
+			mailService: NewMailService(),
+		}
+	}
+	return verificationService
+}
+
+// SendVerification 发送验证邮件，验证链接与当前邮箱绑定，邮箱变更后旧链接失效
+func (v *VerificationService) SendVerification(user *models.User) error {
+	if user.Email == "" || user.EmailVerified() {
+		return nil
+	}
+	userId := strconv.Itoa(int(user.ID))
+	if !global.Redis.SetNX(constant.EMAIL_VERIFY_LIMIT, userId, 1, EMAIL_VERIFY_INTERVAL) {
+		return errors.New("发送过于频繁，请稍后再试")
+	}
+
+	token, err := crypto.GenerateRandomToken(EMAIL_VERIFY_TOKEN_SIZE)
+	if err != nil {
+		return errors.New("验证链接生成失败")
+	}
+	global.Redis.Set(constant.EMAIL_VERIFY_TOKEN, crypto.HashToken(token), userId+":"+user.Email, EMAIL_VERIFY_EXPIRATION)
+
+	link := strings.ReplaceAll(constant.MailConfig.VerifyLink, "{token}", token)
+	body := fmt.Sprintf("%s，您好：\n\n请在 %d 小时内打开以下链接完成邮箱验证：\n\n%s\n\n如果不是您本人操作，请忽略此邮件。\n",
+		user.Username, int(EMAIL_VERIFY_EXPIRATION.Hours()), link)
+	v.mailService.SendAsync(user.Email, "验证邮箱", body)
+	return nil
+}
+
+// ResendVerification 无论邮箱是否注册都返回成功，避免被用来探测邮箱
+func (v *VerificationService) ResendVerification(request dto.ResendVerificationRequest) error {
+	user, err := v.userRepo.GetUserByEmail(request.Email)
+	if err != nil || user.ID == 0 {
+		return nil
+	}
+	if err := v.SendVerification(user); err != nil {
+		global.Logger.Infow("resend verification skipped", "user", user.ID, "reason", err)
+	}
+	return nil
+}
+
+func (v *VerificationService) VerifyEmail(request dto.VerifyEmailRequest) error {
+	value := global.Redis.GetDel(constant.EMAIL_VERIFY_TOKEN, crypto.HashToken(request.Token))
+	userId, email, ok := strings.Cut(value, ":")
+	id, err := strconv.ParseUint(userId, 10, 0)
+	if !ok || err != nil {
+		return errors.New("验证链接无效或已过期")
+	}
+
+	user, err := v.userRepo.GetUserById(uint(id))
+	if err != nil || user.ID == 0 || user.Email != email {
+		return errors.New("验证链接无效或已过期")
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+	_, err = v.userRepo.UpdateUserById(map[string]any{"email_verified_at": time.Now()}, user.ID)
+	return err
+}
@@ -0,0 +1,450 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"server/internal/app/kanboard/dto"
+	"server/internal/constant"
+	"server/internal/event"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/crypto"
+	"server/pkg/webhook"
+)
+
+const (
+	WEBHOOK_TIMEOUT      = 10 * time.Second
+	WEBHOOK_MAX_ATTEMPTS = 6
+	// 第 n 次失败后等待 WEBHOOK_RETRY_BASE * 2^(n-1) 再重试
+	WEBHOOK_RETRY_BASE  = 30 * time.Second
+	WEBHOOK_LEASE       = time.Minute
+	WEBHOOK_SCAN_EVERY  = 15 * time.Second
+	WEBHOOK_SCAN_LIMIT  = 100
+	WEBHOOK_SECRET_SIZE = 20
+)
+
+var webhookEvents = map[constant.EventType]string{
+	constant.TASK_CREATED_EVENT:        constant.WEBHOOK_EVENT_TASK_CREATED,
+	constant.TASK_UPDATED_EVENT:        constant.WEBHOOK_EVENT_TASK_UPDATED,
+	constant.TASK_STATUS_CHANGED_EVENT: constant.WEBHOOK_EVENT_TASK_STATUS_CHANGED,
+	constant.TASK_DELETED_EVENT:        constant.WEBHOOK_EVENT_TASK_DELETED,
+	constant.TASK_OVERDUE_EVENT:        constant.WEBHOOK_EVENT_TASK_OVERDUE,
+	constant.PROJECT_EVENT:             constant.WEBHOOK_EVENT_PROJECT_MEMBER_CHANGED,
+}
+
+func init() {
+	event.KanboardSubscribe(func(event event.Event) {
+		if event.EventType == nil || event.ProjectID == nil {
+			return
+		}
+		name, ok := webhookEvents[*event.EventType]
+		if !ok {
+			return
+		}
+		NewWebhookService().HandleEvent(name, event)
+	})
+}
+
+type WebhookService struct {
+	webhookRepo       *repositories.WebhookRepo
+	taskRepo          *repositories.TaskRepo
+	taskAssigneeRepo  *repositories.TaskAssigneeRepo
+	projectRepo       *repositories.ProjectRepo
+	projectMemberRepo *repositories.ProjectMemberRepo
+	policyService     *PolicyService
+}
+
+var webhookService *WebhookService
+
+func NewWebhookService() *WebhookService {
+	if webhookService == nil {
+		webhookService = &WebhookService{
+			webhookRepo:       repositories.NewWebhookRepo(),
+			taskRepo:          repositories.NewTaskRepo(),
+			taskAssigneeRepo:  repositories.NewTaskAssigneeRepo(),
+			projectRepo:       repositories.NewProjectRepo(),
+			projectMemberRepo: repositories.NewProjectMemberRepo(),
+			policyService:     NewPolicyService(),
+		}
+	}
+	return webhookService
+}
+
+func (w *WebhookService) GetWebhooks(request dto.WebhooksDto, userId uint) ([]dto.WebhookResponse, error) {
+	if err := w.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return nil, err
+	}
+	webhooks, err := w.webhookRepo.GetWebhooksByProjectId(request.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []dto.WebhookResponse{}
+	for _, item := range *webhooks {
+		var response dto.WebhookResponse
+		responses = append(responses, *response.Set(&item, false))
+	}
+	return responses, nil
+}
+
+func (w *WebhookService) CreateWebhook(request dto.WebhookCreateDto, userId uint) (*dto.WebhookResponse, error) {
+	if err := w.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(request.URL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(request.Events)
+	if err != nil {
+		return nil, err
+	}
+	secret := request.Secret
+	if secret == "" {
+		secret, err = crypto.GenerateRandomToken(WEBHOOK_SECRET_SIZE)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	item := models.Webhook{
+		ProjectID: request.ProjectId,
+		URL:       request.URL,
+		Secret:    secret,
+		Events:    events,
+		Enabled:   true,
+		CreatorID: userId,
+	}
+	if request.Enabled != nil {
+		item.Enabled = *request.Enabled
+	}
+	created, err := w.webhookRepo.CreateWebhook(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var response dto.WebhookResponse
+	return response.Set(created, true), nil
+}
+
+func (w *WebhookService) UpdateWebhook(request dto.WebhookUpdateDto, userId uint) (*dto.WebhookResponse, error) {
+	if err := w.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return nil, err
+	}
+	values := make(map[string]any)
+	if request.URL != nil {
+		if err := validateWebhookURL(*request.URL); err != nil {
+			return nil, err
+		}
+		values["url"] = *request.URL
+	}
+	if request.Events != nil {
+		events, err := normalizeWebhookEvents(*request.Events)
+		if err != nil {
+			return nil, err
+		}
+		values["events"] = events
+	}
+	if request.Enabled != nil {
+		values["enabled"] = *request.Enabled
+	}
+	if request.RotateSecret {
+		secret, err := crypto.GenerateRandomToken(WEBHOOK_SECRET_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		values["secret"] = secret
+	}
+	if len(values) > 0 {
+		if err := w.webhookRepo.UpdateWebhook(values, request.Id, request.ProjectId); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := w.webhookRepo.GetWebhookByIdAndProjectId(request.Id, request.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if updated.ID == 0 {
+		return nil, errors.New("Webhook 不存在")
+	}
+	var response dto.WebhookResponse
+	return response.Set(updated, request.RotateSecret), nil
+}
+
+func (w *WebhookService) DeleteWebhook(request dto.WebhookIdDto, userId uint) error {
+	if err := w.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return err
+	}
+	return w.webhookRepo.DeleteWebhook(request.Id, request.ProjectId)
+}
+
+func (w *WebhookService) GetDeliveries(request dto.WebhookDeliveryDto, userId uint) (*dto.WebhookDeliveryPageResponse, error) {
+	if err := w.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return nil, err
+	}
+	item, err := w.webhookRepo.GetWebhookByIdAndProjectId(request.Id, request.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	if item.ID == 0 {
+		return nil, errors.New("Webhook 不存在")
+	}
+
+	total, err := w.webhookRepo.GetDeliveryCountByWebhookId(item.ID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries, err := w.webhookRepo.GetDeliveriesByWebhookId(item.ID, request.Page, request.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	data := []dto.WebhookDeliveryResponse{}
+	for _, delivery := range *deliveries {
+		var response dto.WebhookDeliveryResponse
+		data = append(data, *response.Set(&delivery))
+	}
+
+	var pageResponse dto.WebhookDeliveryPageResponse
+	return pageResponse.Set(total, request.Page, request.PageSize, data), nil
+}
+
+// Redeliver 以原请求体创建一条新的投递记录并立即发送
+func (w *WebhookService) Redeliver(request dto.WebhookIdDto, userId uint) (uint, error) {
+	if err := w.policyService.Authorize(request.ProjectId, userId, constant.PERMISSION_MANAGE_SETTINGS); err != nil {
+		return 0, err
+	}
+	delivery, err := w.webhookRepo.GetDeliveryById(request.Id)
+	if err != nil {
+		return 0, err
+	}
+	if delivery.ID == 0 || delivery.ProjectID != request.ProjectId {
+		return 0, errors.New("投递记录不存在")
+	}
+	item, err := w.webhookRepo.GetWebhookByIdAndProjectId(delivery.WebhookID, request.ProjectId)
+	if err != nil {
+		return 0, err
+	}
+	if item.ID == 0 {
+		return 0, errors.New("Webhook 不存在")
+	}
+
+	now := time.Now()
+	created, err := w.webhookRepo.CreateDelivery(models.WebhookDelivery{
+		WebhookID:   item.ID,
+		ProjectID:   item.ProjectID,
+		Event:       delivery.Event,
+		Payload:     delivery.Payload,
+		Status:      constant.WEBHOOK_DELIVERY_PENDING,
+		NextRetryAt: &now,
+	})
+	if err != nil {
+		return 0, err
+	}
+	go w.attemptDelivery(created.ID)
+	return created.ID, nil
+}
+
+func (w *WebhookService) HandleEvent(name string, e event.Event) {
+	projectId := *e.ProjectID
+	webhooks, err := w.webhookRepo.GetEnabledWebhooks(projectId)
+	if err != nil {
+		global.Logger.Errorw("get webhooks error", "error", err)
+		return
+	}
+	subscribers := []models.Webhook{}
+	for _, item := range *webhooks {
+		if item.Events == "" || slices.Contains(strings.Split(item.Events, ","), name) {
+			subscribers = append(subscribers, item)
+		}
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	payload, err := w.buildPayload(name, e)
+	if err != nil {
+		global.Logger.Errorw("build webhook payload error", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, item := range subscribers {
+		delivery, err := w.webhookRepo.CreateDelivery(models.WebhookDelivery{
+			WebhookID:   item.ID,
+			ProjectID:   projectId,
+			Event:       name,
+			Payload:     string(payload),
+			Status:      constant.WEBHOOK_DELIVERY_PENDING,
+			NextRetryAt: &now,
+		})
+		if err != nil {
+			global.Logger.Errorw("create webhook delivery error", "error", err)
+			continue
+		}
+		go w.attemptDelivery(delivery.ID)
+	}
+}
+
+func (w *WebhookService) buildPayload(name string, e event.Event) ([]byte, error) {
+	project, err := w.projectRepo.GetProjectById(*e.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	payload := dto.WebhookPayload{
+		Event:      name,
+		OccurredAt: time.Now(),
+		Project:    dto.WebhookProjectPayload{Id: project.ID, Name: project.Name},
+		ActorId:    e.ActorID,
+	}
+	if e.Content != nil {
+		payload.Content = *e.Content
+	}
+
+	if e.TaskID != nil {
+		task, err := w.taskRepo.GetTaskByIdWithDeleted(*e.TaskID)
+		if err != nil {
+			return nil, err
+		}
+		assignees := []models.Member{}
+		taskAssignees, err := w.taskAssigneeRepo.GetTaskAssigneesByTaskIds([]uint{task.ID})
+		if err != nil {
+			return nil, err
+		}
+		for _, assignee := range *taskAssignees {
+			assignees = append(assignees, models.Member{UserID: assignee.UserID, Username: assignee.Username})
+		}
+		var taskPayload dto.WebhookTaskPayload
+		payload.Task = taskPayload.Set(task, assignees)
+	} else {
+		members, err := w.projectMemberRepo.GetMemberListByProjectId(project.ID)
+		if err != nil {
+			return nil, err
+		}
+		payload.Members = *members
+	}
+
+	return json.Marshal(payload)
+}
+
+func (w *WebhookService) attemptDelivery(id uint) {
+	now := time.Now()
+	if !w.webhookRepo.ClaimDelivery(id, now, now.Add(WEBHOOK_LEASE)) {
+		return
+	}
+	delivery, err := w.webhookRepo.GetDeliveryById(id)
+	if err != nil || delivery.ID == 0 {
+		return
+	}
+	attempts := delivery.Attempts + 1
+	values := map[string]any{
+		"attempts":      attempts,
+		"response_code": 0,
+		"response_body": "",
+		"error":         "",
+	}
+
+	item, err := w.webhookRepo.GetWebhookById(delivery.WebhookID)
+	if err != nil || item.ID == 0 {
+		values["status"] = constant.WEBHOOK_DELIVERY_FAILED
+		values["next_retry_at"] = nil
+		values["error"] = "Webhook 不存在"
+		w.saveDelivery(values, id)
+		return
+	}
+
+	result, err := webhook.Send(item.URL, delivery.Event, strconv.Itoa(int(delivery.ID)), item.Secret, []byte(delivery.Payload), webhook.Options{
+		Timeout:             WEBHOOK_TIMEOUT,
+		AllowPrivateNetwork: constant.WebhookConfig.AllowPrivateNetwork,
+	})
+	if err != nil {
+		values["error"] = err.Error()
+	} else {
+		values["response_code"] = result.StatusCode
+		values["response_body"] = result.Body
+	}
+
+	switch {
+	case err == nil && result.StatusCode >= 200 && result.StatusCode < 300:
+		deliveredAt := time.Now()
+		values["status"] = constant.WEBHOOK_DELIVERY_SUCCESS
+		values["next_retry_at"] = nil
+		values["delivered_at"] = deliveredAt
+	case attempts >= WEBHOOK_MAX_ATTEMPTS:
+		values["status"] = constant.WEBHOOK_DELIVERY_FAILED
+		values["next_retry_at"] = nil
+	default:
+		values["status"] = constant.WEBHOOK_DELIVERY_PENDING
+		values["next_retry_at"] = time.Now().Add(WEBHOOK_RETRY_BASE << (attempts - 1))
+	}
+	w.saveDelivery(values, id)
+}
+
+func (w *WebhookService) saveDelivery(values map[string]any, id uint) {
+	if err := w.webhookRepo.UpdateDelivery(values, id); err != nil {
+		global.Logger.Errorw("update webhook delivery error", "error", err)
+	}
+}
+
+// StartWebhookScheduler 定时重试到期的投递
+func StartWebhookScheduler() {
+	go func() {
+		ticker := time.NewTicker(WEBHOOK_SCAN_EVERY)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			ids, err := NewWebhookService().webhookRepo.GetDueDeliveryIds(now, WEBHOOK_SCAN_LIMIT)
+			if err != nil {
+				global.Logger.Errorw("get due webhook deliveries error", "error", err)
+				continue
+			}
+			for _, id := range ids {
+				go NewWebhookService().attemptDelivery(id)
+			}
+		}
+	}()
+}
+
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("Webhook 地址格式错误")
+	}
+	if constant.WebhookConfig.AllowPrivateNetwork {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), WEBHOOK_TIMEOUT)
+	defer cancel()
+	if err := webhook.CheckHost(ctx, parsed.Hostname()); err != nil {
+		if errors.Is(err, webhook.ErrForbiddenAddress) {
+			return errors.New("Webhook 地址不能指向本机或内网")
+		}
+		return errors.New("Webhook 地址无法解析")
+	}
+	return nil
+}
+
+func normalizeWebhookEvents(events []string) (string, error) {
+	valid := []string{}
+	for _, name := range webhookEvents {
+		valid = append(valid, name)
+	}
+	result := []string{}
+	for _, name := range events {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(result, name) {
+			continue
+		}
+		if !slices.Contains(valid, name) {
+			return "", errors.New("不支持的事件类型『" + name + "』")
+		}
+		result = append(result, name)
+	}
+	return strings.Join(result, ","), nil
+}
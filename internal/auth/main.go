@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/lockout"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/session"
+	"server/pkg/crypto"
+	"server/pkg/ldapauth"
+
+	"gorm.io/gorm"
+)
+
+// 目录用户不使用本地密码，创建时设置为随机值
+const LDAP_PASSWORD_SIZE = 32
+
+var ErrInvalidCredentials = errors.New("用户名或密码错误")
+
+// Authenticate 校验用户名与密码，开启 LDAP 时优先使用目录认证，首次登录的目录用户会被创建
+// 密码错误按用户名与 IP 计数，次数过多时暂时拒绝登录；被禁用的账号密码正确也不能登录
+func Authenticate(username string, password string, ip string) (*models.User, error) {
+	if err := lockout.Check(username, ip); err != nil {
+		return nil, err
+	}
+	user, err := authenticate(username, password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			lockout.Fail(username, ip)
+		}
+		return nil, err
+	}
+	lockout.Succeed(username)
+	if err := session.CheckLoginable(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func authenticate(username string, password string) (*models.User, error) {
+	config := constant.LDAPConfig
+	if !config.Enabled {
+		return authenticateLocal(username, password)
+	}
+
+	entry, err := ldapauth.Authenticate(ldapConfig(), username, password)
+	if err == nil {
+		return provision(entry)
+	}
+	if !errors.Is(err, ldapauth.ErrInvalidCredentials) && !errors.Is(err, ldapauth.ErrUserNotFound) {
+		global.Logger.Errorw("ldap authenticate error", "error", err)
+	}
+	if !config.LocalFallback {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := authenticateLocal(username, password)
+	if err != nil {
+		return nil, err
+	}
+	// 已关联目录的用户在目录中被禁用或删除后不能再使用本地密码登录
+	if linked, err := repositories.NewUserIdentityRepo().HasIdentity(user.ID, constant.LDAP_PROVIDER); err != nil || linked {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// authenticateLocal 被禁用的用户同样校验密码，由 Authenticate 返回禁用原因
+func authenticateLocal(username string, password string) (*models.User, error) {
+	user, err := repositories.NewUserRepo().GetAuthUserByName(username)
+	if err != nil || user.ID == 0 {
+		return nil, ErrInvalidCredentials
+	}
+	if !crypto.CheckPasswordHash(user.Password, password) {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+func ldapConfig() ldapauth.Config {
+	config := constant.LDAPConfig
+	return ldapauth.Config{
+		URL:                config.URL,
+		StartTLS:           config.StartTLS,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+		Timeout:            config.Timeout,
+		BindDN:             config.BindDN,
+		BindPassword:       config.BindPassword,
+		BaseDN:             config.BaseDN,
+		UserFilter:         config.UserFilter,
+		UsernameAttribute:  config.UsernameAttribute,
+		EmailAttribute:     config.EmailAttribute,
+		AdminGroupDN:       config.AdminGroupDN,
+	}
+}
+
+// provision 按关联记录找到本地用户，不存在时创建，并同步管理员标识
+func provision(entry *ldapauth.Entry) (*models.User, error) {
+	userRepo := repositories.NewUserRepo()
+	userIdentityRepo := repositories.NewUserIdentityRepo()
+	subject := strings.ToLower(entry.Username)
+
+	identity, err := userIdentityRepo.GetIdentity(constant.LDAP_PROVIDER, subject)
+	if err != nil {
+		return nil, err
+	}
+	var user *models.User
+	if identity.ID != 0 {
+		user, err = userRepo.GetUserById(identity.UserID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		// 本地用户已被删除，重新创建
+		if err != nil {
+			if err := userIdentityRepo.DeleteIdentityById(identity.ID); err != nil {
+				return nil, err
+			}
+			identity.ID = 0
+		}
+	}
+
+	if identity.ID == 0 {
+		user, err = findOrCreate(entry)
+		if err != nil {
+			return nil, err
+		}
+		_, err = userIdentityRepo.CreateIdentity(models.UserIdentity{
+			UserID:   user.ID,
+			Provider: constant.LDAP_PROVIDER,
+			Subject:  subject,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if constant.LDAPConfig.AdminGroupDN == "" || entry.IsAdmin == user.IsAdmin {
+		return user, nil
+	}
+	user, err = userRepo.UpdateUserById(map[string]any{"is_admin": entry.IsAdmin}, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 移出管理员分组后注销管理端会话
+	if !entry.IsAdmin {
+		session.RevokeAll(user.ID, constant.ADMIN_TOKEN, "")
+	}
+	return user, nil
+}
+
+func findOrCreate(entry *ldapauth.Entry) (*models.User, error) {
+	userRepo := repositories.NewUserRepo()
+	if userRepo.CheckUserExistByName(entry.Username) {
+		if !constant.LDAPConfig.LinkExisting {
+			return nil, errors.New("用户名已存在")
+		}
+		// 被禁用的本地用户同样关联，登录时再拒绝
+		user, err := userRepo.GetAuthUserByName(entry.Username)
+		if err != nil {
+			return nil, err
+		}
+		if user.ID == 0 {
+			return nil, errors.New("用户不存在")
+		}
+		return user, nil
+	}
+
+	password, err := crypto.GenerateRandomToken(LDAP_PASSWORD_SIZE)
+	if err != nil {
+		return nil, errors.New("用户创建失败")
+	}
+	createUser := models.User{
+		Username:   entry.Username,
+		Password:   password,
+		CreateFrom: constant.LDAP,
+		Loginable:  true,
+	}
+	// 目录中的邮箱由管理员维护，视为已验证；已被其他用户使用时不设置
+	if entry.Email != "" && !userRepo.CheckEmailExist(entry.Email) {
+		now := time.Now()
+		createUser.Email = entry.Email
+		createUser.EmailVerifiedAt = &now
+	}
+	return userRepo.CreateUser(createUser)
+}
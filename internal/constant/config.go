@@ -16,4 +16,20 @@ var (
 	JWTConfig = new(types.JWT)
 
 	FileConfig = new(types.File)
+
+	StatisticsConfig = new(types.Statistics)
+
+	CalendarConfig = new(types.Calendar)
+
+	MailConfig = new(types.Mail)
+
+	TwoFactorConfig = new(types.TwoFactor)
+
+	OIDCConfig = new(types.OIDC)
+
+	LDAPConfig = new(types.LDAP)
+
+	LockoutConfig = new(types.Lockout)
+
+	WebhookConfig = new(types.Webhook)
 )
@@ -5,8 +5,15 @@ type From int
 const (
 	ADMIN From = iota
 	KANBOARD
+	// 通过 OIDC 单点登录首次登录时创建
+	OIDC
+	// 通过 LDAP 认证首次登录时创建
+	LDAP
 )
 
+// 外部账号关联中 LDAP 目录的标识
+const LDAP_PROVIDER = "ldap"
+
 type Gender uint
 
 const (
@@ -29,12 +36,58 @@ const (
 	KANBOARD_SUBJECT = "KANBOARD"
 )
 
+const (
+	ADMIN_AUDIENCE    = "admin"
+	KANBOARD_AUDIENCE = "kanboard"
+)
+
+// 邮箱验证要求，未验证的用户不能登录或不能加入项目
+const (
+	EMAIL_VERIFICATION_LOGIN   = "login"
+	EMAIL_VERIFICATION_PROJECT = "project"
+)
+
+// 登录锁定的对象
+const (
+	LOCKOUT_TYPE_USER = "user"
+	LOCKOUT_TYPE_IP   = "ip"
+)
+
+// 个人访问令牌以该前缀开头，鉴权时据此与登录令牌区分
+const ACCESS_TOKEN_PREFIX = "kbp_"
+
+// 个人访问令牌的权限范围，各接口所需的权限见 accesstoken 包
+const (
+	SCOPE_TASKS_READ     = "tasks:read"
+	SCOPE_TASKS_WRITE    = "tasks:write"
+	SCOPE_PROJECTS_READ  = "projects:read"
+	SCOPE_MESSAGES_READ  = "messages:read"
+	SCOPE_MESSAGES_WRITE = "messages:write"
+)
+
 const (
 	TASK_STATUS_UNDO = iota
 	TASK_STATUS_IN_PROGRESS
 	TASK_STATUS_DONE
 )
 
+const (
+	PROJECT_ROLE_OWNER      = "owner"
+	PROJECT_ROLE_MAINTAINER = "maintainer"
+	PROJECT_ROLE_MEMBER     = "member"
+	PROJECT_ROLE_VIEWER     = "viewer"
+)
+
+// 项目内的操作权限，各角色具备的权限见 policy 包
+const (
+	PERMISSION_VIEW            = "view"
+	PERMISSION_CREATE          = "create"
+	PERMISSION_EDIT_ANY        = "edit_any"
+	PERMISSION_DELETE          = "delete"
+	PERMISSION_MANAGE_MEMBERS  = "manage_members"
+	PERMISSION_MANAGE_SETTINGS = "manage_settings"
+)
+
 const (
 	TASK_PRIORITY_LOW = iota - 1
 	TASK_PRIORITY_MEDIUM
@@ -52,6 +105,13 @@ const (
 	UNREAD_MESSAGE  = "unread_message"
 	PUBLISH_MESSAGE = "publish_message"
 	UPDATE_USER     = "update_user"
+	// 通知 WebSocket 连接检查所属会话或令牌是否已被注销
+	CLOSE_CONNECTION = "close_connection"
+)
+
+const (
+	EXPORT_FORMAT_CSV   = "csv"
+	EXPORT_FORMAT_JSONL = "jsonl"
 )
 
 type EventType string
@@ -60,4 +120,63 @@ const (
 	PROJECT_EVENT     EventType = "project_event"
 	TASK_EVENT        EventType = "task_event"
 	UPDATE_TASK_EVENT EventType = "update_task_event"
+
+	TASK_CREATED_EVENT        EventType = "task_created_event"
+	TASK_STATUS_CHANGED_EVENT EventType = "task_status_changed_event"
+	TASK_OVERDUE_EVENT        EventType = "task_overdue_event"
+	TASK_UPDATED_EVENT        EventType = "task_updated_event"
+	TASK_DELETED_EVENT        EventType = "task_deleted_event"
+)
+
+const (
+	WEBHOOK_EVENT_TASK_CREATED           = "task.created"
+	WEBHOOK_EVENT_TASK_UPDATED           = "task.updated"
+	WEBHOOK_EVENT_TASK_STATUS_CHANGED    = "task.status_changed"
+	WEBHOOK_EVENT_TASK_DELETED           = "task.deleted"
+	WEBHOOK_EVENT_TASK_OVERDUE           = "task.overdue"
+	WEBHOOK_EVENT_PROJECT_MEMBER_CHANGED = "project.member_changed"
+)
+
+const (
+	WEBHOOK_DELIVERY_PENDING = "pending"
+	WEBHOOK_DELIVERY_SUCCESS = "success"
+	WEBHOOK_DELIVERY_FAILED  = "failed"
+)
+
+const (
+	AUTOMATION_TRIGGER_TASK_CREATED        = "task_created"
+	AUTOMATION_TRIGGER_TASK_STATUS_CHANGED = "task_status_changed"
+	AUTOMATION_TRIGGER_TASK_OVERDUE        = "task_overdue"
+)
+
+const (
+	AUTOMATION_ACTION_ASSIGN_USER  = "assign_user"
+	AUTOMATION_ACTION_SET_PRIORITY = "set_priority"
+	AUTOMATION_ACTION_SET_STATUS   = "set_status"
+	AUTOMATION_ACTION_SEND_MESSAGE = "send_message"
+)
+
+const (
+	AUTOMATION_RESULT_SUCCESS = "success"
+	AUTOMATION_RESULT_FAILED  = "failed"
+	AUTOMATION_RESULT_SKIPPED = "skipped"
+)
+
+const (
+	AUTOMATION_RECIPIENT_ASSIGNEES = "assignees"
+	AUTOMATION_RECIPIENT_MEMBERS   = "members"
+	AUTOMATION_RECIPIENT_CREATOR   = "creator"
+)
+
+// 自动化规则执行的操作以该用户 ID 记录
+const AUTOMATION_ACTOR_ID = 0
+
+const (
+	AUDIT_ACTION_ON_BEHALF           = "on_behalf"
+	AUDIT_ACTION_REVOKE_SESSION      = "revoke_session"
+	AUDIT_ACTION_REVOKE_TOKEN        = "revoke_token"
+	AUDIT_ACTION_REVOKE_ACCESS_TOKEN = "revoke_access_token"
+	AUDIT_ACTION_CLEAR_LOCKOUT       = "clear_lockout"
+	AUDIT_ACTION_LOCK_USER           = "lock_user"
+	AUDIT_ACTION_UNLOCK_USER         = "unlock_user"
 )
@@ -15,4 +15,30 @@ const (
 
 	ADMIN_MESSAGE_UNREADED = "admin_message_unreaded"
 	ADMIN_MESSAGE_READED   = "admin_message_readed"
+
+	AUTOMATION_CHAIN   = "automation_chain"
+	AUTOMATION_OVERDUE = "automation_overdue"
+
+	SESSION_LAST_SEEN = "session_last_seen"
+	REVOKED_TOKEN     = "revoked_token"
+	USER_LOGINABLE    = "user_loginable"
+
+	PASSWORD_RESET_TOKEN = "password_reset_token"
+	PASSWORD_RESET_USER  = "password_reset_user"
+	PASSWORD_RESET_LIMIT = "password_reset_limit"
+
+	EMAIL_VERIFY_TOKEN = "email_verify_token"
+	EMAIL_VERIFY_LIMIT = "email_verify_limit"
+
+	TWO_FACTOR_CHALLENGE = "two_factor_challenge"
+	TWO_FACTOR_ATTEMPTS  = "two_factor_attempts"
+	TWO_FACTOR_FAILURE   = "two_factor_failure"
+
+	OIDC_STATE = "oidc_state"
+
+	LOGIN_FAILURE_USER = "login_failure_user"
+	LOGIN_FAILURE_IP   = "login_failure_ip"
+	LOGIN_DELAY        = "login_delay"
+	LOGIN_LOCKED_USER  = "login_locked_user"
+	LOGIN_LOCKED_IP    = "login_locked_ip"
 )
@@ -11,6 +11,9 @@ type Event struct {
 	TaskID    *uint
 	UserID    *uint
 	Content   *string
+	// 触发事件的用户，自动化规则据此识别由规则自身引起的事件
+	ActorID *uint
+	Status  *uint
 }
 
 type (
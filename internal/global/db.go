@@ -39,18 +39,66 @@ func getDB() (*gorm.DB, error) {
 }
 
 func autoMigrate(db *gorm.DB) {
+	// 邮箱验证字段新增前创建的用户需要补齐验证时间，之后的用户由创建逻辑处理
+	backfillEmailVerified := !db.Migrator().HasColumn(&models.User{}, "email_verified_at")
+
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.Task{},
 		&models.Project{},
 		&models.ProjectMember{},
 		&models.TaskAssignee{},
+		&models.TaskDependency{},
+		&models.TaskTransition{},
+		&models.CalendarFeed{},
+		&models.AutomationRule{},
+		&models.AutomationLog{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.GitIntegration{},
+		&models.TaskCommit{},
+		&models.AuditLog{},
+		&models.Session{},
+		&models.TwoFactor{},
+		&models.RecoveryCode{},
+		&models.UserIdentity{},
+		&models.AccessToken{},
 		&models.Resource{},
 	)
 	if err != nil {
 		Logger.Error(err)
 		panic(err)
 	}
+
+	// 为已完成但缺少完成时间的历史任务补齐 completed_at
+	err = db.Model(&models.Task{}).
+		Where("status = ? AND completed_at IS NULL", constant.TASK_STATUS_DONE).
+		UpdateColumn("completed_at", gorm.Expr("updated_at")).Error
+	if err != nil {
+		Logger.Error(err)
+		panic(err)
+	}
+
+	// 管理员创建的用户视为邮箱已验证，只在新增该字段时执行一次，
+	// 否则用户修改邮箱后被清空的验证时间会在重启时再次被补上
+	if backfillEmailVerified {
+		err = db.Model(&models.User{}).
+			Where("create_from = ? AND email IS NOT NULL AND email <> '' AND email_verified_at IS NULL", constant.ADMIN).
+			UpdateColumn("email_verified_at", gorm.Expr("created_at")).Error
+		if err != nil {
+			Logger.Error(err)
+			panic(err)
+		}
+	}
+
+	// 引入项目角色前的负责人迁移为 owner
+	err = db.Model(&models.ProjectMember{}).
+		Where("assignee = ? AND role <> ?", true, constant.PROJECT_ROLE_OWNER).
+		UpdateColumn("role", constant.PROJECT_ROLE_OWNER).Error
+	if err != nil {
+		Logger.Error(err)
+		panic(err)
+	}
 }
 
 func initDBLogger(level logger.LogLevel, colorful bool) logger.Interface {
@@ -1,6 +1,9 @@
 package global
 
 import (
+	"server/internal/constant"
+	"server/pkg/crypto"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -15,6 +18,7 @@ var (
 
 func InitGlobal() {
 	initLogger()
+	initKeyRing()
 	initDB()
 	initRedis()
 }
@@ -23,6 +27,13 @@ func initLogger() {
 	Logger = getLogger()
 }
 
+func initKeyRing() {
+	if err := crypto.InitKeyRing(constant.JWTConfig); err != nil {
+		Logger.Error(err)
+		panic(err)
+	}
+}
+
 func initDB() {
 	db, err := getDB()
 	if err != nil {
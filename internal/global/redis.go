@@ -68,6 +68,24 @@ func (r *RedisClient) Get(namespace string, key string) any {
 	return result
 }
 
+// GetDel 读取并删除键，键不存在时返回空字符串，可用于一次性令牌
+func (r *RedisClient) GetDel(namespace string, key string) string {
+	getKey := fmt.Sprintf("%s/%s", namespace, key)
+	result, err := r.client.GetDel(context.Background(), getKey).Result()
+	if err != nil {
+		if err != redis.Nil {
+			Logger.Error(err)
+		}
+		return ""
+	}
+
+	if constant.EnvConfig.Mode == "debug" {
+		Logger.Infow("redis GetDel", "key", getKey, "value", result)
+	}
+
+	return result
+}
+
 func (r *RedisClient) Set(namespace string, key string, value any, reset ...time.Duration) {
 	expiration := r.duration
 	if len(reset) > 0 {
@@ -86,6 +104,43 @@ func (r *RedisClient) Set(namespace string, key string, value any, reset ...time
 	}
 }
 
+// SetNX 仅在键不存在时写入，返回是否写入成功
+func (r *RedisClient) SetNX(namespace string, key string, value any, expiration time.Duration) bool {
+	setKey := fmt.Sprintf("%s/%s", namespace, key)
+	ok, err := r.client.SetNX(context.Background(), setKey, value, expiration).Result()
+	if err != nil {
+		Logger.Error(err)
+		return false
+	}
+
+	if constant.EnvConfig.Mode == "debug" {
+		Logger.Infow("redis SetNX", "key", setKey, "value", value, "ok", ok)
+	}
+
+	return ok
+}
+
+// Incr 自增计数，首次写入时设置过期时间
+func (r *RedisClient) Incr(namespace string, key string, expiration time.Duration) int64 {
+	incrKey := fmt.Sprintf("%s/%s", namespace, key)
+	count, err := r.client.Incr(context.Background(), incrKey).Result()
+	if err != nil {
+		Logger.Error(err)
+		return 0
+	}
+	if count == 1 {
+		if err := r.client.Expire(context.Background(), incrKey, expiration).Err(); err != nil {
+			Logger.Error(err)
+		}
+	}
+
+	if constant.EnvConfig.Mode == "debug" {
+		Logger.Infow("redis Incr", "key", incrKey, "count", count)
+	}
+
+	return count
+}
+
 func (r *RedisClient) Delete(namespace string, key ...string) error {
 	var deleteKeys []string
 	for _, k := range key {
@@ -0,0 +1,195 @@
+package lockout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"server/internal/constant"
+	"server/internal/event"
+	"server/internal/global"
+)
+
+// 首次需要等待的时间，之后每次失败翻倍
+const BASE_DELAY = time.Second
+
+// Lock 为一条登录锁定记录，Key 为用户名或 IP
+type Lock struct {
+	Type      string    `json:"type"`
+	Key       string    `json:"key"`
+	Failures  int64     `json:"failures"`
+	IP        string    `json:"ip"`
+	LockedAt  time.Time `json:"locked_at"`
+	ExpiresIn int       `json:"expires_in"`
+}
+
+func userKey(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+func lockNamespace(lockType string) (string, string, error) {
+	switch lockType {
+	case constant.LOCKOUT_TYPE_USER:
+		return constant.LOGIN_LOCKED_USER, constant.LOGIN_FAILURE_USER, nil
+	case constant.LOCKOUT_TYPE_IP:
+		return constant.LOGIN_LOCKED_IP, constant.LOGIN_FAILURE_IP, nil
+	default:
+		return "", "", errors.New("无效的锁定类型")
+	}
+}
+
+// Check 校验密码前调用，IP 或账号被锁定、或距上次失败未满等待时间时返回错误
+func Check(username string, ip string) error {
+	if ip != "" {
+		if ttl := global.Redis.GetTTL(constant.LOGIN_LOCKED_IP, ip); ttl > 0 {
+			return fmt.Errorf("登录失败次数过多，请%d分钟后再试", minutes(ttl))
+		}
+	}
+	key := userKey(username)
+	if ttl := global.Redis.GetTTL(constant.LOGIN_LOCKED_USER, key); ttl > 0 {
+		return fmt.Errorf("账号已被临时锁定，请%d分钟后再试", minutes(ttl))
+	}
+	if ttl := global.Redis.GetTTL(constant.LOGIN_DELAY, key); ttl > 0 {
+		return fmt.Errorf("登录过于频繁，请%d秒后再试", int(math.Ceil(ttl.Seconds())))
+	}
+	return nil
+}
+
+// Fail 记录一次密码错误，达到次数后锁定并通知管理员
+func Fail(username string, ip string) {
+	config := constant.LockoutConfig
+	key := userKey(username)
+
+	count := global.Redis.Incr(constant.LOGIN_FAILURE_USER, key, config.Window)
+	if config.MaxFailures > 0 && count >= int64(config.MaxFailures) {
+		lock(constant.LOCKOUT_TYPE_USER, key, count, ip)
+	} else if config.DelayAfter > 0 && count >= int64(config.DelayAfter) {
+		global.Redis.Set(constant.LOGIN_DELAY, key, count, delay(count-int64(config.DelayAfter)))
+	}
+
+	failIP(ip)
+}
+
+// FailTwoFactor 记录一次两步验证失败，单独计数，不会因密码校验成功而清除
+// 达到次数后与密码错误一样锁定账号，锁定期间无法再发起新的两步验证
+func FailTwoFactor(username string, ip string) {
+	config := constant.LockoutConfig
+	key := userKey(username)
+
+	count := global.Redis.Incr(constant.TWO_FACTOR_FAILURE, key, config.Window)
+	if config.MaxFailures > 0 && count >= int64(config.MaxFailures) {
+		global.Redis.Delete(constant.TWO_FACTOR_FAILURE, key)
+		lock(constant.LOCKOUT_TYPE_USER, key, count, ip)
+	}
+	failIP(ip)
+}
+
+func failIP(ip string) {
+	if ip == "" {
+		return
+	}
+	config := constant.LockoutConfig
+	ipCount := global.Redis.Incr(constant.LOGIN_FAILURE_IP, ip, config.Window)
+	if config.IPMaxFailures > 0 && ipCount >= int64(config.IPMaxFailures) {
+		lock(constant.LOCKOUT_TYPE_IP, ip, ipCount, ip)
+	}
+}
+
+// Succeed 登录成功后清除该用户名的失败记录，IP 的失败记录保留
+func Succeed(username string) {
+	key := userKey(username)
+	global.Redis.Delete(constant.LOGIN_FAILURE_USER, key)
+	global.Redis.Delete(constant.LOGIN_DELAY, key)
+}
+
+// SucceedTwoFactor 两步验证通过后清除该用户的两步验证失败记录
+func SucceedTwoFactor(username string) {
+	global.Redis.Delete(constant.TWO_FACTOR_FAILURE, userKey(username))
+}
+
+func delay(exponent int64) time.Duration {
+	maxDelay := constant.LockoutConfig.MaxDelay
+	if exponent >= 30 {
+		return maxDelay
+	}
+	return min(BASE_DELAY<<exponent, maxDelay)
+}
+
+func minutes(ttl time.Duration) int {
+	return int(math.Ceil(ttl.Minutes()))
+}
+
+func lock(lockType string, key string, failures int64, ip string) {
+	lockedNamespace, failureNamespace, _ := lockNamespace(lockType)
+	data, err := json.Marshal(Lock{
+		Type:     lockType,
+		Key:      key,
+		Failures: failures,
+		IP:       ip,
+		LockedAt: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+	duration := constant.LockoutConfig.Duration
+	global.Redis.Delete(failureNamespace, key)
+	// 已处于锁定状态时不重复通知
+	if !global.Redis.SetNX(lockedNamespace, key, string(data), duration) {
+		return
+	}
+
+	var content string
+	if lockType == constant.LOCKOUT_TYPE_USER {
+		content = fmt.Sprintf("用户『%s』连续%d次登录失败，已锁定%d分钟（IP：%s）", key, failures, minutes(duration), ip)
+	} else {
+		content = fmt.Sprintf("IP『%s』%d次登录失败，已锁定%d分钟", key, failures, minutes(duration))
+	}
+	global.Logger.Warnw("login locked", "type", lockType, "key", key, "failures", failures, "ip", ip)
+	event.AdminPublish(event.Event{Content: &content})
+}
+
+// List 返回当前全部锁定记录
+func List() ([]Lock, error) {
+	locks := []Lock{}
+	for _, namespace := range []string{constant.LOGIN_LOCKED_USER, constant.LOGIN_LOCKED_IP} {
+		iter := global.Redis.Scan(namespace, "*", 100)
+		for iter.Next(global.Redis.Ctx) {
+			key := strings.TrimPrefix(iter.Val(), namespace+"/")
+			value, _ := global.Redis.Get(namespace, key).(string)
+			var lock Lock
+			if value == "" || json.Unmarshal([]byte(value), &lock) != nil {
+				continue
+			}
+			ttl := global.Redis.GetTTL(namespace, key)
+			if ttl <= 0 {
+				continue
+			}
+			lock.ExpiresIn = int(math.Ceil(ttl.Seconds()))
+			locks = append(locks, lock)
+		}
+		if err := iter.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return locks, nil
+}
+
+// Clear 解除锁定并清空失败次数
+func Clear(lockType string, key string) error {
+	lockedNamespace, failureNamespace, err := lockNamespace(lockType)
+	if err != nil {
+		return err
+	}
+	if lockType == constant.LOCKOUT_TYPE_USER {
+		key = userKey(key)
+		global.Redis.Delete(constant.LOGIN_DELAY, key)
+		global.Redis.Delete(constant.TWO_FACTOR_FAILURE, key)
+	}
+	if err := global.Redis.Delete(failureNamespace, key); err != nil {
+		return err
+	}
+	return global.Redis.Delete(lockedNamespace, key)
+}
@@ -4,23 +4,25 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
+	"server/internal/accesstoken"
 	adminServices "server/internal/app/admin/services"
-	kanboardServices "server/internal/app/kanboard/services"
 	"server/internal/common"
 	"server/internal/constant"
-	"server/internal/global"
+	"server/internal/models"
+	"server/internal/session"
+	"server/internal/types"
+	"server/internal/utils"
 	"server/pkg/crypto"
 
 	"github.com/gin-gonic/gin"
 )
 
 var (
-	AUTH_KEY             = "Authorization"
-	AUTH_QUERY           = "token"
-	TOKEN_KEY            = "Token"
-	RENEW_TOKEN_DURATION = 15 * time.Minute
+	AUTH_KEY      = "Authorization"
+	AUTH_QUERY    = "token"
+	TOKEN_KEY     = "Token"
+	ON_BEHALF_KEY = "X-On-Behalf-Of"
 )
 
 func tokenError(ctx *gin.Context, msg string) {
@@ -31,62 +33,175 @@ func tokenError(ctx *gin.Context, msg string) {
 	})
 }
 
+func forbiddenError(ctx *gin.Context, msg string) {
+	common.ResponseWithOmitempty(ctx, common.BaseRspWithOmitempty{
+		Status: http.StatusForbidden,
+		Code:   constant.FAIL,
+		Msg:    msg,
+	})
+}
+
+func getToken(ctx *gin.Context) (string, bool) {
+	authHeader := ctx.GetHeader(AUTH_KEY)
+	authQuery := ctx.Query(AUTH_QUERY)
+
+	if authHeader == "" && authQuery == "" {
+		tokenError(ctx, "Authorization header is required")
+		return "", false
+	}
+
+	if authHeader == "" {
+		return authQuery, true
+	}
+	parts := strings.Split(authHeader, ":")
+	if len(parts) != 2 || parts[0] != TOKEN_KEY {
+		tokenError(ctx, "Invalid token format")
+		return "", false
+	}
+	return parts[1], true
+}
+
+// verifyToken 校验令牌签名，并检查令牌所属的登录会话未被注销、账号未被禁用
+func verifyToken(ctx *gin.Context, namespace string, token string) (*crypto.JWTClaims, bool) {
+	parse := crypto.ParseTokenToKanboard
+	if namespace == constant.ADMIN_TOKEN {
+		parse = crypto.ParseTokenToAdmin
+	}
+	jwtClaims, err := parse(token)
+	if err != nil {
+		tokenError(ctx, err.Error())
+		return nil, false
+	}
+	if jwtClaims.ID == 0 || !session.Validate(namespace, jwtClaims) {
+		tokenError(ctx, "Invalid token")
+		return nil, false
+	}
+	if !session.IsLoginable(jwtClaims.ID) {
+		tokenError(ctx, "User is disabled")
+		return nil, false
+	}
+
+	session.Touch(jwtClaims.SessionID, ctx.ClientIP())
+	return jwtClaims, true
+}
+
+// 路由中的 /:id 必须是当前请求代表的用户
+func checkPathOwner(ctx *gin.Context, userId uint) bool {
+	id := ctx.Param("id")
+	if id != "" && id != strconv.Itoa(int(userId)) {
+		forbiddenError(ctx, "Token does not match user")
+		return false
+	}
+	return true
+}
+
 func Auth(namespace string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		authHeader := ctx.GetHeader(AUTH_KEY)
-		authQuery := ctx.Query(AUTH_QUERY)
+		token, ok := getToken(ctx)
+		if !ok {
+			return
+		}
 
-		if authHeader == "" && authQuery == "" {
-			tokenError(ctx, "Authorization header is required")
+		if onBehalf := ctx.GetHeader(ON_BEHALF_KEY); onBehalf != "" && namespace == constant.KANBOARD_TOKEN {
+			authOnBehalf(ctx, token, onBehalf)
 			return
 		}
 
-		var token string
-		if authHeader != "" {
-			parts := strings.Split(authHeader, ":")
-			if len(parts) != 2 || parts[0] != TOKEN_KEY {
-				tokenError(ctx, "Invalid token format")
+		if accesstoken.IsAccessToken(token) {
+			if namespace != constant.KANBOARD_TOKEN {
+				tokenError(ctx, "Invalid token")
 				return
 			}
-			token = parts[1]
-		} else {
-			token = authQuery
-		}
-
-		jwtClaims, err := crypto.ParseToken(token)
-		if err != nil || jwtClaims.ID == 0 {
-			tokenError(ctx, err.Error())
+			authAccessToken(ctx, token)
 			return
 		}
 
-		userId := strconv.Itoa(int(jwtClaims.ID))
-		result := global.Redis.Get(namespace, userId)
-		if result != token {
-			tokenError(ctx, "Invalid token")
+		jwtClaims, ok := verifyToken(ctx, namespace, token)
+		if !ok {
 			return
 		}
-
-		ttl := global.Redis.GetTTL(namespace, userId)
-		if ttl <= 0 {
-			tokenError(ctx, "Token expired")
+		if !checkPathOwner(ctx, jwtClaims.ID) {
 			return
 		}
 
-		if ttl.Minutes() <= RENEW_TOKEN_DURATION.Minutes() {
-			var token string
-			var err error
-			if namespace == constant.KANBOARD_TOKEN {
-				token, err = kanboardServices.GenerateToken(jwtClaims.ID, jwtClaims.Username)
-			} else {
-				token, err = adminServices.GenerateToken(jwtClaims.ID, jwtClaims.Username)
-			}
-			if err != nil {
-				tokenError(ctx, err.Error())
-				return
-			}
-			ctx.Header("Token", token)
+		utils.SetPrincipal(ctx, &types.Principal{
+			UserID:    jwtClaims.ID,
+			Username:  jwtClaims.Username,
+			ActorID:   jwtClaims.ID,
+			ActorName: jwtClaims.Username,
+			SessionID: jwtClaims.SessionID,
+			TokenID:   jwtClaims.RegisteredClaims.ID,
+		})
+		ctx.Next()
+	}
+}
+
+// authAccessToken 个人访问令牌只能访问授予了对应权限范围的接口
+func authAccessToken(ctx *gin.Context, token string) {
+	accessToken, user, err := accesstoken.Authenticate(token, ctx.ClientIP())
+	if err != nil {
+		tokenError(ctx, err.Error())
+		return
+	}
+	scope, ok := accesstoken.RouteScope(ctx.Request.Method, ctx.FullPath())
+	if !ok || !accessToken.HasScope(scope) {
+		forbiddenError(ctx, "Access token scope does not allow this request")
+		return
+	}
+	if !checkPathOwner(ctx, user.ID) {
+		return
+	}
+
+	utils.SetPrincipal(ctx, &types.Principal{
+		UserID:        user.ID,
+		Username:      user.Username,
+		ActorID:       user.ID,
+		ActorName:     user.Username,
+		AccessTokenID: accessToken.ID,
+	})
+	ctx.Next()
+}
+
+// authOnBehalf 管理员携带管理端令牌与 X-On-Behalf-Of 请求头代为操作用户接口，
+// 每次请求都会写入审计记录
+func authOnBehalf(ctx *gin.Context, token string, onBehalf string) {
+	jwtClaims, ok := verifyToken(ctx, constant.ADMIN_TOKEN, token)
+	if !ok {
+		return
+	}
+	userId, err := strconv.ParseUint(onBehalf, 10, 0)
+	if err != nil || !checkPathOwner(ctx, uint(userId)) {
+		if err != nil {
+			forbiddenError(ctx, "Invalid "+ON_BEHALF_KEY)
 		}
+		return
+	}
 
-		ctx.Next()
+	auditService := adminServices.NewAuditService()
+	admin, user, err := auditService.AuthorizeOnBehalf(jwtClaims.ID, uint(userId))
+	if err != nil {
+		forbiddenError(ctx, err.Error())
+		return
 	}
+
+	utils.SetPrincipal(ctx, &types.Principal{
+		UserID:    user.ID,
+		Username:  user.Username,
+		ActorID:   admin.ID,
+		ActorName: admin.Username,
+		OnBehalf:  true,
+		SessionID: jwtClaims.SessionID,
+		TokenID:   jwtClaims.RegisteredClaims.ID,
+	})
+	ctx.Next()
+
+	auditService.Record(models.AuditLog{
+		ActorID: admin.ID,
+		UserID:  user.ID,
+		Action:  constant.AUDIT_ACTION_ON_BEHALF,
+		Method:  ctx.Request.Method,
+		Path:    ctx.Request.URL.Path,
+		Status:  ctx.Writer.Status(),
+		IP:      ctx.ClientIP(),
+	})
 }
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// 个人访问令牌，供脚本与机器人调用看板接口，只保存摘要
+// Scopes 以逗号分隔，ExpiresAt 为空表示永不过期
+type AccessToken struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"index;not null"`
+	Name      string `gorm:"size:64;not null"`
+	TokenHash string `gorm:"size:64;uniqueIndex;not null"`
+	// 令牌末尾几位，便于用户辨认
+	Hint       string     `gorm:"size:8;not null"`
+	Scopes     string     `gorm:"size:255;not null"`
+	ExpiresAt  *time.Time `gorm:"index;default:null"`
+	LastUsedAt *time.Time `gorm:"default:null"`
+	LastUsedIP string     `gorm:"size:64"`
+	CreatedAt  time.Time
+}
+
+func (a *AccessToken) ScopeList() []string {
+	if a.Scopes == "" {
+		return []string{}
+	}
+	return strings.Split(a.Scopes, ",")
+}
+
+func (a *AccessToken) HasScope(scope string) bool {
+	return slices.Contains(a.ScopeList(), scope)
+}
+
+func (a *AccessToken) Expired(now time.Time) bool {
+	return a.ExpiresAt != nil && !now.Before(*a.ExpiresAt)
+}
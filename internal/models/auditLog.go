@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// 管理员敏感操作的审计记录，UserID 为被操作的用户
+type AuditLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	ActorID   uint      `gorm:"index;not null" json:"actor_id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	Action    string    `gorm:"size:50;index;not null" json:"action"`
+	Method    string    `gorm:"size:10" json:"method"`
+	Path      string    `gorm:"size:1024" json:"path"`
+	Status    int       `json:"status"`
+	IP        string    `gorm:"size:64" json:"ip"`
+	Detail    string    `gorm:"type:text" json:"detail"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type AutomationLog struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	RuleID    uint      `gorm:"index;not null" json:"rule_id"`
+	ProjectID uint      `gorm:"index;not null" json:"project_id"`
+	TaskID    uint      `gorm:"index;not null" json:"task_id"`
+	Trigger   string    `gorm:"size:64;not null" json:"trigger"`
+	Result    string    `gorm:"size:16;not null" json:"result"`
+	Message   string    `gorm:"type:text" json:"message"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// 自动化规则，Conditions 与 Params 以 JSON 保存
+type AutomationRule struct {
+	gorm.Model
+	ProjectID  uint   `gorm:"index;not null"`
+	Name       string `gorm:"size:255;not null"`
+	Trigger    string `gorm:"size:64;index;not null"`
+	Conditions string `gorm:"type:text"`
+	Action     string `gorm:"size:64;not null"`
+	Params     string `gorm:"type:text"`
+	Enabled    bool   `gorm:"index;not null;default:true"`
+	CreatorID  uint   `gorm:"not null"`
+}
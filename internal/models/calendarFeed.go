@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// 日历订阅令牌，ProjectID 为空时为个人日历
+type CalendarFeed struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	UserID         uint       `gorm:"index;not null" json:"user_id"`
+	ProjectID      *uint      `gorm:"index;default:null" json:"project_id"`
+	TokenHash      string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastAccessedAt *time.Time `gorm:"default:null" json:"last_accessed_at"`
+}
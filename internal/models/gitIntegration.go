@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// 代码仓库接入，CloseTasks 为真时合入默认分支的提交可将引用的任务标记为完成，
+// 状态变更以创建者的身份执行
+type GitIntegration struct {
+	gorm.Model
+	ProjectID  uint   `gorm:"index;not null"`
+	Provider   string `gorm:"size:20;not null"`
+	Secret     string `gorm:"size:255;not null"`
+	CloseTasks bool   `gorm:"not null;default:true"`
+	CreatorID  uint   `gorm:"not null"`
+}
@@ -15,11 +15,27 @@ type ProjectMember struct {
 	UserID    uint      `gorm:"primary_key;index" json:"user_id"`
 	Username  string    `gorm:"size:255;not null" json:"username"`
 	Assignee  bool      `gorm:"not null;default:false" json:"assignee"`
+	Role      string    `gorm:"size:20;not null;default:member" json:"role"`
 	JoinedAt  time.Time `json:"joined_at"`
 }
 
+var projectRoleNames = map[string]string{
+	constant.PROJECT_ROLE_OWNER:      "负责人",
+	constant.PROJECT_ROLE_MAINTAINER: "维护者",
+	constant.PROJECT_ROLE_MEMBER:     "成员",
+	constant.PROJECT_ROLE_VIEWER:     "访客",
+}
+
+// Assignee 与负责人角色保持一致
 func (p *ProjectMember) BeforeCreate(db *gorm.DB) error {
 	p.JoinedAt = time.Now()
+	if p.Role == "" {
+		p.Role = constant.PROJECT_ROLE_MEMBER
+		if p.Assignee {
+			p.Role = constant.PROJECT_ROLE_OWNER
+		}
+	}
+	p.Assignee = p.Role == constant.PROJECT_ROLE_OWNER
 	return nil
 }
 
@@ -31,12 +47,7 @@ func (p *ProjectMember) AfterUpdate(db *gorm.DB) error {
 		return err
 	}
 
-	var content string
-	if p.Assignee {
-		content = fmt.Sprintf("『%s』成为项目『%s』的负责人", p.Username, project.Name)
-	} else {
-		content = fmt.Sprintf("『%s』不再是项目『%s』的负责人", p.Username, project.Name)
-	}
+	content := fmt.Sprintf("『%s』在项目『%s』中的角色变更为%s", p.Username, project.Name, projectRoleNames[p.Role])
 	eventType := constant.PROJECT_EVENT
 	event.KanboardPublish(event.Event{EventType: &eventType, Content: &content, ProjectID: &projectID, UserID: &p.UserID})
 	return nil
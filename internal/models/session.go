@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// 登录会话，每次登录创建一条，刷新令牌只保存摘要
+// Namespace 区分看板与管理端会话
+type Session struct {
+	ID                   string    `gorm:"size:32;primarykey"`
+	UserID               uint      `gorm:"index;not null"`
+	Namespace            string    `gorm:"size:32;index;not null"`
+	Device               string    `gorm:"size:255"`
+	IP                   string    `gorm:"size:64"`
+	RefreshTokenHash     string    `gorm:"size:64;uniqueIndex;not null"`
+	PrevRefreshTokenHash string    `gorm:"size:64;index"`
+	ExpiresAt            time.Time `gorm:"index;not null"`
+	LastSeenAt           time.Time `gorm:"not null"`
+	CreatedAt            time.Time
+}
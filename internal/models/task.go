@@ -12,13 +12,15 @@ import (
 
 type Task struct {
 	gorm.Model
-	Title     string    `gorm:"size:255;not null"`
-	Desc      string    `gorm:"not null"`
-	Status    uint      `gorm:"size:1;index;default:0;not null"`
-	DueDate   time.Time `gorm:"size:1;index;default:null"`
-	Priority  int       `gorm:"size:1;index;default:0;not null"`
-	ProjectID uint      `gorm:"index;not null"`
-	CreatorID uint      `gorm:"index;not null"`
+	Title       string     `gorm:"size:255;not null"`
+	Desc        string     `gorm:"not null"`
+	Status      uint       `gorm:"size:1;index;default:0;not null"`
+	StartDate   time.Time  `gorm:"index;default:null"`
+	DueDate     time.Time  `gorm:"size:1;index;default:null"`
+	CompletedAt *time.Time `gorm:"index;default:null"`
+	Priority    int        `gorm:"size:1;index;default:0;not null"`
+	ProjectID   uint       `gorm:"index;not null"`
+	CreatorID   uint       `gorm:"index;not null"`
 }
 
 func (t *Task) AfterCreate(db *gorm.DB) error {
@@ -44,3 +46,10 @@ func (t *Task) AfterUpdate(db *gorm.DB) error {
 	event.KanboardPublish(event.Event{EventType: &eventType, Content: &content, ProjectID: &t.ProjectID, TaskID: &t.ID})
 	return nil
 }
+
+func (t *Task) AfterDelete(db *gorm.DB) error {
+	eventType := constant.TASK_DELETED_EVENT
+	content := fmt.Sprintf("删除任务『%s』", t.Title)
+	event.KanboardPublish(event.Event{EventType: &eventType, Content: &content, ProjectID: &t.ProjectID, TaskID: &t.ID})
+	return nil
+}
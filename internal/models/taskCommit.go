@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// 任务关联的提交或合并请求，Ref 为提交哈希或合并请求编号
+type TaskCommit struct {
+	ID            uint       `gorm:"primarykey"`
+	TaskID        uint       `gorm:"uniqueIndex:idx_task_commit;not null"`
+	ProjectID     uint       `gorm:"index;not null"`
+	IntegrationID uint       `gorm:"index;not null"`
+	Kind          string     `gorm:"size:20;uniqueIndex:idx_task_commit;not null"`
+	Ref           string     `gorm:"size:64;uniqueIndex:idx_task_commit;not null"`
+	Repository    string     `gorm:"size:255"`
+	Title         string     `gorm:"size:1024"`
+	URL           string     `gorm:"size:1024"`
+	Author        string     `gorm:"size:255"`
+	CommittedAt   *time.Time `gorm:"default:null"`
+	CreatedAt     time.Time
+}
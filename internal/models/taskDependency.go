@@ -0,0 +1,7 @@
+package models
+
+type TaskDependency struct {
+	ProjectID   uint `gorm:"index;not null" json:"project_id"`
+	TaskID      uint `gorm:"primary_key" json:"task_id"`
+	DependsOnID uint `gorm:"primary_key" json:"depends_on_id"`
+}
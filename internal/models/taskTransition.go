@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type TaskTransition struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	ProjectID  uint      `gorm:"index;not null" json:"project_id"`
+	TaskID     uint      `gorm:"index;not null" json:"task_id"`
+	FromStatus *uint     `gorm:"size:1;default:null" json:"from_status"`
+	ToStatus   uint      `gorm:"size:1;not null" json:"to_status"`
+	ActorID    uint      `gorm:"index;not null" json:"actor_id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// 用户的 TOTP 两步验证，EnabledAt 为空表示已生成密钥但尚未完成绑定
+type TwoFactor struct {
+	ID        uint       `gorm:"primarykey"`
+	UserID    uint       `gorm:"uniqueIndex;not null"`
+	Secret    string     `gorm:"size:64;not null"`
+	EnabledAt *time.Time `gorm:"default:null"`
+	// 最近一次使用的验证码所在时间步，不接受更早的验证码
+	LastStep  int64 `gorm:"not null;default:0"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// 两步验证的一次性恢复码，只保存摘要
+type RecoveryCode struct {
+	ID        uint       `gorm:"primarykey"`
+	UserID    uint       `gorm:"index;not null"`
+	CodeHash  string     `gorm:"size:64;not null"`
+	UsedAt    *time.Time `gorm:"default:null"`
+	CreatedAt time.Time
+}
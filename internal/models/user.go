@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"time"
 
 	"server/internal/constant"
 	"server/internal/event"
@@ -21,7 +22,16 @@ type User struct {
 	CreateFrom constant.From   `gorm:"<-create;size:1;index;not null"`
 	IsAdmin    bool            `gorm:"default:0;not null"`
 	Loginable  bool            `gorm:"not null"`
-	Position   string          `gorm:"default:null"`
+	// 管理员禁用账号时填写的原因与时间
+	LockReason string     `gorm:"size:255;default:null"`
+	LockedAt   *time.Time `gorm:"default:null"`
+	Position   string     `gorm:"default:null"`
+	// 为空表示邮箱尚未验证
+	EmailVerifiedAt *time.Time `gorm:"default:null"`
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
 
 func (u *User) EncryptPassword() error {
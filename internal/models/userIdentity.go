@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// 外部身份提供方中的账号与本地用户的关联
+// Provider 为身份提供方标识，OIDC 使用其 issuer，Subject 为对方账号的唯一标识
+type UserIdentity struct {
+	ID        uint   `gorm:"primarykey"`
+	UserID    uint   `gorm:"index;not null"`
+	Provider  string `gorm:"size:255;uniqueIndex:idx_user_identity_subject;not null"`
+	Subject   string `gorm:"size:255;uniqueIndex:idx_user_identity_subject;not null"`
+	CreatedAt time.Time
+}
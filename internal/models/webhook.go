@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+// 项目的 Webhook 订阅，Events 为逗号分隔的事件名，为空时订阅全部事件
+type Webhook struct {
+	gorm.Model
+	ProjectID uint   `gorm:"index;not null"`
+	URL       string `gorm:"size:1024;not null"`
+	Secret    string `gorm:"size:255;not null"`
+	Events    string `gorm:"size:1024"`
+	Enabled   bool   `gorm:"index;not null;default:true"`
+	CreatorID uint   `gorm:"not null"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type WebhookDelivery struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	WebhookID    uint       `gorm:"index;not null" json:"webhook_id"`
+	ProjectID    uint       `gorm:"index;not null" json:"project_id"`
+	Event        string     `gorm:"size:64;not null" json:"event"`
+	Payload      string     `gorm:"type:mediumtext;not null" json:"payload"`
+	Status       string     `gorm:"size:16;index;not null" json:"status"`
+	Attempts     int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseCode int        `json:"response_code"`
+	ResponseBody string     `gorm:"type:text" json:"response_body"`
+	Error        string     `gorm:"type:text" json:"error"`
+	NextRetryAt  *time.Time `gorm:"index;default:null" json:"next_retry_at"`
+	DeliveredAt  *time.Time `gorm:"default:null" json:"delivered_at"`
+	CreatedAt    time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
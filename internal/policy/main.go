@@ -0,0 +1,56 @@
+package policy
+
+import (
+	"slices"
+
+	"server/internal/constant"
+)
+
+// 角色权限矩阵
+//
+//	               owner  maintainer  member  viewer
+//	view             ✓        ✓         ✓       ✓
+//	create           ✓        ✓         ✓
+//	edit_any         ✓        ✓
+//	delete           ✓        ✓
+//	manage_members   ✓        ✓
+//	manage_settings  ✓
+//
+// create 同时涵盖变更任务状态以及编辑、删除自己创建的任务
+var matrix = map[string][]string{
+	constant.PROJECT_ROLE_OWNER: {
+		constant.PERMISSION_VIEW,
+		constant.PERMISSION_CREATE,
+		constant.PERMISSION_EDIT_ANY,
+		constant.PERMISSION_DELETE,
+		constant.PERMISSION_MANAGE_MEMBERS,
+		constant.PERMISSION_MANAGE_SETTINGS,
+	},
+	constant.PROJECT_ROLE_MAINTAINER: {
+		constant.PERMISSION_VIEW,
+		constant.PERMISSION_CREATE,
+		constant.PERMISSION_EDIT_ANY,
+		constant.PERMISSION_DELETE,
+		constant.PERMISSION_MANAGE_MEMBERS,
+	},
+	constant.PROJECT_ROLE_MEMBER: {
+		constant.PERMISSION_VIEW,
+		constant.PERMISSION_CREATE,
+	},
+	constant.PROJECT_ROLE_VIEWER: {
+		constant.PERMISSION_VIEW,
+	},
+}
+
+func Can(role string, permission string) bool {
+	return slices.Contains(matrix[role], permission)
+}
+
+func IsValidRole(role string) bool {
+	_, ok := matrix[role]
+	return ok
+}
+
+func Permissions(role string) []string {
+	return slices.Clone(matrix[role])
+}
@@ -0,0 +1,63 @@
+package repositories
+
+import (
+	"time"
+
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type AccessTokenRepo struct {
+	db *gorm.DB
+}
+
+var accessTokenRepo *AccessTokenRepo
+
+func NewAccessTokenRepo() *AccessTokenRepo {
+	if accessTokenRepo == nil {
+		accessTokenRepo = &AccessTokenRepo{
+			db: global.DB,
+		}
+	}
+	return accessTokenRepo
+}
+
+func (a *AccessTokenRepo) CreateAccessToken(accessToken models.AccessToken) (*models.AccessToken, error) {
+	err := a.db.Create(&accessToken).Error
+	return utils.HandleError(&accessToken, err)
+}
+
+func (a *AccessTokenRepo) GetAccessTokenByHash(tokenHash string) (*models.AccessToken, error) {
+	var accessToken models.AccessToken
+	err := a.db.Find(&accessToken, "token_hash = ?", tokenHash).Error
+	return utils.HandleError(&accessToken, err)
+}
+
+func (a *AccessTokenRepo) GetAccessTokensByUserId(userId uint) (*[]models.AccessToken, error) {
+	var accessTokens []models.AccessToken
+	err := a.db.Order("id DESC").Find(&accessTokens, "user_id = ?", userId).Error
+	return utils.HandleError(&accessTokens, err)
+}
+
+func (a *AccessTokenRepo) GetAccessTokenCountByUserId(userId uint) (int64, error) {
+	var count int64
+	err := a.db.Model(&models.AccessToken{}).Where("user_id = ?", userId).Count(&count).Error
+	return count, err
+}
+
+func (a *AccessTokenRepo) DeleteAccessToken(id uint, userId uint) (int64, error) {
+	result := a.db.Delete(&models.AccessToken{}, "id = ? AND user_id = ?", id, userId)
+	return result.RowsAffected, result.Error
+}
+
+func (a *AccessTokenRepo) DeleteAccessTokensByUserId(userId uint) error {
+	return a.db.Delete(&models.AccessToken{}, "user_id = ?", userId).Error
+}
+
+func (a *AccessTokenRepo) TouchAccessToken(id uint, usedAt time.Time, ip string) error {
+	return a.db.Model(&models.AccessToken{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"last_used_at": usedAt, "last_used_ip": ip}).Error
+}
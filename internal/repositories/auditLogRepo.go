@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type AuditLogRepo struct {
+	db *gorm.DB
+}
+
+var auditLogRepo *AuditLogRepo
+
+func NewAuditLogRepo() *AuditLogRepo {
+	if auditLogRepo == nil {
+		auditLogRepo = &AuditLogRepo{
+			db: global.DB,
+		}
+	}
+	return auditLogRepo
+}
+
+func (a *AuditLogRepo) CreateLog(log models.AuditLog) error {
+	return a.db.Create(&log).Error
+}
+
+func (a *AuditLogRepo) filter(query map[string]any) *gorm.DB {
+	db := a.db.Model(&models.AuditLog{})
+	if actorId, ok := query["actorId"].(uint); ok {
+		db = db.Where("actor_id = ?", actorId)
+	}
+	if userId, ok := query["userId"].(uint); ok {
+		db = db.Where("user_id = ?", userId)
+	}
+	if action, ok := query["action"].(string); ok {
+		db = db.Where("action = ?", action)
+	}
+	return db
+}
+
+func (a *AuditLogRepo) GetLogCount(query map[string]any) (int64, error) {
+	var count int64
+	err := a.filter(query).Count(&count).Error
+	return count, err
+}
+
+func (a *AuditLogRepo) GetLogs(query map[string]any, page int, pageSize int) (*[]models.AuditLog, error) {
+	var logs []models.AuditLog
+	err := a.filter(query).Order("id DESC").Limit(pageSize).Offset((page - 1) * pageSize).Find(&logs).Error
+	return utils.HandleError(&logs, err)
+}
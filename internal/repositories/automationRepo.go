@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type AutomationRepo struct {
+	db *gorm.DB
+}
+
+var automationRepo *AutomationRepo
+
+func NewAutomationRepo() *AutomationRepo {
+	if automationRepo == nil {
+		automationRepo = &AutomationRepo{
+			db: global.DB,
+		}
+	}
+	return automationRepo
+}
+
+func (a *AutomationRepo) CreateRule(rule models.AutomationRule) (*models.AutomationRule, error) {
+	err := a.db.Create(&rule).Error
+	return utils.HandleError(&rule, err)
+}
+
+func (a *AutomationRepo) UpdateRule(values map[string]any, id uint, projectId uint) error {
+	var rule models.AutomationRule
+	if err := a.db.First(&rule, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
+		return err
+	}
+	return a.db.Model(&rule).Updates(values).Error
+}
+
+func (a *AutomationRepo) DeleteRule(id uint, projectId uint) error {
+	var rule models.AutomationRule
+	if err := a.db.First(&rule, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
+		return err
+	}
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.AutomationLog{}, "rule_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&rule).Error
+	})
+}
+
+func (a *AutomationRepo) GetRuleById(id uint, projectId uint) (*models.AutomationRule, error) {
+	var rule models.AutomationRule
+	err := a.db.Find(&rule, "id = ? AND project_id = ?", id, projectId).Error
+	return utils.HandleError(&rule, err)
+}
+
+func (a *AutomationRepo) GetRulesByProjectId(projectId uint) (*[]models.AutomationRule, error) {
+	var rules []models.AutomationRule
+	err := a.db.Order("id").Find(&rules, "project_id = ?", projectId).Error
+	return utils.HandleError(&rules, err)
+}
+
+func (a *AutomationRepo) GetEnabledRules(projectId uint, trigger string) (*[]models.AutomationRule, error) {
+	var rules []models.AutomationRule
+	err := a.db.Order("id").Find(&rules, "project_id = ? AND `trigger` = ? AND enabled = ?", projectId, trigger, true).Error
+	return utils.HandleError(&rules, err)
+}
+
+func (a *AutomationRepo) CreateLog(log models.AutomationLog) error {
+	return a.db.Create(&log).Error
+}
+
+func (a *AutomationRepo) GetLogCountByRuleId(ruleId uint) (int64, error) {
+	var count int64
+	err := a.db.Model(&models.AutomationLog{}).Where("rule_id = ?", ruleId).Count(&count).Error
+	return count, err
+}
+
+func (a *AutomationRepo) GetLogsByRuleId(ruleId uint, page int, pageSize int) (*[]models.AutomationLog, error) {
+	var logs []models.AutomationLog
+	err := a.db.Order("id DESC").Limit(pageSize).Offset((page-1)*pageSize).Find(&logs, "rule_id = ?", ruleId).Error
+	return utils.HandleError(&logs, err)
+}
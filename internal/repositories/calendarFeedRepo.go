@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"time"
+
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedRepo struct {
+	db *gorm.DB
+}
+
+var calendarFeedRepo *CalendarFeedRepo
+
+func NewCalendarFeedRepo() *CalendarFeedRepo {
+	if calendarFeedRepo == nil {
+		calendarFeedRepo = &CalendarFeedRepo{
+			db: global.DB,
+		}
+	}
+	return calendarFeedRepo
+}
+
+// 同一用户同一范围只保留一个订阅，重新生成时旧令牌立即失效
+func (c *CalendarFeedRepo) ReplaceFeed(feed models.CalendarFeed) (*models.CalendarFeed, error) {
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("user_id = ?", feed.UserID)
+		if feed.ProjectID != nil {
+			query = query.Where("project_id = ?", *feed.ProjectID)
+		} else {
+			query = query.Where("project_id IS NULL")
+		}
+		if err := query.Delete(&models.CalendarFeed{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&feed).Error
+	})
+	return utils.HandleError(&feed, err)
+}
+
+func (c *CalendarFeedRepo) GetFeedByTokenHash(tokenHash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	err := c.db.Find(&feed, "token_hash = ?", tokenHash).Error
+	return utils.HandleError(&feed, err)
+}
+
+func (c *CalendarFeedRepo) GetFeedsByUserId(userId uint) (*[]models.CalendarFeed, error) {
+	var feeds []models.CalendarFeed
+	err := c.db.Order("id").Find(&feeds, "user_id = ?", userId).Error
+	return utils.HandleError(&feeds, err)
+}
+
+func (c *CalendarFeedRepo) DeleteFeed(id uint, userId uint) (int64, error) {
+	result := c.db.Delete(&models.CalendarFeed{}, "id = ? AND user_id = ?", id, userId)
+	return result.RowsAffected, result.Error
+}
+
+func (c *CalendarFeedRepo) DeleteFeedsByProjectId(projectId uint) error {
+	return c.db.Delete(&models.CalendarFeed{}, "project_id = ?", projectId).Error
+}
+
+func (c *CalendarFeedRepo) TouchFeed(id uint, accessedAt time.Time) error {
+	return c.db.Model(&models.CalendarFeed{}).Where("id = ?", id).UpdateColumn("last_accessed_at", accessedAt).Error
+}
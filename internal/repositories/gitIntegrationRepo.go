@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GitIntegrationRepo struct {
+	db *gorm.DB
+}
+
+var gitIntegrationRepo *GitIntegrationRepo
+
+func NewGitIntegrationRepo() *GitIntegrationRepo {
+	if gitIntegrationRepo == nil {
+		gitIntegrationRepo = &GitIntegrationRepo{
+			db: global.DB,
+		}
+	}
+	return gitIntegrationRepo
+}
+
+func (g *GitIntegrationRepo) CreateIntegration(integration models.GitIntegration) (*models.GitIntegration, error) {
+	err := g.db.Create(&integration).Error
+	return utils.HandleError(&integration, err)
+}
+
+func (g *GitIntegrationRepo) DeleteIntegration(id uint, projectId uint) error {
+	var integration models.GitIntegration
+	if err := g.db.First(&integration, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
+		return err
+	}
+	return g.db.Delete(&integration).Error
+}
+
+func (g *GitIntegrationRepo) DeleteIntegrationsByProjectId(projectId uint) error {
+	return g.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.TaskCommit{}, "project_id = ?", projectId).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.GitIntegration{}, "project_id = ?", projectId).Error
+	})
+}
+
+func (g *GitIntegrationRepo) GetIntegrationById(id uint) (*models.GitIntegration, error) {
+	var integration models.GitIntegration
+	err := g.db.Find(&integration, "id = ?", id).Error
+	return utils.HandleError(&integration, err)
+}
+
+func (g *GitIntegrationRepo) GetIntegrationsByProjectId(projectId uint) (*[]models.GitIntegration, error) {
+	var integrations []models.GitIntegration
+	err := g.db.Order("id").Find(&integrations, "project_id = ?", projectId).Error
+	return utils.HandleError(&integrations, err)
+}
+
+// CreateTaskCommit 同一任务重复关联同一提交时忽略，返回是否新增
+func (g *GitIntegrationRepo) CreateTaskCommit(commit models.TaskCommit) (bool, error) {
+	result := g.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&commit)
+	return result.RowsAffected == 1, result.Error
+}
+
+func (g *GitIntegrationRepo) GetTaskCommitsByTaskId(taskId uint) (*[]models.TaskCommit, error) {
+	var commits []models.TaskCommit
+	err := g.db.Order("id DESC").Find(&commits, "task_id = ?", taskId).Error
+	return utils.HandleError(&commits, err)
+}
@@ -74,6 +74,10 @@ func (m *MessageRepo) AddUnReadMsg(userID, msgeID string, namespace string) erro
 	return m.redis.SAdd(namespace, userID, msgeID, true)
 }
 
+func (m *MessageRepo) AddReadedMsg(userID, msgID string, namespace string) error {
+	return m.redis.SAdd(namespace, userID, msgID, false)
+}
+
 func (m *MessageRepo) GetUnReadCount(userID string, namespace string) int64 {
 	return m.redis.SCard(namespace, userID)
 }
@@ -377,6 +381,20 @@ func (m *MessageRepo) GetAllAdminMsgs(count int64) ([]models.Message, error) {
 }
 
 func (m *MessageRepo) GetMsgsByProjectId(count int64, projectId uint) ([]models.Message, error) {
+	messages, err := m.GetAllMsgsByProjectId(count, projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只取最新的10条
+	if len(messages) > 10 {
+		messages = messages[:10]
+	}
+
+	return messages, nil
+}
+
+func (m *MessageRepo) GetAllMsgsByProjectId(count int64, projectId uint) ([]models.Message, error) {
 	messages := []models.Message{}
 	match := fmt.Sprintf("%d/*", projectId)
 	iter := m.redis.Scan(constant.KANBOARD_NOTIFICATION, match, count)
@@ -426,10 +444,5 @@ func (m *MessageRepo) GetMsgsByProjectId(count int64, projectId uint) ([]models.
 		return t1 > t2
 	})
 
-	// 只取最新的10条
-	if len(messages) > 10 {
-		messages = messages[:10]
-	}
-
 	return messages, nil
 }
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type ProjectArchiveRepo struct {
+	db *gorm.DB
+}
+
+var projectArchiveRepo *ProjectArchiveRepo
+
+func NewProjectArchiveRepo() *ProjectArchiveRepo {
+	if projectArchiveRepo == nil {
+		projectArchiveRepo = &ProjectArchiveRepo{
+			db: global.DB,
+		}
+	}
+	return projectArchiveRepo
+}
+
+type ProjectSnapshot struct {
+	Project      models.Project
+	Members      []models.ProjectMember
+	Tasks        []models.Task
+	Assignees    []models.TaskAssignee
+	Dependencies []models.TaskDependency
+	Transitions  []models.TaskTransition
+}
+
+func (p *ProjectArchiveRepo) GetProjectSnapshot(projectId uint) (*ProjectSnapshot, error) {
+	var snapshot ProjectSnapshot
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&snapshot.Project, projectId).Error; err != nil {
+			return err
+		}
+		if err := tx.Order("joined_at").Find(&snapshot.Members, "project_id = ?", projectId).Error; err != nil {
+			return err
+		}
+		if err := tx.Order("id").Find(&snapshot.Tasks, "project_id = ?", projectId).Error; err != nil {
+			return err
+		}
+		if err := tx.Find(&snapshot.Assignees, "project_id = ?", projectId).Error; err != nil {
+			return err
+		}
+		if err := tx.Find(&snapshot.Dependencies, "project_id = ?", projectId).Error; err != nil {
+			return err
+		}
+		return tx.Order("created_at, id").Find(&snapshot.Transitions, "project_id = ?", projectId).Error
+	})
+	return utils.HandleError(&snapshot, err)
+}
+
+// 恢复项目快照，所有记录重新分配 ID，返回新项目 ID 与新旧任务 ID 的映射
+// 恢复过程跳过模型钩子，避免为历史数据逐条推送通知
+func (p *ProjectArchiveRepo) RestoreProjectSnapshot(snapshot ProjectSnapshot) (uint, map[uint]uint, error) {
+	taskIds := make(map[uint]uint)
+	project := snapshot.Project
+	project.ID = 0
+
+	err := p.db.Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&project).Error; err != nil {
+			return err
+		}
+
+		for _, member := range snapshot.Members {
+			member.ProjectID = project.ID
+			// 早期归档没有角色字段
+			if member.Role == "" && member.Assignee {
+				member.Role = constant.PROJECT_ROLE_OWNER
+			}
+			if err := tx.Create(&member).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, task := range snapshot.Tasks {
+			oldId := task.ID
+			task.ID = 0
+			task.ProjectID = project.ID
+			if err := tx.Create(&task).Error; err != nil {
+				return err
+			}
+			taskIds[oldId] = task.ID
+		}
+
+		for _, assignee := range snapshot.Assignees {
+			taskId, ok := taskIds[assignee.TaskID]
+			if !ok {
+				continue
+			}
+			assignee.ProjectID = project.ID
+			assignee.TaskID = taskId
+			if err := tx.Create(&assignee).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, dependency := range snapshot.Dependencies {
+			taskId, ok := taskIds[dependency.TaskID]
+			dependsOnId, dependsOk := taskIds[dependency.DependsOnID]
+			if !ok || !dependsOk {
+				continue
+			}
+			dependency.ProjectID = project.ID
+			dependency.TaskID = taskId
+			dependency.DependsOnID = dependsOnId
+			if err := tx.Create(&dependency).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, transition := range snapshot.Transitions {
+			taskId, ok := taskIds[transition.TaskID]
+			if !ok {
+				continue
+			}
+			transition.ID = 0
+			transition.ProjectID = project.ID
+			transition.TaskID = taskId
+			if err := tx.Create(&transition).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return project.ID, taskIds, nil
+}
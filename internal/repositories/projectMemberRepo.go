@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"server/internal/constant"
 	"server/internal/global"
 	"server/internal/models"
 	"server/internal/utils"
@@ -29,14 +30,25 @@ func (p *ProjectMemberRepo) CheckProjectMemberExist(projectId uint, userId uint)
 	return err == nil
 }
 
+// ChangeAssignees 设为负责人即授予 owner 角色，取消负责人时 owner 降为 member
 func (p *ProjectMemberRepo) ChangeAssignees(userIds []uint, projectId uint, assignee bool) error {
 	tx := p.db.Begin()
 	if tx.Error != nil {
 		return tx.Error
 	}
-	var projectMember models.ProjectMember
 	for _, userId := range userIds {
-		if err := tx.Model(&projectMember).Where("user_id = ? and project_id = ?", userId, projectId).First(&projectMember).Update("assignee", assignee).Error; err != nil {
+		var projectMember models.ProjectMember
+		if err := tx.First(&projectMember, "user_id = ? and project_id = ?", userId, projectId).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		role := projectMember.Role
+		if assignee {
+			role = constant.PROJECT_ROLE_OWNER
+		} else if role == constant.PROJECT_ROLE_OWNER {
+			role = constant.PROJECT_ROLE_MEMBER
+		}
+		if err := p.updateRole(tx, &projectMember, role); err != nil {
 			tx.Rollback()
 			return err
 		}
@@ -44,6 +56,23 @@ func (p *ProjectMemberRepo) ChangeAssignees(userIds []uint, projectId uint, assi
 	return tx.Commit().Error
 }
 
+func (p *ProjectMemberRepo) ChangeRole(userId uint, projectId uint, role string) error {
+	var projectMember models.ProjectMember
+	if err := p.db.First(&projectMember, "user_id = ? and project_id = ?", userId, projectId).Error; err != nil {
+		return err
+	}
+	return p.updateRole(p.db, &projectMember, role)
+}
+
+func (p *ProjectMemberRepo) updateRole(db *gorm.DB, projectMember *models.ProjectMember, role string) error {
+	return db.Model(projectMember).
+		Where("user_id = ? and project_id = ?", projectMember.UserID, projectMember.ProjectID).
+		Updates(map[string]any{
+			"role":     role,
+			"assignee": role == constant.PROJECT_ROLE_OWNER,
+		}).Error
+}
+
 func (p *ProjectMemberRepo) AddProjectAssignee(members []models.Member, projectId uint) error {
 	tx := p.db.Begin()
 	if tx.Error != nil {
@@ -70,6 +99,10 @@ func (p *ProjectMemberRepo) AddProjectAssignee(members []models.Member, projectI
 }
 
 func (p *ProjectMemberRepo) AddProjectMember(members []models.Member, projectId uint) error {
+	return p.AddProjectMemberWithRole(members, projectId, constant.PROJECT_ROLE_MEMBER)
+}
+
+func (p *ProjectMemberRepo) AddProjectMemberWithRole(members []models.Member, projectId uint, role string) error {
 	tx := p.db.Begin()
 	if tx.Error != nil {
 		return tx.Error
@@ -79,6 +112,7 @@ func (p *ProjectMemberRepo) AddProjectMember(members []models.Member, projectId
 			ProjectID: projectId,
 			UserID:    member.UserID,
 			Username:  member.Username,
+			Role:      role,
 		}
 		if err := tx.Find(&projectMember).Error; err != nil {
 			tx.Rollback()
@@ -147,6 +181,27 @@ func (p *ProjectMemberRepo) GetProjectByUserId(userId uint) ([]models.ProjectMem
 	return projectMember, err
 }
 
+func (p *ProjectMemberRepo) GetProjectCountByUserId(userId uint) (int64, error) {
+	var count int64
+	err := p.db.Model(&models.ProjectMember{}).Where("user_id = ?", userId).Count(&count).Error
+	return count, err
+}
+
+// GetMemberRole 返回用户在项目中的角色，不是项目成员时返回空字符串
+func (p *ProjectMemberRepo) GetMemberRole(projectId uint, userId uint) string {
+	var projectMember models.ProjectMember
+	if err := p.db.Find(&projectMember, "project_id = ? and user_id = ?", projectId, userId).Error; err != nil {
+		return ""
+	}
+	return projectMember.Role
+}
+
+func (p *ProjectMemberRepo) GetMemberCountByRole(projectId uint, role string) (int64, error) {
+	var count int64
+	err := p.db.Model(&models.ProjectMember{}).Where("project_id = ? and role = ?", projectId, role).Count(&count).Error
+	return count, err
+}
+
 func (p *ProjectMemberRepo) CheckAssignee(projectId uint, userId uint) bool {
 	var projectMember models.ProjectMember
 	err := p.db.First(&projectMember, "project_id = ? and user_id = ? and assignee = ?", projectId, userId, true).Error
@@ -43,6 +43,12 @@ func (p *ProjectRepo) GetProjectById(id uint) (*models.Project, error) {
 	return utils.HandleError(&project, err)
 }
 
+func (p *ProjectRepo) GetProjectsByIds(ids []uint) (*[]models.Project, error) {
+	var projects []models.Project
+	err := p.db.Find(&projects, "id IN ?", ids).Error
+	return utils.HandleError(&projects, err)
+}
+
 func (p *ProjectRepo) CreateProject(project models.Project) (*models.Project, error) {
 	err := p.db.Create(&project).Error
 	return utils.HandleError(&project, err)
@@ -44,3 +44,9 @@ func (r *ResourceRepo) GetResourceById(id uint) (*models.Resource, error) {
 	err := r.db.Find(&resource, "id = ?", id).Error
 	return utils.HandleError(&resource, err)
 }
+
+func (r *ResourceRepo) GetResourcesByStaticPaths(staticPaths []string) (*[]models.Resource, error) {
+	var resources []models.Resource
+	err := r.db.Find(&resources, "static_path IN ?", staticPaths).Error
+	return utils.HandleError(&resources, err)
+}
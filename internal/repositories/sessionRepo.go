@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"time"
+
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepo struct {
+	db *gorm.DB
+}
+
+var sessionRepo *SessionRepo
+
+func NewSessionRepo() *SessionRepo {
+	if sessionRepo == nil {
+		sessionRepo = &SessionRepo{
+			db: global.DB,
+		}
+	}
+	return sessionRepo
+}
+
+func (s *SessionRepo) CreateSession(session models.Session) (*models.Session, error) {
+	err := s.db.Create(&session).Error
+	return utils.HandleError(&session, err)
+}
+
+func (s *SessionRepo) GetSessionById(id string) (*models.Session, error) {
+	var session models.Session
+	err := s.db.Find(&session, "id = ?", id).Error
+	return utils.HandleError(&session, err)
+}
+
+func (s *SessionRepo) GetSessionByRefreshToken(namespace string, hash string) (*models.Session, error) {
+	var session models.Session
+	err := s.db.Find(&session, "namespace = ? AND refresh_token_hash = ?", namespace, hash).Error
+	return utils.HandleError(&session, err)
+}
+
+func (s *SessionRepo) GetSessionByPrevRefreshToken(namespace string, hash string) (*models.Session, error) {
+	var session models.Session
+	err := s.db.Find(&session, "namespace = ? AND prev_refresh_token_hash = ?", namespace, hash).Error
+	return utils.HandleError(&session, err)
+}
+
+// GetSessionsByUserId namespace 为空时返回用户在看板与管理端的全部会话
+func (s *SessionRepo) GetSessionsByUserId(userId uint, namespace string) (*[]models.Session, error) {
+	var sessions []models.Session
+	db := s.db.Where("user_id = ?", userId)
+	if namespace != "" {
+		db = db.Where("namespace = ?", namespace)
+	}
+	err := db.Order("last_seen_at DESC").Find(&sessions).Error
+	return utils.HandleError(&sessions, err)
+}
+
+// RotateRefreshToken 只有当前刷新令牌仍为 hash 时才会更新，返回是否更新成功
+// 同一个刷新令牌并发使用时只有一个请求能够成功
+func (s *SessionRepo) RotateRefreshToken(id string, hash string, values map[string]any) bool {
+	result := s.db.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ?", id, hash).
+		Updates(values)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func (s *SessionRepo) TouchSession(id string, ip string, now time.Time) error {
+	return s.db.Model(&models.Session{}).Where("id = ?", id).
+		UpdateColumns(map[string]any{"ip": ip, "last_seen_at": now}).Error
+}
+
+func (s *SessionRepo) DeleteSession(id string) error {
+	return s.db.Delete(&models.Session{}, "id = ?", id).Error
+}
+
+func (s *SessionRepo) DeleteSessions(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.db.Delete(&models.Session{}, "id IN ?", ids).Error
+}
+
+func (s *SessionRepo) DeleteExpiredSessions(userId uint, now time.Time) error {
+	return s.db.Delete(&models.Session{}, "user_id = ? AND expires_at <= ?", userId, now).Error
+}
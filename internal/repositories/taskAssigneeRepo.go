@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"time"
+
+	"server/internal/constant"
 	"server/internal/global"
 	"server/internal/models"
 	"server/internal/utils"
@@ -29,6 +32,18 @@ func (t *TaskAssigneeRepo) GetTaskAssigneesByProjectIdAndTankId(projectId, taskI
 	return utils.HandleError(&taskAssignee, err)
 }
 
+func (t *TaskAssigneeRepo) GetTaskAssigneesByProjectId(projectId uint) (*[]models.TaskAssignee, error) {
+	var taskAssignee []models.TaskAssignee
+	err := t.db.Find(&taskAssignee, "project_id = ?", projectId).Error
+	return utils.HandleError(&taskAssignee, err)
+}
+
+func (t *TaskAssigneeRepo) GetTaskAssigneesByTaskIds(taskIds []uint) (*[]models.TaskAssignee, error) {
+	var taskAssignee []models.TaskAssignee
+	err := t.db.Find(&taskAssignee, "task_id IN ?", taskIds).Error
+	return utils.HandleError(&taskAssignee, err)
+}
+
 func (t *TaskAssigneeRepo) GetTaskByUserIdLimt(uerId uint, page int, pageSize int) (*[]models.TaskAssignee, error) {
 	var taskAssignee []models.TaskAssignee
 	err := t.db.Limit(pageSize).Offset((page-1)*pageSize).Find(&taskAssignee, "user_id = ?", uerId).Error
@@ -113,3 +128,47 @@ func (t *TaskAssigneeRepo) GetAllAssigneeIdByTaskId(taskID uint) (*[]uint, error
 	err := t.db.Model(&taskAssignee).Where("task_id = ?", taskID).Pluck("user_id", &assigneeIds).Error
 	return &assigneeIds, err
 }
+
+type UserTaskStatistics struct {
+	TotalTasks      int64
+	DoneTasks       int64
+	InProgressTasks int64
+	LastWeekTasks   int64
+	ThisWeekTasks   int64
+	LastMonthTasks  int64
+	ThisMonthTasks  int64
+	RangeTasks      int64
+}
+
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// GetUserTaskStatistics 按完成时间统计用户负责的任务，各时间段均为 [Start, End)
+func (t *TaskAssigneeRepo) GetUserTaskStatistics(userId uint, lastWeek, thisWeek, lastMonth, thisMonth, custom TimeRange) (*UserTaskStatistics, error) {
+	var statistics UserTaskStatistics
+	completedBetween := "COALESCE(SUM(CASE WHEN tasks.status = ? AND tasks.completed_at >= ? AND tasks.completed_at < ? THEN 1 ELSE 0 END), 0)"
+	err := t.db.Model(&models.TaskAssignee{}).
+		Select(
+			"COUNT(*) AS total_tasks, "+
+				"COALESCE(SUM(CASE WHEN tasks.status = ? THEN 1 ELSE 0 END), 0) AS done_tasks, "+
+				"COALESCE(SUM(CASE WHEN tasks.status = ? THEN 1 ELSE 0 END), 0) AS in_progress_tasks, "+
+				completedBetween+" AS last_week_tasks, "+
+				completedBetween+" AS this_week_tasks, "+
+				completedBetween+" AS last_month_tasks, "+
+				completedBetween+" AS this_month_tasks, "+
+				completedBetween+" AS range_tasks",
+			constant.TASK_STATUS_DONE,
+			constant.TASK_STATUS_IN_PROGRESS,
+			constant.TASK_STATUS_DONE, lastWeek.Start, lastWeek.End,
+			constant.TASK_STATUS_DONE, thisWeek.Start, thisWeek.End,
+			constant.TASK_STATUS_DONE, lastMonth.Start, lastMonth.End,
+			constant.TASK_STATUS_DONE, thisMonth.Start, thisMonth.End,
+			constant.TASK_STATUS_DONE, custom.Start, custom.End,
+		).
+		Joins("JOIN tasks ON tasks.id = task_assignees.task_id AND tasks.deleted_at IS NULL").
+		Where("task_assignees.user_id = ?", userId).
+		Scan(&statistics).Error
+	return utils.HandleError(&statistics, err)
+}
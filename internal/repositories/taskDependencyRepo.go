@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type TaskDependencyRepo struct {
+	db *gorm.DB
+}
+
+var taskDependencyRepo *TaskDependencyRepo
+
+func NewTaskDependencyRepo() *TaskDependencyRepo {
+	if taskDependencyRepo == nil {
+		taskDependencyRepo = &TaskDependencyRepo{
+			db: global.DB,
+		}
+	}
+	return taskDependencyRepo
+}
+
+func (t *TaskDependencyRepo) GetDependenciesByProjectId(projectId uint) (*[]models.TaskDependency, error) {
+	var dependencies []models.TaskDependency
+	err := t.db.Find(&dependencies, "project_id = ?", projectId).Error
+	return utils.HandleError(&dependencies, err)
+}
+
+func (t *TaskDependencyRepo) CheckDependencyExist(taskId uint, dependsOnId uint) bool {
+	var dependency models.TaskDependency
+	count := t.db.Find(&dependency, "task_id = ? AND depends_on_id = ?", taskId, dependsOnId).RowsAffected
+	return count > 0
+}
+
+func (t *TaskDependencyRepo) CreateDependency(dependency models.TaskDependency) error {
+	return t.db.Create(&dependency).Error
+}
+
+func (t *TaskDependencyRepo) RemoveDependency(taskId uint, dependsOnId uint) error {
+	var dependency models.TaskDependency
+	if err := t.db.First(&dependency, "task_id = ? AND depends_on_id = ?", taskId, dependsOnId).Error; err != nil {
+		return err
+	}
+	return t.db.Delete(&dependency, "task_id = ? AND depends_on_id = ?", taskId, dependsOnId).Error
+}
+
+func (t *TaskDependencyRepo) DeleteDependencyByTaskId(taskId uint) error {
+	var dependency models.TaskDependency
+	return t.db.Where("task_id = ? OR depends_on_id = ?", taskId, taskId).Delete(&dependency).Error
+}
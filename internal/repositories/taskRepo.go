@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"server/internal/constant"
+	"server/internal/event"
 	"server/internal/global"
 	"server/internal/models"
 	"server/internal/utils"
@@ -38,6 +39,13 @@ func (t *TaskRepo) GetTaskByProjectId(projectId uint) (*[]models.Task, error) {
 	return utils.HandleError(&task, err)
 }
 
+// GetTaskHistoryByProjectId 包括 since 之后才删除的任务，用于统计历史数据
+func (t *TaskRepo) GetTaskHistoryByProjectId(projectId uint, since time.Time) (*[]models.Task, error) {
+	var task []models.Task
+	err := t.db.Unscoped().Find(&task, "project_id = ? AND (deleted_at IS NULL OR deleted_at >= ?)", projectId, since).Error
+	return utils.HandleError(&task, err)
+}
+
 func (t *TaskRepo) GetTaskCountByProjectId(projectId uint) (int64, error) {
 	var count int64
 	err := t.db.Model(&models.Task{}).Where("project_id = ?", projectId).Count(&count).Error
@@ -105,6 +113,13 @@ func (t *TaskRepo) GetTaskById(id uint) (*models.Task, error) {
 	return utils.HandleError(&task, err)
 }
 
+// GetTaskByIdWithDeleted 包含已删除的任务
+func (t *TaskRepo) GetTaskByIdWithDeleted(id uint) (*models.Task, error) {
+	var task models.Task
+	err := t.db.Unscoped().First(&task, id).Error
+	return utils.HandleError(&task, err)
+}
+
 func (t *TaskRepo) GetTaskByIdAndProjectId(id uint, projectId uint) (*models.Task, error) {
 	var task models.Task
 	err := t.db.First(&task, "id = ? AND project_id = ?", id, projectId).Error
@@ -112,8 +127,31 @@ func (t *TaskRepo) GetTaskByIdAndProjectId(id uint, projectId uint) (*models.Tas
 }
 
 func (t *TaskRepo) CreateTask(task models.Task) (*models.Task, error) {
-	err := t.db.Create(&task).Error
-	return utils.HandleError(&task, err)
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&task).Error; err != nil {
+			return err
+		}
+		transition := models.TaskTransition{
+			ProjectID: task.ProjectID,
+			TaskID:    task.ID,
+			ToStatus:  task.Status,
+			ActorID:   task.CreatorID,
+			CreatedAt: task.CreatedAt,
+		}
+		return tx.Create(&transition).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	createdType := constant.TASK_CREATED_EVENT
+	event.KanboardPublish(event.Event{EventType: &createdType, ProjectID: &task.ProjectID, TaskID: &task.ID, ActorID: &task.CreatorID, Status: &task.Status})
+	return &task, nil
+}
+
+// publishTaskUpdated 任务事件在写入提交后发布，订阅者读取任务时能看到最新数据
+func publishTaskUpdated(id uint, projectId uint) {
+	updatedType := constant.TASK_UPDATED_EVENT
+	event.KanboardPublish(event.Event{EventType: &updatedType, ProjectID: &projectId, TaskID: &id})
 }
 
 func (t *TaskRepo) DeleteTaskById(id uint) error {
@@ -130,24 +168,150 @@ func (t *TaskRepo) UpdateTask(values map[string]any, id uint, projectId uint) er
 	if err := t.db.First(&task, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
 		return err
 	}
-	err := t.db.Model(&task).Where("id = ?", id).Where("project_id = ?", projectId).Updates(values).Error
-	return err
+	if err := t.db.Model(&task).Where("id = ?", id).Where("project_id = ?", projectId).Updates(values).Error; err != nil {
+		return err
+	}
+	publishTaskUpdated(id, projectId)
+	return nil
 }
 
-func (t *TaskRepo) SearchTask(query map[string]any, projectId uint) (*[]models.Task, error) {
-	var tasks []models.Task
+func (t *TaskRepo) UpdateTaskStatus(id uint, projectId uint, status uint, actorId uint) error {
+	changed := false
+	err := t.db.Transaction(func(tx *gorm.DB) error {
+		var task models.Task
+		if err := tx.First(&task, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
+			return err
+		}
+		if task.Status == status {
+			return nil
+		}
+		fromStatus := task.Status
+		values := map[string]any{
+			"status":       status,
+			"completed_at": nil,
+		}
+		if status == constant.TASK_STATUS_DONE {
+			values["completed_at"] = time.Now()
+		}
+		if err := tx.Model(&task).Where("id = ?", id).Where("project_id = ?", projectId).Updates(values).Error; err != nil {
+			return err
+		}
+		transition := models.TaskTransition{
+			ProjectID:  projectId,
+			TaskID:     id,
+			FromStatus: &fromStatus,
+			ToStatus:   status,
+			ActorID:    actorId,
+		}
+		if err := tx.Create(&transition).Error; err != nil {
+			return err
+		}
+		changed = true
+		return nil
+	})
+	if err != nil || !changed {
+		return err
+	}
+	publishTaskUpdated(id, projectId)
+	statusType := constant.TASK_STATUS_CHANGED_EVENT
+	event.KanboardPublish(event.Event{EventType: &statusType, ProjectID: &projectId, TaskID: &id, ActorID: &actorId, Status: &status})
+	return nil
+}
+
+func (t *TaskRepo) RescheduleTask(id uint, projectId uint, startDate time.Time, dueDate time.Time) error {
+	var task models.Task
+	if err := t.db.First(&task, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
+		return err
+	}
+	values := map[string]any{
+		"start_date": startDate,
+		"due_date":   dueDate,
+	}
+	if err := t.db.Model(&task).Where("id = ?", id).Where("project_id = ?", projectId).Updates(values).Error; err != nil {
+		return err
+	}
+	publishTaskUpdated(id, projectId)
+	return nil
+}
+
+func (t *TaskRepo) searchScope(query map[string]any, projectId uint) *gorm.DB {
 	var task models.Task
-	ctx := t.db.Model(&task)
+	ctx := t.db.Model(&task).Where("project_id = ?", projectId)
 	if title, ok := query["title"].(string); ok {
-		ctx.Where("title LIKE ? AND project_id = ?", "%"+title+"%", projectId)
+		ctx.Where("title LIKE ?", "%"+title+"%")
 	}
 	if priority, ok := query["priority"].(int); ok {
-		ctx.Where("priority = ? AND project_id = ?", priority, projectId)
+		ctx.Where("priority = ?", priority)
 	}
 	if creatorId, ok := query["creatorId"].(uint); ok {
-		ctx.Where("creator_id = ? AND project_id = ?", creatorId, projectId)
+		ctx.Where("creator_id = ?", creatorId)
+	}
+	if userId, ok := query["userId"].(uint); ok {
+		ctx.Where("id IN (?)", t.db.Model(&models.TaskAssignee{}).Select("task_id").Where("user_id = ?", userId))
+	}
+	return ctx
+}
+
+func (t *TaskRepo) SearchTask(query map[string]any, projectId uint) (*[]models.Task, error) {
+	var tasks []models.Task
+	err := t.searchScope(query, projectId).Find(&tasks).Error
+	return utils.HandleError(&tasks, err)
+}
+
+// StreamSearchTask 分批读取查询结果，避免一次性加载整个项目的任务
+func (t *TaskRepo) StreamSearchTask(query map[string]any, projectId uint, batchSize int, fn func(tasks []models.Task) error) error {
+	var tasks []models.Task
+	return t.searchScope(query, projectId).Order("id").FindInBatches(&tasks, batchSize, func(tx *gorm.DB, batch int) error {
+		return fn(tasks)
+	}).Error
+}
+
+func (t *TaskRepo) GetCalendarTasksByProjectId(projectId uint, since time.Time) (*[]models.Task, error) {
+	var tasks []models.Task
+	err := t.db.Order("due_date, id").
+		Find(&tasks, "project_id = ? AND (due_date >= ? OR start_date >= ?)", projectId, since, since).Error
+	return utils.HandleError(&tasks, err)
+}
+
+func (t *TaskRepo) GetCalendarTasksByUserId(userId uint, since time.Time) (*[]models.Task, error) {
+	var tasks []models.Task
+	assigned := t.db.Model(&models.TaskAssignee{}).Select("task_id").Where("user_id = ?", userId)
+	err := t.db.Order("due_date, id").
+		Find(&tasks, "id IN (?) AND (due_date >= ? OR start_date >= ?)", assigned, since, since).Error
+	return utils.HandleError(&tasks, err)
+}
+
+// GetTasksInRange 查询截止时间或开始时间落在范围内、或跨越整个范围的任务
+func (t *TaskRepo) GetTasksInRange(query map[string]any, start time.Time, end time.Time) (*[]models.Task, error) {
+	var tasks []models.Task
+	ctx := t.db.Model(&models.Task{})
+	if projectId, ok := query["projectId"].(uint); ok {
+		ctx.Where("project_id = ?", projectId)
+	}
+	if userId, ok := query["userId"].(uint); ok {
+		ctx.Where("id IN (?)", t.db.Model(&models.TaskAssignee{}).Select("task_id").Where("user_id = ?", userId))
 	}
-	err := ctx.Find(&tasks).Error
+	if status, ok := query["status"].(uint); ok {
+		ctx.Where("status = ?", status)
+	}
+	err := ctx.Where("((due_date BETWEEN ? AND ?) OR (start_date BETWEEN ? AND ?) OR (start_date < ? AND due_date > ?))",
+		start, end, start, end, start, end).
+		Order("due_date, id").Find(&tasks).Error
+	return utils.HandleError(&tasks, err)
+}
+
+func (t *TaskRepo) GetDueTasksByUserId(userId uint) (*[]models.Task, error) {
+	var tasks []models.Task
+	assigned := t.db.Model(&models.TaskAssignee{}).Select("task_id").Where("user_id = ?", userId)
+	err := t.db.Order("due_date, id").Find(&tasks, "id IN (?) AND due_date IS NOT NULL", assigned).Error
+	return utils.HandleError(&tasks, err)
+}
+
+// GetOverdueTasks 查询截止时间落在 (from, to] 内且未完成的任务
+func (t *TaskRepo) GetOverdueTasks(from time.Time, to time.Time) (*[]models.Task, error) {
+	var tasks []models.Task
+	err := t.db.Order("due_date, id").
+		Find(&tasks, "due_date > ? AND due_date <= ? AND status <> ?", from, to, constant.TASK_STATUS_DONE).Error
 	return utils.HandleError(&tasks, err)
 }
 
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"time"
+
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type TaskTransitionRepo struct {
+	db *gorm.DB
+}
+
+var taskTransitionRepo *TaskTransitionRepo
+
+func NewTaskTransitionRepo() *TaskTransitionRepo {
+	if taskTransitionRepo == nil {
+		taskTransitionRepo = &TaskTransitionRepo{
+			db: global.DB,
+		}
+	}
+	return taskTransitionRepo
+}
+
+func (t *TaskTransitionRepo) GetTransitionsByProjectId(projectId uint, before time.Time) (*[]models.TaskTransition, error) {
+	var transitions []models.TaskTransition
+	err := t.db.Order("created_at, id").Find(&transitions, "project_id = ? AND created_at < ?", projectId, before).Error
+	return utils.HandleError(&transitions, err)
+}
+
+func (t *TaskTransitionRepo) GetTransitionsByTaskId(taskId uint) (*[]models.TaskTransition, error) {
+	var transitions []models.TaskTransition
+	err := t.db.Order("created_at, id").Find(&transitions, "task_id = ?", taskId).Error
+	return utils.HandleError(&transitions, err)
+}
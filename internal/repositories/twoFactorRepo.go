@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"time"
+
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepo struct {
+	db *gorm.DB
+}
+
+var twoFactorRepo *TwoFactorRepo
+
+func NewTwoFactorRepo() *TwoFactorRepo {
+	if twoFactorRepo == nil {
+		twoFactorRepo = &TwoFactorRepo{
+			db: global.DB,
+		}
+	}
+	return twoFactorRepo
+}
+
+func (t *TwoFactorRepo) GetByUserId(userId uint) (*models.TwoFactor, error) {
+	var twoFactor models.TwoFactor
+	err := t.db.Find(&twoFactor, "user_id = ?", userId).Error
+	return utils.HandleError(&twoFactor, err)
+}
+
+// SavePending 保存尚未完成绑定的密钥，已有的未绑定密钥会被替换
+func (t *TwoFactorRepo) SavePending(userId uint, secret string) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.TwoFactor{}, "user_id = ? AND enabled_at IS NULL", userId).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.TwoFactor{UserID: userId, Secret: secret}).Error
+	})
+}
+
+// UseStep 只有 step 大于最近使用的时间步时才会更新，返回是否更新成功
+func (t *TwoFactorRepo) UseStep(id uint, step int64) bool {
+	result := t.db.Model(&models.TwoFactor{}).
+		Where("id = ? AND last_step < ?", id, step).
+		UpdateColumn("last_step", step)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func (t *TwoFactorRepo) Enable(id uint, now time.Time) error {
+	return t.db.Model(&models.TwoFactor{}).Where("id = ?", id).UpdateColumn("enabled_at", now).Error
+}
+
+func (t *TwoFactorRepo) DeleteByUserId(userId uint) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", userId).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.TwoFactor{}, "user_id = ?", userId).Error
+	})
+}
+
+// ReplaceRecoveryCodes 生成新的恢复码时旧的恢复码全部作废
+func (t *TwoFactorRepo) ReplaceRecoveryCodes(userId uint, hashes []string) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.RecoveryCode{}, "user_id = ?", userId).Error; err != nil {
+			return err
+		}
+		codes := []models.RecoveryCode{}
+		for _, hash := range hashes {
+			codes = append(codes, models.RecoveryCode{UserID: userId, CodeHash: hash})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode 将恢复码标记为已使用，返回是否使用成功
+func (t *TwoFactorRepo) UseRecoveryCode(userId uint, hash string, now time.Time) bool {
+	result := t.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userId, hash).
+		UpdateColumn("used_at", now)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func (t *TwoFactorRepo) GetRemainingRecoveryCodeCount(userId uint) (int64, error) {
+	var count int64
+	err := t.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userId).Count(&count).Error
+	return count, err
+}
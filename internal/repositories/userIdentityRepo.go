@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepo struct {
+	db *gorm.DB
+}
+
+var userIdentityRepo *UserIdentityRepo
+
+func NewUserIdentityRepo() *UserIdentityRepo {
+	if userIdentityRepo == nil {
+		userIdentityRepo = &UserIdentityRepo{
+			db: global.DB,
+		}
+	}
+	return userIdentityRepo
+}
+
+func (u *UserIdentityRepo) CreateIdentity(identity models.UserIdentity) (*models.UserIdentity, error) {
+	err := u.db.Create(&identity).Error
+	return utils.HandleError(&identity, err)
+}
+
+func (u *UserIdentityRepo) GetIdentity(provider string, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := u.db.Find(&identity, "provider = ? AND subject = ?", provider, subject).Error
+	return utils.HandleError(&identity, err)
+}
+
+func (u *UserIdentityRepo) DeleteIdentityById(id uint) error {
+	return u.db.Delete(&models.UserIdentity{}, "id = ?", id).Error
+}
+
+func (u *UserIdentityRepo) DeleteIdentitiesByUserId(userId uint) error {
+	return u.db.Delete(&models.UserIdentity{}, "user_id = ?", userId).Error
+}
+
+func (u *UserIdentityRepo) HasIdentity(userId uint, provider string) (bool, error) {
+	var count int64
+	err := u.db.Model(&models.UserIdentity{}).Where("user_id = ? AND provider = ?", userId, provider).Count(&count).Error
+	return count > 0, err
+}
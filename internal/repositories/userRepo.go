@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"fmt"
 	"time"
 
 	"server/internal/constant"
@@ -55,12 +56,66 @@ func (u *UserRepo) GetUserById(id uint) (*models.User, error) {
 	return utils.HandleError(&user, err)
 }
 
+func (u *UserRepo) GetUsersByIds(ids []uint) (*[]models.User, error) {
+	var users []models.User
+	err := u.db.Find(&users, "id IN ?", ids).Error
+	return utils.HandleError(&users, err)
+}
+
+func (u *UserRepo) GetUsersByNames(usernames []string) (*[]models.User, error) {
+	var users []models.User
+	err := u.db.Find(&users, "username IN ?", usernames).Error
+	return utils.HandleError(&users, err)
+}
+
 func (u *UserRepo) GetUserByName(username string) (*models.User, error) {
 	var user models.User
 	err := u.db.Find(&user, "username = ? and loginable = ?", username, true).Error
 	return utils.HandleError(&user, err)
 }
 
+// GetAuthUserByName 包括已禁用的用户，供认证时校验密码后再判断能否登录
+func (u *UserRepo) GetAuthUserByName(username string) (*models.User, error) {
+	var user models.User
+	err := u.db.Find(&user, "username = ?", username).Error
+	return utils.HandleError(&user, err)
+}
+
+func (u *UserRepo) GetUserByEmail(email string) (*models.User, error) {
+	var user models.User
+	err := u.db.Limit(1).Find(&user, "email = ? and loginable = ?", email, true).Error
+	return utils.HandleError(&user, err)
+}
+
+// GetUnverifiedUsers 返回 ids 中邮箱尚未验证的用户
+func (u *UserRepo) GetUnverifiedUsers(ids []uint) (*[]models.User, error) {
+	var users []models.User
+	if len(ids) == 0 {
+		return &users, nil
+	}
+	err := u.db.Find(&users, "id IN ? AND email_verified_at IS NULL", ids).Error
+	return utils.HandleError(&users, err)
+}
+
+// CheckMembersVerified 开启邮箱验证要求时，未验证邮箱的用户不能加入项目
+func (u *UserRepo) CheckMembersVerified(members []models.Member) error {
+	if constant.MailConfig.RequireVerification == "" || len(members) == 0 {
+		return nil
+	}
+	ids := []uint{}
+	for _, member := range members {
+		ids = append(ids, member.UserID)
+	}
+	users, err := u.GetUnverifiedUsers(ids)
+	if err != nil {
+		return err
+	}
+	if len(*users) > 0 {
+		return fmt.Errorf("用户『%s』邮箱未验证，不能加入项目", (*users)[0].Username)
+	}
+	return nil
+}
+
 func (u *UserRepo) GetUserCount() int64 {
 	var count int64
 	u.db.Model(&models.User{}).Count(&count)
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"time"
+
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepo struct {
+	db *gorm.DB
+}
+
+var webhookRepo *WebhookRepo
+
+func NewWebhookRepo() *WebhookRepo {
+	if webhookRepo == nil {
+		webhookRepo = &WebhookRepo{
+			db: global.DB,
+		}
+	}
+	return webhookRepo
+}
+
+func (w *WebhookRepo) CreateWebhook(webhook models.Webhook) (*models.Webhook, error) {
+	err := w.db.Create(&webhook).Error
+	return utils.HandleError(&webhook, err)
+}
+
+func (w *WebhookRepo) UpdateWebhook(values map[string]any, id uint, projectId uint) error {
+	var webhook models.Webhook
+	if err := w.db.First(&webhook, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
+		return err
+	}
+	return w.db.Model(&webhook).Updates(values).Error
+}
+
+func (w *WebhookRepo) DeleteWebhook(id uint, projectId uint) error {
+	var webhook models.Webhook
+	if err := w.db.First(&webhook, "id = ? AND project_id = ?", id, projectId).Error; err != nil {
+		return err
+	}
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.WebhookDelivery{}, "webhook_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&webhook).Error
+	})
+}
+
+func (w *WebhookRepo) DeleteWebhooksByProjectId(projectId uint) error {
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.WebhookDelivery{}, "project_id = ?", projectId).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Webhook{}, "project_id = ?", projectId).Error
+	})
+}
+
+func (w *WebhookRepo) GetWebhookById(id uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := w.db.Find(&webhook, "id = ?", id).Error
+	return utils.HandleError(&webhook, err)
+}
+
+func (w *WebhookRepo) GetWebhookByIdAndProjectId(id uint, projectId uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	err := w.db.Find(&webhook, "id = ? AND project_id = ?", id, projectId).Error
+	return utils.HandleError(&webhook, err)
+}
+
+func (w *WebhookRepo) GetWebhooksByProjectId(projectId uint) (*[]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := w.db.Order("id").Find(&webhooks, "project_id = ?", projectId).Error
+	return utils.HandleError(&webhooks, err)
+}
+
+func (w *WebhookRepo) GetEnabledWebhooks(projectId uint) (*[]models.Webhook, error) {
+	var webhooks []models.Webhook
+	err := w.db.Order("id").Find(&webhooks, "project_id = ? AND enabled = ?", projectId, true).Error
+	return utils.HandleError(&webhooks, err)
+}
+
+func (w *WebhookRepo) CreateDelivery(delivery models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	err := w.db.Create(&delivery).Error
+	return utils.HandleError(&delivery, err)
+}
+
+func (w *WebhookRepo) GetDeliveryById(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	err := w.db.Find(&delivery, "id = ?", id).Error
+	return utils.HandleError(&delivery, err)
+}
+
+func (w *WebhookRepo) GetDeliveryCountByWebhookId(webhookId uint) (int64, error) {
+	var count int64
+	err := w.db.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookId).Count(&count).Error
+	return count, err
+}
+
+func (w *WebhookRepo) GetDeliveriesByWebhookId(webhookId uint, page int, pageSize int) (*[]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := w.db.Order("id DESC").Limit(pageSize).Offset((page-1)*pageSize).Find(&deliveries, "webhook_id = ?", webhookId).Error
+	return utils.HandleError(&deliveries, err)
+}
+
+func (w *WebhookRepo) GetDueDeliveryIds(now time.Time, limit int) ([]uint, error) {
+	ids := []uint{}
+	err := w.db.Model(&models.WebhookDelivery{}).
+		Where("status = ? AND next_retry_at <= ?", constant.WEBHOOK_DELIVERY_PENDING, now).
+		Order("next_retry_at").Limit(limit).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// ClaimDelivery 将待投递记录的下次重试时间推迟到 leaseUntil，返回是否抢占成功
+// 多实例同时扫描时只有一个实例能够抢占到同一条记录
+func (w *WebhookRepo) ClaimDelivery(id uint, now time.Time, leaseUntil time.Time) bool {
+	result := w.db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ? AND next_retry_at <= ?", id, constant.WEBHOOK_DELIVERY_PENDING, now).
+		UpdateColumn("next_retry_at", leaseUntil)
+	return result.Error == nil && result.RowsAffected == 1
+}
+
+func (w *WebhookRepo) UpdateDelivery(values map[string]any, id uint) error {
+	return w.db.Model(&models.WebhookDelivery{}).Where("id = ?", id).Updates(values).Error
+}
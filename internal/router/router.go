@@ -38,6 +38,11 @@ func initRoutes(router *gin.Engine) {
 
 func getRouter() *gin.Engine {
 	router := gin.New()
+	// 登录失败计数等按客户端 IP 统计，不能信任任意来源的 X-Forwarded-For
+	if err := router.SetTrustedProxies(constant.ServerConfig.TrustedProxies); err != nil {
+		global.Logger.Error(err)
+		panic(err)
+	}
 
 	router.Use(
 		gin.Logger(),
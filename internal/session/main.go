@@ -0,0 +1,326 @@
+package session
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/pkg/crypto"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	SESSION_ID_SIZE    = 16
+	REFRESH_TOKEN_SIZE = 32
+	DEVICE_MAX_LENGTH  = 255
+	// 最近活跃时间的写库间隔
+	TOUCH_INTERVAL = time.Minute
+	// 账号是否可登录的缓存时间，直接修改数据库时最多延迟该时间生效
+	LOGINABLE_CACHE_TTL = time.Minute
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("登录已失效，请重新登录")
+	ErrUserDisabled        = errors.New("账号已被禁用")
+)
+
+// Client 为发起登录或刷新的设备
+type Client struct {
+	Device string
+	IP     string
+}
+
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// access token 的有效秒数
+	ExpiresIn int `json:"expires_in"`
+}
+
+// NewClient 未填写设备名称时使用 User-Agent
+func NewClient(ctx *gin.Context, device string) Client {
+	if device == "" {
+		device = ctx.Request.UserAgent()
+	}
+	for len(device) > DEVICE_MAX_LENGTH {
+		_, size := utf8.DecodeLastRuneInString(device)
+		device = device[:len(device)-size]
+	}
+	return Client{Device: device, IP: ctx.ClientIP()}
+}
+
+// 会话有效期，即刷新令牌的有效期
+func lifetime(namespace string) time.Duration {
+	if namespace == constant.ADMIN_TOKEN {
+		return constant.JWTConfig.AdminTokenExpiration
+	}
+	return constant.JWTConfig.KanboardTokenExpiration
+}
+
+func newTokenPair(namespace string, user *models.User, sessionId string, refreshToken string) (*TokenPair, error) {
+	var token string
+	var err error
+	if namespace == constant.ADMIN_TOKEN {
+		token, err = crypto.GenerateJWTToAdmin(user.ID, user.Username, sessionId)
+	} else {
+		token, err = crypto.GenerateJWTToKanboard(user.ID, user.Username, sessionId)
+	}
+	if err != nil {
+		return nil, errors.New("token生成失败")
+	}
+	return &TokenPair{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(constant.JWTConfig.AccessTokenExpiration.Seconds()),
+	}, nil
+}
+
+// CheckLoginable 账号被禁用时返回的错误附带禁用原因
+func CheckLoginable(user *models.User) error {
+	if user.Loginable {
+		return nil
+	}
+	if user.LockReason != "" {
+		return errors.New(ErrUserDisabled.Error() + "：" + user.LockReason)
+	}
+	return ErrUserDisabled
+}
+
+// IsLoginable 供鉴权中间件在每次请求时检查账号未被禁用，结果缓存 LOGINABLE_CACHE_TTL
+func IsLoginable(userId uint) bool {
+	key := strconv.Itoa(int(userId))
+	if cached, ok := global.Redis.Get(constant.USER_LOGINABLE, key).(string); ok && cached != "" {
+		return cached == "1"
+	}
+	loginable := false
+	user, err := repositories.NewUserRepo().GetUserById(userId)
+	if err == nil {
+		loginable = user.ID != 0 && user.Loginable
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		// 数据库不可用时不缓存，也不因此拒绝请求
+		return true
+	}
+	value := "0"
+	if loginable {
+		value = "1"
+	}
+	global.Redis.Set(constant.USER_LOGINABLE, key, value, LOGINABLE_CACHE_TTL)
+	return loginable
+}
+
+// ForgetLoginable 启用或禁用账号后清除缓存，使其立即生效
+func ForgetLoginable(userId uint) {
+	global.Redis.Delete(constant.USER_LOGINABLE, strconv.Itoa(int(userId)))
+}
+
+// Issue 为登录成功的用户创建新会话，已有的其他设备会话不受影响
+func Issue(namespace string, user *models.User, client Client) (*TokenPair, error) {
+	if err := CheckLoginable(user); err != nil {
+		return nil, err
+	}
+	sessionRepo := repositories.NewSessionRepo()
+	now := time.Now()
+	if err := sessionRepo.DeleteExpiredSessions(user.ID, now); err != nil {
+		global.Logger.Errorw("delete expired sessions error", "error", err)
+	}
+
+	sessionId, err := crypto.GenerateRandomToken(SESSION_ID_SIZE)
+	if err != nil {
+		return nil, errors.New("token生成失败")
+	}
+	refreshToken, err := crypto.GenerateRandomToken(REFRESH_TOKEN_SIZE)
+	if err != nil {
+		return nil, errors.New("token生成失败")
+	}
+
+	expiration := lifetime(namespace)
+	_, err = sessionRepo.CreateSession(models.Session{
+		ID:               sessionId,
+		UserID:           user.ID,
+		Namespace:        namespace,
+		Device:           client.Device,
+		IP:               client.IP,
+		RefreshTokenHash: crypto.HashToken(refreshToken),
+		ExpiresAt:        now.Add(expiration),
+		LastSeenAt:       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	global.Redis.Set(namespace, sessionId, strconv.Itoa(int(user.ID)), expiration)
+
+	return newTokenPair(namespace, user, sessionId, refreshToken)
+}
+
+// Refresh 使用刷新令牌换取新的令牌对，旧的刷新令牌随即失效
+// 已轮换掉的刷新令牌再次出现说明令牌可能被盗用，直接注销整个会话
+func Refresh(namespace string, refreshToken string, client Client) (*TokenPair, error) {
+	sessionRepo := repositories.NewSessionRepo()
+	hash := crypto.HashToken(refreshToken)
+
+	session, err := sessionRepo.GetSessionByRefreshToken(namespace, hash)
+	if err != nil {
+		return nil, err
+	}
+	if session.ID == "" {
+		reused, err := sessionRepo.GetSessionByPrevRefreshToken(namespace, hash)
+		if err == nil && reused.ID != "" {
+			global.Logger.Warnw("refresh token reused, revoke session", "session", reused.ID, "user", reused.UserID)
+			Revoke(reused)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	now := time.Now()
+	if !session.ExpiresAt.After(now) {
+		Revoke(session)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := repositories.NewUserRepo().GetUserById(session.UserID)
+	if err != nil || user.ID == 0 {
+		Revoke(session)
+		return nil, ErrInvalidRefreshToken
+	}
+	if err := CheckLoginable(user); err != nil {
+		Revoke(session)
+		return nil, err
+	}
+
+	newRefreshToken, err := crypto.GenerateRandomToken(REFRESH_TOKEN_SIZE)
+	if err != nil {
+		return nil, errors.New("token生成失败")
+	}
+	expiration := lifetime(namespace)
+	rotated := sessionRepo.RotateRefreshToken(session.ID, hash, map[string]any{
+		"refresh_token_hash":      crypto.HashToken(newRefreshToken),
+		"prev_refresh_token_hash": hash,
+		"expires_at":              now.Add(expiration),
+		"last_seen_at":            now,
+		"ip":                      client.IP,
+	})
+	if !rotated {
+		return nil, ErrInvalidRefreshToken
+	}
+	global.Redis.Set(namespace, session.ID, strconv.Itoa(int(user.ID)), expiration)
+
+	return newTokenPair(namespace, user, session.ID, newRefreshToken)
+}
+
+// Validate 检查 access token 未被吊销且所属的会话仍然有效
+func Validate(namespace string, claims *crypto.JWTClaims) bool {
+	if claims.SessionID == "" || IsTokenRevoked(claims.RegisteredClaims.ID) {
+		return false
+	}
+	userId, _ := global.Redis.Get(namespace, claims.SessionID).(string)
+	return userId != "" && userId == strconv.Itoa(int(claims.ID))
+}
+
+// Touch 记录会话最近的活跃时间与 IP，间隔 TOUCH_INTERVAL 才写库
+func Touch(sessionId string, ip string) {
+	if !global.Redis.SetNX(constant.SESSION_LAST_SEEN, sessionId, ip, TOUCH_INTERVAL) {
+		return
+	}
+	if err := repositories.NewSessionRepo().TouchSession(sessionId, ip, time.Now()); err != nil {
+		global.Logger.Errorw("touch session error", "error", err)
+	}
+}
+
+func Get(sessionId string) (*models.Session, error) {
+	return repositories.NewSessionRepo().GetSessionById(sessionId)
+}
+
+// List namespace 为空时返回用户的全部会话
+func List(userId uint, namespace string) (*[]models.Session, error) {
+	return repositories.NewSessionRepo().GetSessionsByUserId(userId, namespace)
+}
+
+// IsActive 供长连接检查所属的会话与令牌是否仍然有效，且账号未被禁用
+func IsActive(sessionId string, tokenId string) bool {
+	if IsTokenRevoked(tokenId) {
+		return false
+	}
+	session, err := repositories.NewSessionRepo().GetSessionById(sessionId)
+	return err == nil && session.ID != "" && IsLoginable(session.UserID)
+}
+
+func IsTokenRevoked(tokenId string) bool {
+	if tokenId == "" {
+		return false
+	}
+	revoked, _ := global.Redis.Get(constant.REVOKED_TOKEN, tokenId).(string)
+	return revoked != ""
+}
+
+// RevokeToken 将令牌加入吊销列表，保留到令牌原本的过期时间
+func RevokeToken(tokenId string, userId uint, expiresAt time.Time) {
+	ttl := time.Until(expiresAt)
+	if tokenId == "" || ttl <= 0 {
+		return
+	}
+	global.Redis.Set(constant.REVOKED_TOKEN, tokenId, strconv.Itoa(int(userId)), ttl)
+	closeConnections(userId)
+}
+
+// Logout 注销当前会话并吊销当前令牌
+func Logout(userId uint, sessionId string, tokenId string) error {
+	current, err := Get(sessionId)
+	if err != nil {
+		return err
+	}
+	if current.ID != "" && current.UserID == userId {
+		if err := Revoke(current); err != nil {
+			return err
+		}
+	}
+	RevokeToken(tokenId, userId, time.Now().Add(constant.JWTConfig.AccessTokenExpiration))
+	return nil
+}
+
+// closeConnections 通知用户在各实例上的 WebSocket 连接重新校验，失效的连接会被关闭
+func closeConnections(userId uint) {
+	messageRepo := repositories.NewMessageRepo()
+	messageRepo.PublishMsg(constant.CLOSE_CONNECTION, userId, constant.KANBOARD_MESSAGE_CHANNEL)
+	messageRepo.PublishMsg(constant.CLOSE_CONNECTION, userId, constant.ADMIN_MESSAGE_CHANNEL)
+}
+
+// Revoke 注销会话，该会话签发的 access token 立即失效
+func Revoke(session *models.Session) error {
+	global.Redis.Delete(session.Namespace, session.ID)
+	if err := repositories.NewSessionRepo().DeleteSession(session.ID); err != nil {
+		return err
+	}
+	closeConnections(session.UserID)
+	return nil
+}
+
+// RevokeAll 注销用户的会话，namespace 为空时包括看板与管理端，exceptId 为保留的会话
+func RevokeAll(userId uint, namespace string, exceptId string) error {
+	sessionRepo := repositories.NewSessionRepo()
+	sessions, err := sessionRepo.GetSessionsByUserId(userId, namespace)
+	if err != nil {
+		return err
+	}
+	ids := []string{}
+	for _, session := range *sessions {
+		if session.ID == exceptId {
+			continue
+		}
+		global.Redis.Delete(session.Namespace, session.ID)
+		ids = append(ids, session.ID)
+	}
+	if err := sessionRepo.DeleteSessions(ids); err != nil {
+		return err
+	}
+	if len(ids) > 0 {
+		closeConnections(userId)
+	}
+	return nil
+}
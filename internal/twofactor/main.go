@@ -0,0 +1,315 @@
+package twofactor
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"server/internal/constant"
+	"server/internal/global"
+	"server/internal/lockout"
+	"server/internal/models"
+	"server/internal/repositories"
+	"server/internal/session"
+	"server/pkg/crypto"
+	"server/pkg/totp"
+)
+
+const (
+	RECOVERY_CODE_COUNT = 10
+	// 恢复码为 10 位十六进制字符，展示为 xxxxx-xxxxx
+	RECOVERY_CODE_SIZE = 5
+
+	CHALLENGE_TOKEN_SIZE   = 32
+	CHALLENGE_EXPIRATION   = 5 * time.Minute
+	CHALLENGE_MAX_ATTEMPTS = 5
+)
+
+var (
+	ErrInvalidCode      = errors.New("验证码错误")
+	ErrChallengeExpired = errors.New("验证已过期，请重新登录")
+)
+
+type Setup struct {
+	Secret string `json:"secret"`
+	// otpauth:// 链接，用于生成二维码
+	URI string `json:"uri"`
+}
+
+type Status struct {
+	Enabled       bool  `json:"enabled"`
+	Required      bool  `json:"required"`
+	RecoveryCodes int64 `json:"recovery_codes"`
+}
+
+// Challenge 密码校验通过后返回，凭此完成第二步登录
+// SetupRequired 为 true 时用户尚未绑定，需要先获取密钥完成绑定
+type Challenge struct {
+	Token         string `json:"challenge"`
+	ExpiresIn     int    `json:"expires_in"`
+	SetupRequired bool   `json:"setup_required"`
+}
+
+type pendingLogin struct {
+	UserID        uint   `json:"user_id"`
+	Namespace     string `json:"namespace"`
+	Device        string `json:"device"`
+	IP            string `json:"ip"`
+	SetupRequired bool   `json:"setup_required"`
+}
+
+// Required 按策略该用户是否必须开启两步验证
+func Required(user *models.User) bool {
+	return user.IsAdmin && constant.TwoFactorConfig.RequireAdmin
+}
+
+func getEnabled(userId uint) (*models.TwoFactor, bool) {
+	twoFactor, err := repositories.NewTwoFactorRepo().GetByUserId(userId)
+	if err != nil || twoFactor.ID == 0 || twoFactor.EnabledAt == nil {
+		return nil, false
+	}
+	return twoFactor, true
+}
+
+func getUser(userId uint) (*models.User, error) {
+	user, err := repositories.NewUserRepo().GetUserById(userId)
+	if err != nil || user.ID == 0 {
+		return nil, errors.New("用户不存在")
+	}
+	return user, nil
+}
+
+func GetStatus(userId uint) (*Status, error) {
+	user, err := getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{Required: Required(user)}
+	if _, ok := getEnabled(user.ID); !ok {
+		return status, nil
+	}
+	count, err := repositories.NewTwoFactorRepo().GetRemainingRecoveryCodeCount(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	status.Enabled = true
+	status.RecoveryCodes = count
+	return status, nil
+}
+
+// Begin 再次校验密码后生成新的密钥，使用 Enable 提交验证码后才会生效
+func Begin(userId uint, password string) (*Setup, error) {
+	user, err := getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	if !crypto.CheckPasswordHash(user.Password, password) {
+		return nil, errors.New("密码错误")
+	}
+	return begin(user)
+}
+
+func begin(user *models.User) (*Setup, error) {
+	if _, ok := getEnabled(user.ID); ok {
+		return nil, errors.New("已开启两步验证")
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("密钥生成失败")
+	}
+	if err := repositories.NewTwoFactorRepo().SavePending(user.ID, secret); err != nil {
+		return nil, err
+	}
+	return &Setup{
+		Secret: secret,
+		URI:    totp.ProvisioningURI(constant.TwoFactorConfig.Issuer, user.Username, secret),
+	}, nil
+}
+
+// Enable 校验验证器生成的验证码并开启两步验证，返回只展示一次的恢复码
+func Enable(userId uint, code string) ([]string, error) {
+	user, err := getUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	return enable(user, code)
+}
+
+func enable(user *models.User, code string) ([]string, error) {
+	twoFactorRepo := repositories.NewTwoFactorRepo()
+	twoFactor, err := twoFactorRepo.GetByUserId(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if twoFactor.ID == 0 {
+		return nil, errors.New("请先生成两步验证密钥")
+	}
+	if twoFactor.EnabledAt != nil {
+		return nil, errors.New("已开启两步验证")
+	}
+	if err := verifyTOTP(twoFactor, code); err != nil {
+		return nil, err
+	}
+	if err := twoFactorRepo.Enable(twoFactor.ID, time.Now()); err != nil {
+		return nil, err
+	}
+	return generateRecoveryCodes(user.ID)
+}
+
+// Disable 按策略必须开启两步验证的用户不能关闭
+func Disable(userId uint, code string) error {
+	user, err := getUser(userId)
+	if err != nil {
+		return err
+	}
+	if Required(user) {
+		return errors.New("管理员必须开启两步验证")
+	}
+	if err := Verify(user.ID, code); err != nil {
+		return err
+	}
+	return repositories.NewTwoFactorRepo().DeleteByUserId(user.ID)
+}
+
+func RegenerateRecoveryCodes(userId uint, code string) ([]string, error) {
+	if err := Verify(userId, code); err != nil {
+		return nil, err
+	}
+	return generateRecoveryCodes(userId)
+}
+
+// Verify 校验验证码或恢复码，均只能使用一次
+func Verify(userId uint, code string) error {
+	twoFactor, ok := getEnabled(userId)
+	if !ok {
+		return errors.New("未开启两步验证")
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == totp.DIGITS {
+		return verifyTOTP(twoFactor, code)
+	}
+
+	normalized := strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	if !repositories.NewTwoFactorRepo().UseRecoveryCode(userId, crypto.HashToken(normalized), time.Now()) {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+func verifyTOTP(twoFactor *models.TwoFactor, code string) error {
+	step, ok := totp.Validate(twoFactor.Secret, code, time.Now())
+	if !ok || !repositories.NewTwoFactorRepo().UseStep(twoFactor.ID, step) {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+func generateRecoveryCodes(userId uint) ([]string, error) {
+	codes := []string{}
+	hashes := []string{}
+	for range RECOVERY_CODE_COUNT {
+		code, err := crypto.GenerateRandomToken(RECOVERY_CODE_SIZE)
+		if err != nil {
+			return nil, errors.New("恢复码生成失败")
+		}
+		codes = append(codes, code[:RECOVERY_CODE_SIZE]+"-"+code[RECOVERY_CODE_SIZE:])
+		hashes = append(hashes, crypto.HashToken(code))
+	}
+	if err := repositories.NewTwoFactorRepo().ReplaceRecoveryCodes(userId, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// LoginChallenge 密码校验通过后调用，不需要两步验证时返回 nil
+func LoginChallenge(user *models.User, namespace string, client session.Client) (*Challenge, error) {
+	_, enabled := getEnabled(user.ID)
+	if !enabled && !Required(user) {
+		return nil, nil
+	}
+
+	token, err := crypto.GenerateRandomToken(CHALLENGE_TOKEN_SIZE)
+	if err != nil {
+		return nil, errors.New("token生成失败")
+	}
+	data, err := json.Marshal(pendingLogin{
+		UserID:        user.ID,
+		Namespace:     namespace,
+		Device:        client.Device,
+		IP:            client.IP,
+		SetupRequired: !enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+	global.Redis.Set(constant.TWO_FACTOR_CHALLENGE, crypto.HashToken(token), string(data), CHALLENGE_EXPIRATION)
+
+	return &Challenge{
+		Token:         token,
+		ExpiresIn:     int(CHALLENGE_EXPIRATION.Seconds()),
+		SetupRequired: !enabled,
+	}, nil
+}
+
+func getChallenge(namespace string, token string) (*pendingLogin, *models.User, error) {
+	value, _ := global.Redis.Get(constant.TWO_FACTOR_CHALLENGE, crypto.HashToken(token)).(string)
+	var pending pendingLogin
+	if value == "" || json.Unmarshal([]byte(value), &pending) != nil || pending.Namespace != namespace {
+		return nil, nil, ErrChallengeExpired
+	}
+	user, err := repositories.NewUserRepo().GetUserById(pending.UserID)
+	if err != nil || user.ID == 0 {
+		return nil, nil, ErrChallengeExpired
+	}
+	return &pending, user, nil
+}
+
+// SetupChallenge 尚未绑定的用户在登录过程中获取密钥
+func SetupChallenge(namespace string, token string) (*Setup, error) {
+	pending, user, err := getChallenge(namespace, token)
+	if err != nil {
+		return nil, err
+	}
+	if !pending.SetupRequired {
+		return nil, errors.New("已开启两步验证")
+	}
+	return begin(user)
+}
+
+// CompleteChallenge 校验第二步的验证码，成功后返回用户与发起登录的设备
+// 首次绑定时同时返回恢复码，超过尝试次数后需要重新登录
+// 验证码错误按用户累计，不随重新登录清零，次数过多时账号被临时锁定
+func CompleteChallenge(namespace string, token string, code string) (*models.User, session.Client, []string, error) {
+	pending, user, err := getChallenge(namespace, token)
+	if err != nil {
+		return nil, session.Client{}, nil, err
+	}
+	hash := crypto.HashToken(token)
+	if err := lockout.Check(user.Username, ""); err != nil {
+		global.Redis.Delete(constant.TWO_FACTOR_CHALLENGE, hash)
+		return nil, session.Client{}, nil, err
+	}
+	if global.Redis.Incr(constant.TWO_FACTOR_ATTEMPTS, hash, CHALLENGE_EXPIRATION) > CHALLENGE_MAX_ATTEMPTS {
+		global.Redis.Delete(constant.TWO_FACTOR_CHALLENGE, hash)
+		return nil, session.Client{}, nil, ErrChallengeExpired
+	}
+
+	var recoveryCodes []string
+	if pending.SetupRequired {
+		recoveryCodes, err = enable(user, code)
+	} else {
+		err = Verify(user.ID, code)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidCode) {
+			lockout.FailTwoFactor(user.Username, pending.IP)
+		}
+		return nil, session.Client{}, nil, err
+	}
+	lockout.SucceedTwoFactor(user.Username)
+
+	global.Redis.Delete(constant.TWO_FACTOR_CHALLENGE, hash)
+	global.Redis.Delete(constant.TWO_FACTOR_ATTEMPTS, hash)
+	return user, session.Client{Device: pending.Device, IP: pending.IP}, recoveryCodes, nil
+}
@@ -13,12 +13,117 @@ type Server struct {
 	Host    string
 	Port    string
 	Timeout time.Duration
+	// 只信任这些反向代理传递的 X-Forwarded-For，为空时使用连接的来源地址
+	TrustedProxies []string
 }
 
 type JWT struct {
 	Secret                  string
+	Issuer                  string
+	ActiveKid               string
+	Keys                    []JWTKey
 	KanboardTokenExpiration time.Duration
 	AdminTokenExpiration    time.Duration
+	AccessTokenExpiration   time.Duration
+}
+
+// JWTKey 为签名密钥环中的一把密钥
+// HS256 使用 Secret，EdDSA 与 RS256 从 PEM 文件加载，只配置公钥的密钥仅用于校验
+type JWTKey struct {
+	Kid        string
+	Algorithm  string
+	Secret     string
+	PrivateKey string
+	PublicKey  string
+}
+
+type Statistics struct {
+	WeekStart time.Weekday
+}
+
+type Calendar struct {
+	FeedURL  string
+	TaskLink string
+	PastDays int
+	Refresh  time.Duration
+}
+
+type Mail struct {
+	// smtp 或 log，log 只将邮件内容写入日志
+	Transport  string
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	From       string
+	ResetLink  string
+	VerifyLink string
+	// 为空时不要求验证邮箱，login 为登录前必须验证，project 为加入项目前必须验证
+	RequireVerification string
+}
+
+type TwoFactor struct {
+	// 验证器应用中显示的发行方名称
+	Issuer string
+	// 管理员必须开启两步验证
+	RequireAdmin bool
+}
+
+type OIDC struct {
+	Enabled      bool
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// 前端回调页面地址，需要在身份提供方登记
+	RedirectURL string
+	Scopes      []string
+	Timeout     time.Duration
+	// 声明名称，支持以 . 分隔的嵌套声明
+	UsernameClaim string
+	EmailClaim    string
+	GroupsClaim   string
+	// GroupsClaim 不为空时，属于其中任一分组的用户为管理员，每次登录同步
+	AdminGroups []string
+	// 首次登录时关联邮箱已验证且相同的本地用户
+	LinkByEmail bool
+}
+
+type LDAP struct {
+	Enabled            bool
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	BindDN             string
+	BindPassword       string
+	BaseDN             string
+	// {username} 为登录时输入的用户名
+	UserFilter        string
+	UsernameAttribute string
+	EmailAttribute    string
+	// 为空时不同步管理员，否则每次登录按是否为该分组成员同步
+	AdminGroupDN string
+	// 目录认证失败时继续校验本地密码，已关联目录账号的用户除外
+	LocalFallback bool
+	// 首次登录时关联同名的本地用户
+	LinkExisting bool
+}
+
+type Webhook struct {
+	// 允许投递到本机与内网地址，仅用于本地调试
+	AllowPrivateNetwork bool
+}
+
+type Lockout struct {
+	// 时间窗口内同一用户名失败达到该次数后锁定，为 0 时不锁定
+	MaxFailures int
+	// 时间窗口内同一 IP 失败达到该次数后锁定该 IP，为 0 时不锁定
+	IPMaxFailures int
+	// 失败达到该次数后，每次失败需等待的时间翻倍，最长为 MaxDelay
+	DelayAfter int
+	MaxDelay   time.Duration
+	Window     time.Duration
+	Duration   time.Duration
 }
 
 type File struct {
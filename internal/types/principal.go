@@ -0,0 +1,17 @@
+package types
+
+// Principal 为通过鉴权的请求所代表的用户
+type Principal struct {
+	UserID   uint
+	Username string
+	// 管理员代为操作时为管理员，否则与 UserID 相同
+	ActorID   uint
+	ActorName string
+	OnBehalf  bool
+	// 当前请求的登录会话，管理员代为操作时为管理员的会话
+	SessionID string
+	// 当前请求令牌的 JWT ID
+	TokenID string
+	// 通过个人访问令牌鉴权时为令牌 ID，此时没有登录会话
+	AccessTokenID uint
+}
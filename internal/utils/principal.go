@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"server/internal/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+const PRINCIPAL_KEY = "principal"
+
+func SetPrincipal(ctx *gin.Context, principal *types.Principal) {
+	ctx.Set(PRINCIPAL_KEY, principal)
+}
+
+// GetPrincipal 未经过鉴权中间件的请求返回 nil
+func GetPrincipal(ctx *gin.Context) *types.Principal {
+	value, ok := ctx.Get(PRINCIPAL_KEY)
+	if !ok {
+		return nil
+	}
+	principal, _ := value.(*types.Principal)
+	return principal
+}
+
+// GetUserId 返回当前请求代表的用户 ID
+func GetUserId(ctx *gin.Context) uint {
+	if principal := GetPrincipal(ctx); principal != nil {
+		return principal.UserID
+	}
+	return 0
+}
+
+// GetSessionId 返回当前请求的登录会话
+func GetSessionId(ctx *gin.Context) string {
+	if principal := GetPrincipal(ctx); principal != nil {
+		return principal.SessionID
+	}
+	return ""
+}
@@ -11,39 +11,61 @@ import (
 type JWTClaims struct {
 	ID       uint
 	Username string
+	// 签发该令牌的登录会话
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
-var secret = []byte(constant.JWTConfig.Secret)
+var errKeyRing = errors.New("jwt key ring not initialized")
 
-func GenerateJWT(id uint, username string, expiresAt time.Duration, subject string) (string, error) {
+// GenerateJWT 每个令牌带有随机的 JWT ID（jti），用于单独吊销
+// audience 与 subject 区分看板与管理端令牌
+func GenerateJWT(id uint, username string, sessionId string, expiresAt time.Duration, audience string, subject string) (string, error) {
+	if ring == nil {
+		return "", errKeyRing
+	}
+	tokenId, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
 	claims := JWTClaims{
-		ID:       id,
-		Username: username,
+		ID:        id,
+		Username:  username,
+		SessionID: sessionId,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			Issuer:    constant.JWTConfig.Issuer,
+			Audience:  jwt.ClaimStrings{audience},
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresAt)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Subject:   subject,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secret)
+	return ring.sign(claims)
 }
 
-func GenerateJWTToKanboard(id uint, username string) (string, error) {
-	return GenerateJWT(id, username, constant.JWTConfig.KanboardTokenExpiration, constant.KANBOARD_SUBJECT)
+func GenerateJWTToKanboard(id uint, username string, sessionId string) (string, error) {
+	return GenerateJWT(id, username, sessionId, constant.JWTConfig.AccessTokenExpiration, constant.KANBOARD_AUDIENCE, constant.KANBOARD_SUBJECT)
 }
 
-func GenerateJWTToAdmin(id uint, username string) (string, error) {
-	return GenerateJWT(id, username, constant.JWTConfig.AdminTokenExpiration, constant.ADMIN_SUBJECT)
+func GenerateJWTToAdmin(id uint, username string, sessionId string) (string, error) {
+	return GenerateJWT(id, username, sessionId, constant.JWTConfig.AccessTokenExpiration, constant.ADMIN_AUDIENCE, constant.ADMIN_SUBJECT)
 }
 
-func ParseToken(tokenStr string) (*JWTClaims, error) {
+// ParseToken 校验签名、有效期以及签发者、受众与主题
+func ParseToken(tokenStr string, audience string, subject string) (*JWTClaims, error) {
 	var claims JWTClaims
-	token, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (interface{}, error) {
-		return secret, nil
-	})
+	if ring == nil {
+		return &claims, errKeyRing
+	}
+	token, err := jwt.ParseWithClaims(tokenStr, &claims, ring.lookup,
+		jwt.WithValidMethods(ring.methods),
+		jwt.WithIssuer(constant.JWTConfig.Issuer),
+		jwt.WithAudience(audience),
+		jwt.WithSubject(subject),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err == nil && !token.Valid {
 		err = errors.New("invalid token")
@@ -52,7 +74,10 @@ func ParseToken(tokenStr string) (*JWTClaims, error) {
 	return &claims, err
 }
 
-func IsTokenValid(tokenStr string) bool {
-	_, err := ParseToken(tokenStr)
-	return err == nil
+func ParseTokenToKanboard(tokenStr string) (*JWTClaims, error) {
+	return ParseToken(tokenStr, constant.KANBOARD_AUDIENCE, constant.KANBOARD_SUBJECT)
+}
+
+func ParseTokenToAdmin(tokenStr string) (*JWTClaims, error) {
+	return ParseToken(tokenStr, constant.ADMIN_AUDIENCE, constant.ADMIN_SUBJECT)
 }
@@ -0,0 +1,227 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"server/internal/types"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	ALG_HS256 = "HS256"
+	ALG_EDDSA = "EdDSA"
+	ALG_RS256 = "RS256"
+)
+
+// jwt.secret 对应的密钥，不带 kid 的旧令牌也使用它校验
+const DEFAULT_KID = "default"
+
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	// 签名密钥，只用于校验的密钥为 nil
+	private any
+	public  any
+}
+
+type keyRing struct {
+	active *signingKey
+	keys   map[string]*signingKey
+	// 密钥环中出现的算法，解析时只接受这些算法
+	methods []string
+}
+
+var ring *keyRing
+
+// InitKeyRing 根据配置加载密钥环，需在配置初始化之后调用
+func InitKeyRing(config *types.JWT) error {
+	r := &keyRing{keys: make(map[string]*signingKey)}
+
+	if config.Secret != "" {
+		r.add(&signingKey{
+			kid:     DEFAULT_KID,
+			method:  jwt.SigningMethodHS256,
+			private: []byte(config.Secret),
+			public:  []byte(config.Secret),
+		})
+	}
+	for _, item := range config.Keys {
+		key, err := loadKey(item)
+		if err != nil {
+			return fmt.Errorf("jwt key %q: %w", item.Kid, err)
+		}
+		if _, ok := r.keys[key.kid]; ok {
+			return fmt.Errorf("jwt key %q: duplicate kid", key.kid)
+		}
+		r.add(key)
+	}
+
+	// 未指定 activeKid 时使用最后配置的密钥
+	activeKid := config.ActiveKid
+	if activeKid == "" && len(config.Keys) > 0 {
+		activeKid = config.Keys[len(config.Keys)-1].Kid
+	}
+	if activeKid == "" {
+		activeKid = DEFAULT_KID
+	}
+	active, ok := r.keys[activeKid]
+	if !ok {
+		return fmt.Errorf("jwt active key %q not found", activeKid)
+	}
+	if active.private == nil {
+		return fmt.Errorf("jwt active key %q has no private key", activeKid)
+	}
+	r.active = active
+
+	ring = r
+	return nil
+}
+
+func (r *keyRing) add(key *signingKey) {
+	r.keys[key.kid] = key
+	alg := key.method.Alg()
+	for _, method := range r.methods {
+		if method == alg {
+			return
+		}
+	}
+	r.methods = append(r.methods, alg)
+}
+
+func loadKey(config types.JWTKey) (*signingKey, error) {
+	if config.Kid == "" {
+		return nil, errors.New("kid is required")
+	}
+	key := &signingKey{kid: config.Kid}
+
+	switch config.Algorithm {
+	case ALG_HS256, "":
+		if config.Secret == "" {
+			return nil, errors.New("secret is required")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.private = []byte(config.Secret)
+		key.public = []byte(config.Secret)
+	case ALG_EDDSA:
+		key.method = jwt.SigningMethodEdDSA
+		if config.PrivateKey != "" {
+			data, err := os.ReadFile(config.PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.private = private
+			key.public = private.(ed25519.PrivateKey).Public()
+		}
+		if config.PublicKey != "" {
+			data, err := os.ReadFile(config.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			public, err := jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.public = public
+		}
+	case ALG_RS256:
+		key.method = jwt.SigningMethodRS256
+		if config.PrivateKey != "" {
+			data, err := os.ReadFile(config.PrivateKey)
+			if err != nil {
+				return nil, err
+			}
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.private = private
+			key.public = &private.PublicKey
+		}
+		if config.PublicKey != "" {
+			data, err := os.ReadFile(config.PublicKey)
+			if err != nil {
+				return nil, err
+			}
+			public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.public = public
+		}
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", config.Algorithm)
+	}
+
+	if key.public == nil {
+		return nil, errors.New("privateKey or publicKey is required")
+	}
+	return key, nil
+}
+
+// sign 使用当前密钥签名，并在头部写入 kid
+func (r *keyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.active.method, claims)
+	token.Header["kid"] = r.active.kid
+	return token.SignedString(r.active.private)
+}
+
+// lookup 按 kid 查找校验密钥，算法必须与密钥一致
+func (r *keyRing) lookup(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = DEFAULT_KID
+	}
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown kid %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, errors.New("signing method does not match key")
+	}
+	return key.public, nil
+}
+
+// 生成 PEM 格式的密钥对，用于配置新的签名密钥
+func GenerateKeyPair(algorithm string) ([]byte, []byte, error) {
+	var private, public any
+	switch algorithm {
+	case ALG_EDDSA:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, nil, err
+		}
+		private, public = priv, pub
+	case ALG_RS256:
+		priv, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, nil, err
+		}
+		private, public = priv, &priv.PublicKey
+	default:
+		return nil, nil, fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+
+	privateDer, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDer, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, nil, err
+	}
+	privatePem := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDer})
+	publicPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer})
+	return privatePem, publicPem, nil
+}
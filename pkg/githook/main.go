@@ -0,0 +1,326 @@
+package githook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	PROVIDER_GITHUB = "github"
+	PROVIDER_GITEA  = "gitea"
+	PROVIDER_GITLAB = "gitlab"
+)
+
+const (
+	EVENT_PUSH          = "push"
+	EVENT_MERGE_REQUEST = "merge_request"
+)
+
+var ErrUnsupportedEvent = errors.New("unsupported event")
+
+type Commit struct {
+	ID        string
+	Message   string
+	URL       string
+	Author    string
+	Timestamp *time.Time
+}
+
+type MergeRequest struct {
+	Number      int
+	Title       string
+	Description string
+	URL         string
+	Author      string
+	Merged      bool
+	MergedAt    *time.Time
+}
+
+// Event 为各平台推送内容的统一结构，Commits 与 MergeRequest 按事件类型二选一
+type Event struct {
+	Type          string
+	Repository    string
+	Branch        string
+	DefaultBranch string
+	Commits       []Commit
+	MergeRequest  *MergeRequest
+}
+
+// OnDefaultBranch 推送到默认分支时才视为已合入
+func (e *Event) OnDefaultBranch() bool {
+	return e.Branch != "" && e.Branch == e.DefaultBranch
+}
+
+type Reference struct {
+	TaskID uint
+	Close  bool
+}
+
+var referencePattern = regexp.MustCompile(`(?i)(?:\b(close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+)?#(\d+)\b`)
+
+// ParseReferences 解析文本中的任务引用，如 "fixes #123"、"refs #45"
+func ParseReferences(text string) []Reference {
+	references := []Reference{}
+	indexes := map[uint]int{}
+	for _, match := range referencePattern.FindAllStringSubmatchIndex(text, -1) {
+		// 忽略 "owner/repo#1"、"abc#1" 这类引用其他仓库或非任务编号的写法
+		hash := match[4] - 1
+		if hash > 0 && (isWordChar(text[hash-1]) || text[hash-1] == '/') {
+			continue
+		}
+		id, err := strconv.ParseUint(text[match[4]:match[5]], 10, 0)
+		if err != nil || id == 0 {
+			continue
+		}
+		taskId := uint(id)
+		closing := match[2] != -1
+		if index, ok := indexes[taskId]; ok {
+			references[index].Close = references[index].Close || closing
+			continue
+		}
+		indexes[taskId] = len(references)
+		references = append(references, Reference{TaskID: taskId, Close: closing})
+	}
+	return references
+}
+
+func isWordChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// Verify 按平台约定校验请求来源
+func Verify(provider string, secret string, header http.Header, body []byte) bool {
+	switch provider {
+	case PROVIDER_GITHUB:
+		signature := header.Get("X-Hub-Signature-256")
+		return strings.HasPrefix(signature, "sha256=") && hmac.Equal([]byte(signature[len("sha256="):]), []byte(sign(secret, body)))
+	case PROVIDER_GITEA:
+		signature := header.Get("X-Gitea-Signature")
+		return signature != "" && hmac.Equal([]byte(signature), []byte(sign(secret, body)))
+	case PROVIDER_GITLAB:
+		token := header.Get("X-Gitlab-Token")
+		return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Parse 将平台推送内容转换为统一结构，不支持的事件返回 ErrUnsupportedEvent
+func Parse(provider string, header http.Header, body []byte) (*Event, error) {
+	switch provider {
+	case PROVIDER_GITHUB:
+		return parseGithubStyle(header.Get("X-GitHub-Event"), body)
+	case PROVIDER_GITEA:
+		return parseGithubStyle(header.Get("X-Gitea-Event"), body)
+	case PROVIDER_GITLAB:
+		return parseGitlab(header.Get("X-Gitlab-Event"), body)
+	}
+	return nil, errors.New("unknown provider: " + provider)
+}
+
+type githubCommit struct {
+	ID        string `json:"id"`
+	Message   string `json:"message"`
+	URL       string `json:"url"`
+	Timestamp string `json:"timestamp"`
+	Author    struct {
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"author"`
+}
+
+type githubRepository struct {
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+type githubPush struct {
+	Ref        string           `json:"ref"`
+	Repository githubRepository `json:"repository"`
+	Commits    []githubCommit   `json:"commits"`
+}
+
+type githubPullRequest struct {
+	Action      string           `json:"action"`
+	Number      int              `json:"number"`
+	Repository  githubRepository `json:"repository"`
+	PullRequest struct {
+		Title    string `json:"title"`
+		Body     string `json:"body"`
+		HTMLURL  string `json:"html_url"`
+		Merged   bool   `json:"merged"`
+		MergedAt string `json:"merged_at"`
+		User     struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+}
+
+// GitHub 与 Gitea 的推送格式基本一致
+func parseGithubStyle(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "push":
+		var push githubPush
+		if err := json.Unmarshal(body, &push); err != nil {
+			return nil, err
+		}
+		event := Event{
+			Type:          EVENT_PUSH,
+			Repository:    push.Repository.FullName,
+			Branch:        strings.TrimPrefix(push.Ref, "refs/heads/"),
+			DefaultBranch: push.Repository.DefaultBranch,
+			Commits:       []Commit{},
+		}
+		for _, commit := range push.Commits {
+			author := commit.Author.Username
+			if author == "" {
+				author = commit.Author.Name
+			}
+			event.Commits = append(event.Commits, Commit{
+				ID:        commit.ID,
+				Message:   commit.Message,
+				URL:       commit.URL,
+				Author:    author,
+				Timestamp: parseTime(commit.Timestamp),
+			})
+		}
+		return &event, nil
+	case "pull_request":
+		var pull githubPullRequest
+		if err := json.Unmarshal(body, &pull); err != nil {
+			return nil, err
+		}
+		return &Event{
+			Type:          EVENT_MERGE_REQUEST,
+			Repository:    pull.Repository.FullName,
+			Branch:        pull.PullRequest.Base.Ref,
+			DefaultBranch: pull.Repository.DefaultBranch,
+			MergeRequest: &MergeRequest{
+				Number:      pull.Number,
+				Title:       pull.PullRequest.Title,
+				Description: pull.PullRequest.Body,
+				URL:         pull.PullRequest.HTMLURL,
+				Author:      pull.PullRequest.User.Login,
+				Merged:      pull.Action == "closed" && pull.PullRequest.Merged,
+				MergedAt:    parseTime(pull.PullRequest.MergedAt),
+			},
+		}, nil
+	}
+	return nil, ErrUnsupportedEvent
+}
+
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	DefaultBranch     string `json:"default_branch"`
+}
+
+type gitlabPush struct {
+	Ref     string        `json:"ref"`
+	Project gitlabProject `json:"project"`
+	Commits []struct {
+		ID        string `json:"id"`
+		Message   string `json:"message"`
+		URL       string `json:"url"`
+		Timestamp string `json:"timestamp"`
+		Author    struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commits"`
+}
+
+type gitlabMergeRequest struct {
+	Project gitlabProject `json:"project"`
+	User    struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	ObjectAttributes struct {
+		IID          int    `json:"iid"`
+		Title        string `json:"title"`
+		Description  string `json:"description"`
+		URL          string `json:"url"`
+		Action       string `json:"action"`
+		State        string `json:"state"`
+		TargetBranch string `json:"target_branch"`
+		UpdatedAt    string `json:"updated_at"`
+	} `json:"object_attributes"`
+}
+
+func parseGitlab(eventType string, body []byte) (*Event, error) {
+	switch eventType {
+	case "Push Hook":
+		var push gitlabPush
+		if err := json.Unmarshal(body, &push); err != nil {
+			return nil, err
+		}
+		event := Event{
+			Type:          EVENT_PUSH,
+			Repository:    push.Project.PathWithNamespace,
+			Branch:        strings.TrimPrefix(push.Ref, "refs/heads/"),
+			DefaultBranch: push.Project.DefaultBranch,
+			Commits:       []Commit{},
+		}
+		for _, commit := range push.Commits {
+			event.Commits = append(event.Commits, Commit{
+				ID:        commit.ID,
+				Message:   commit.Message,
+				URL:       commit.URL,
+				Author:    commit.Author.Name,
+				Timestamp: parseTime(commit.Timestamp),
+			})
+		}
+		return &event, nil
+	case "Merge Request Hook":
+		var request gitlabMergeRequest
+		if err := json.Unmarshal(body, &request); err != nil {
+			return nil, err
+		}
+		attributes := request.ObjectAttributes
+		merged := attributes.Action == "merge" || (attributes.Action == "" && attributes.State == "merged")
+		mergeRequest := MergeRequest{
+			Number:      attributes.IID,
+			Title:       attributes.Title,
+			Description: attributes.Description,
+			URL:         attributes.URL,
+			Author:      request.User.Username,
+			Merged:      merged,
+		}
+		if merged {
+			mergeRequest.MergedAt = parseTime(attributes.UpdatedAt)
+		}
+		return &Event{
+			Type:          EVENT_MERGE_REQUEST,
+			Repository:    request.Project.PathWithNamespace,
+			Branch:        attributes.TargetBranch,
+			DefaultBranch: request.Project.DefaultBranch,
+			MergeRequest:  &mergeRequest,
+		}, nil
+	}
+	return nil, ErrUnsupportedEvent
+}
+
+// GitLab 的时间格式不完全是 RFC3339，例如 "2024-01-02 03:04:05 UTC"
+func parseTime(value string) *time.Time {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
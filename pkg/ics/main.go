@@ -0,0 +1,195 @@
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	COMPONENT_EVENT = "VEVENT"
+	COMPONENT_TODO  = "VTODO"
+)
+
+const (
+	TODO_NEEDS_ACTION = "NEEDS-ACTION"
+	TODO_IN_PROCESS   = "IN-PROCESS"
+	TODO_COMPLETED    = "COMPLETED"
+	EVENT_CONFIRMED   = "CONFIRMED"
+)
+
+// RFC 5545 建议每行不超过 75 个字节
+const lineLimit = 75
+
+type Calendar struct {
+	ProdID     string
+	Name       string
+	Desc       string
+	Refresh    time.Duration
+	Components []Component
+}
+
+type Component struct {
+	Type        string
+	UID         string
+	Stamp       time.Time
+	Created     time.Time
+	Modified    time.Time
+	Summary     string
+	Desc        string
+	URL         string
+	Categories  []string
+	Status      string
+	Priority    int
+	Start       time.Time
+	End         time.Time
+	Due         time.Time
+	Completed   *time.Time
+	PercentDone *int
+}
+
+func (c *Calendar) WriteTo(w io.Writer) (int64, error) {
+	writer := &lineWriter{w: bufio.NewWriter(w)}
+
+	writer.line("BEGIN", "VCALENDAR")
+	writer.line("VERSION", "2.0")
+	writer.line("PRODID", c.ProdID)
+	writer.line("CALSCALE", "GREGORIAN")
+	writer.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		writer.line("X-WR-CALNAME", Escape(c.Name))
+	}
+	if c.Desc != "" {
+		writer.line("X-WR-CALDESC", Escape(c.Desc))
+	}
+	if c.Refresh > 0 {
+		duration := formatDuration(c.Refresh)
+		writer.line("REFRESH-INTERVAL;VALUE=DURATION", duration)
+		writer.line("X-PUBLISHED-TTL", duration)
+	}
+	for _, component := range c.Components {
+		component.write(writer)
+	}
+	writer.line("END", "VCALENDAR")
+
+	if writer.err == nil {
+		writer.err = writer.w.Flush()
+	}
+	return writer.n, writer.err
+}
+
+func (c *Component) write(writer *lineWriter) {
+	writer.line("BEGIN", c.Type)
+	writer.line("UID", c.UID)
+	writer.line("DTSTAMP", FormatTime(c.Stamp))
+	if !c.Created.IsZero() {
+		writer.line("CREATED", FormatTime(c.Created))
+	}
+	if !c.Modified.IsZero() {
+		writer.line("LAST-MODIFIED", FormatTime(c.Modified))
+	}
+	writer.line("SUMMARY", Escape(c.Summary))
+	if c.Desc != "" {
+		writer.line("DESCRIPTION", Escape(c.Desc))
+	}
+	if c.URL != "" {
+		writer.line("URL", c.URL)
+	}
+	if len(c.Categories) > 0 {
+		categories := make([]string, len(c.Categories))
+		for i, category := range c.Categories {
+			categories[i] = Escape(category)
+		}
+		writer.line("CATEGORIES", strings.Join(categories, ","))
+	}
+	if c.Status != "" {
+		writer.line("STATUS", c.Status)
+	}
+	if c.Priority > 0 {
+		writer.line("PRIORITY", fmt.Sprintf("%d", c.Priority))
+	}
+	if !c.Start.IsZero() {
+		writer.line("DTSTART", FormatTime(c.Start))
+	}
+	if !c.End.IsZero() {
+		writer.line("DTEND", FormatTime(c.End))
+	}
+	if !c.Due.IsZero() {
+		writer.line("DUE", FormatTime(c.Due))
+	}
+	if c.Completed != nil {
+		writer.line("COMPLETED", FormatTime(*c.Completed))
+	}
+	if c.PercentDone != nil {
+		writer.line("PERCENT-COMPLETE", fmt.Sprintf("%d", *c.PercentDone))
+	}
+	writer.line("END", c.Type)
+}
+
+// 统一使用 UTC 时间，避免输出 VTIMEZONE
+func FormatTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func Escape(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+func formatDuration(d time.Duration) string {
+	minutes := int(d.Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes%(24*60) == 0 {
+		return fmt.Sprintf("P%dD", minutes/(24*60))
+	}
+	if minutes%60 == 0 {
+		return fmt.Sprintf("PT%dH", minutes/60)
+	}
+	return fmt.Sprintf("PT%dM", minutes)
+}
+
+type lineWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+// 按 75 字节折行，续行以空格开头，且不截断多字节字符
+func (l *lineWriter) line(name string, value string) {
+	if l.err != nil {
+		return
+	}
+	content := name + ":" + value
+	limit := lineLimit
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+		l.write(content[:cut] + "\r\n ")
+		content = content[cut:]
+		limit = lineLimit - 1
+	}
+	l.write(content + "\r\n")
+}
+
+func (l *lineWriter) write(s string) {
+	if l.err != nil {
+		return
+	}
+	n, err := l.w.WriteString(s)
+	l.n += int64(n)
+	l.err = err
+}
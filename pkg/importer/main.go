@@ -0,0 +1,273 @@
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	FORMAT_CSV    = "csv"
+	FORMAT_TRELLO = "trello"
+	FORMAT_JIRA   = "jira"
+)
+
+const (
+	FIELD_TITLE      = "title"
+	FIELD_DESC       = "desc"
+	FIELD_STATUS     = "status"
+	FIELD_PRIORITY   = "priority"
+	FIELD_START_DATE = "start_date"
+	FIELD_DUE_DATE   = "due_date"
+	FIELD_ASSIGNEES  = "assignees"
+	FIELD_CREATOR    = "creator"
+)
+
+// Record 为各种来源统一后的任务记录，字段均保留原始文本
+type Record struct {
+	Line      int      `json:"line"`
+	Title     string   `json:"title"`
+	Desc      string   `json:"desc"`
+	Status    string   `json:"status"`
+	Priority  string   `json:"priority"`
+	StartDate string   `json:"start_date"`
+	DueDate   string   `json:"due_date"`
+	Creator   string   `json:"creator"`
+	Assignees []string `json:"assignees"`
+	Labels    []string `json:"labels"`
+}
+
+var DefaultCSVMapping = map[string]string{
+	FIELD_TITLE:      "title",
+	FIELD_DESC:       "desc",
+	FIELD_STATUS:     "status",
+	FIELD_PRIORITY:   "priority",
+	FIELD_START_DATE: "start_date",
+	FIELD_DUE_DATE:   "due_date",
+	FIELD_ASSIGNEES:  "assignees",
+	FIELD_CREATOR:    "creator",
+}
+
+var JiraCSVMapping = map[string]string{
+	FIELD_TITLE:      "Summary",
+	FIELD_DESC:       "Description",
+	FIELD_STATUS:     "Status",
+	FIELD_PRIORITY:   "Priority",
+	FIELD_START_DATE: "Start date",
+	FIELD_DUE_DATE:   "Due date",
+	FIELD_ASSIGNEES:  "Assignee",
+	FIELD_CREATOR:    "Reporter",
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	time.DateTime,
+	"2006-01-02 15:04",
+	time.DateOnly,
+	"2006/01/02 15:04:05",
+	"2006/01/02",
+	"02/Jan/06 3:04 PM",
+	"02/Jan/06 15:04",
+	"02/Jan/06",
+	"1/2/2006 15:04",
+	"1/2/2006",
+}
+
+func Parse(format string, reader io.Reader, mapping map[string]string) ([]Record, error) {
+	switch format {
+	case FORMAT_CSV:
+		if len(mapping) == 0 {
+			mapping = DefaultCSVMapping
+		}
+		return ParseCSV(reader, mapping)
+	case FORMAT_JIRA:
+		if len(mapping) == 0 {
+			mapping = JiraCSVMapping
+		}
+		return ParseCSV(reader, mapping)
+	case FORMAT_TRELLO:
+		return ParseTrello(reader)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", format)
+}
+
+// ParseCSV 按 mapping（任务字段 -> 表头名）读取 CSV，表头不区分大小写，同名列会合并（用于 Jira 的多列字段）
+func ParseCSV(reader io.Reader, mapping map[string]string) ([]Record, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.LazyQuotes = true
+
+	header, err := csvReader.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\xEF\xBB\xBF")
+	}
+
+	columns := make(map[string][]int)
+	for field, name := range mapping {
+		for index, column := range header {
+			if strings.EqualFold(strings.TrimSpace(column), strings.TrimSpace(name)) {
+				columns[field] = append(columns[field], index)
+			}
+		}
+	}
+	if len(columns[FIELD_TITLE]) == 0 {
+		return nil, errors.New("missing title column")
+	}
+
+	records := []Record{}
+	line := 1
+	for {
+		row, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		values := func(field string) []string {
+			result := []string{}
+			for _, index := range columns[field] {
+				if index < len(row) && strings.TrimSpace(row[index]) != "" {
+					result = append(result, strings.TrimSpace(row[index]))
+				}
+			}
+			return result
+		}
+		value := func(field string) string {
+			if v := values(field); len(v) > 0 {
+				return v[0]
+			}
+			return ""
+		}
+
+		assignees := []string{}
+		for _, v := range values(FIELD_ASSIGNEES) {
+			assignees = append(assignees, splitNames(v)...)
+		}
+
+		records = append(records, Record{
+			Line:      line,
+			Title:     value(FIELD_TITLE),
+			Desc:      value(FIELD_DESC),
+			Status:    value(FIELD_STATUS),
+			Priority:  value(FIELD_PRIORITY),
+			StartDate: value(FIELD_START_DATE),
+			DueDate:   value(FIELD_DUE_DATE),
+			Creator:   value(FIELD_CREATOR),
+			Assignees: assignees,
+		})
+	}
+	return records, nil
+}
+
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		Name        string   `json:"name"`
+		Desc        string   `json:"desc"`
+		IDList      string   `json:"idList"`
+		Closed      bool     `json:"closed"`
+		Start       *string  `json:"start"`
+		Due         *string  `json:"due"`
+		DueComplete bool     `json:"dueComplete"`
+		IDMembers   []string `json:"idMembers"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Members []struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"members"`
+}
+
+// ParseTrello 读取 Trello 看板导出的 JSON，列表名作为状态，已归档的卡片会被跳过
+func ParseTrello(reader io.Reader) ([]Record, error) {
+	var board trelloBoard
+	if err := json.NewDecoder(reader).Decode(&board); err != nil {
+		return nil, err
+	}
+
+	lists := make(map[string]string)
+	for _, list := range board.Lists {
+		lists[list.ID] = list.Name
+	}
+	members := make(map[string]string)
+	for _, member := range board.Members {
+		members[member.ID] = member.Username
+	}
+
+	records := []Record{}
+	for index, card := range board.Cards {
+		if card.Closed {
+			continue
+		}
+		record := Record{
+			Line:      index + 1,
+			Title:     strings.TrimSpace(card.Name),
+			Desc:      card.Desc,
+			Status:    lists[card.IDList],
+			Assignees: []string{},
+			Labels:    []string{},
+		}
+		if card.DueComplete {
+			record.Status = "done"
+		}
+		if card.Start != nil {
+			record.StartDate = *card.Start
+		}
+		if card.Due != nil {
+			record.DueDate = *card.Due
+		}
+		for _, memberId := range card.IDMembers {
+			if username, ok := members[memberId]; ok {
+				record.Assignees = append(record.Assignees, username)
+			}
+		}
+		for _, label := range card.Labels {
+			name := label.Name
+			if name == "" {
+				name = label.Color
+			}
+			record.Labels = append(record.Labels, name)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time: %s", value)
+}
+
+func splitNames(value string) []string {
+	names := []string{}
+	for _, name := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ';' || r == ','
+	}) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
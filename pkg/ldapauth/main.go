@@ -0,0 +1,160 @@
+package ldapauth
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+var (
+	ErrInvalidCredentials = errors.New("ldap: invalid credentials")
+	ErrUserNotFound       = errors.New("ldap: user not found")
+)
+
+type Config struct {
+	// ldap://host:389 或 ldaps://host:636
+	URL                string
+	StartTLS           bool
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	// 用于查找用户的服务账号，为空时匿名查找
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// {username} 会被替换为转义后的用户名，例如 (&(objectClass=person)(uid={username}))
+	UserFilter        string
+	UsernameAttribute string
+	EmailAttribute    string
+	// 不为空时，作为该分组成员的用户为管理员
+	AdminGroupDN string
+}
+
+// Entry 为认证通过的目录用户
+type Entry struct {
+	DN       string
+	Username string
+	Email    string
+	IsAdmin  bool
+}
+
+// Authenticate 使用服务账号查找用户，再以用户 DN 与密码绑定校验密码
+func Authenticate(config Config, username string, password string) (*Entry, error) {
+	// 空密码会被服务器视为匿名绑定而直接成功
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := dial(config)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := serviceBind(conn, config); err != nil {
+		return nil, err
+	}
+
+	filter := strings.ReplaceAll(config.UserFilter, "{username}", ldap.EscapeFilter(username))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		config.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 2, int(config.Timeout.Seconds()), false,
+		filter, []string{config.UsernameAttribute, config.EmailAttribute}, nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return nil, ErrUserNotFound
+	}
+	// 过滤条件匹配到多个用户时拒绝登录
+	if len(result.Entries) > 1 {
+		return nil, fmt.Errorf("ldap search: filter matched multiple entries")
+	}
+	item := result.Entries[0]
+
+	if err := conn.Bind(item.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap bind: %w", err)
+	}
+
+	entry := &Entry{
+		DN:       item.DN,
+		Username: item.GetEqualFoldAttributeValue(config.UsernameAttribute),
+		Email:    item.GetEqualFoldAttributeValue(config.EmailAttribute),
+	}
+	if entry.Username == "" {
+		entry.Username = username
+	}
+
+	if config.AdminGroupDN != "" {
+		// 组成员查询使用服务账号，用户本身可能没有读取分组的权限
+		if err := serviceBind(conn, config); err != nil {
+			return nil, err
+		}
+		entry.IsAdmin, err = isMember(conn, config, entry)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entry, nil
+}
+
+func dial(config Config) (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}
+	conn, err := ldap.DialURL(config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ldap dial: %w", err)
+	}
+	conn.SetTimeout(config.Timeout)
+
+	if config.StartTLS {
+		if parsed, err := url.Parse(config.URL); err == nil {
+			tlsConfig.ServerName = parsed.Hostname()
+		}
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap starttls: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func serviceBind(conn *ldap.Conn, config Config) error {
+	var err error
+	if config.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(config.BindDN, config.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("ldap service bind: %w", err)
+	}
+	return nil
+}
+
+// isMember 兼容 groupOfNames、groupOfUniqueNames 与 posixGroup
+func isMember(conn *ldap.Conn, config Config, entry *Entry) (bool, error) {
+	dn := ldap.EscapeFilter(entry.DN)
+	filter := fmt.Sprintf("(|(member=%s)(uniqueMember=%s)(memberUid=%s))", dn, dn, ldap.EscapeFilter(entry.Username))
+	result, err := conn.Search(ldap.NewSearchRequest(
+		config.AdminGroupDN, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, int(config.Timeout.Seconds()), false,
+		filter, []string{"dn"}, nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return false, nil
+		}
+		return false, fmt.Errorf("ldap group search: %w", err)
+	}
+	return len(result.Entries) > 0, nil
+}
@@ -0,0 +1,100 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      []string
+	Subject string
+	// 纯文本正文
+	Body string
+}
+
+// Sender 为邮件发送方式，SMTPSender 之外可以按需实现
+type Sender interface {
+	Send(message Message) error
+}
+
+// SMTPSender 通过 SMTP 发送邮件，服务器支持时自动使用 STARTTLS
+// 未配置用户名时不进行认证，可以直接指向本地的 SMTP 捕获工具
+type SMTPSender struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPSender(host string, port int, username string, password string, from string) *SMTPSender {
+	return &SMTPSender{
+		Addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Username: username,
+		Password: password,
+		From:     from,
+	}
+}
+
+func (s *SMTPSender) Send(message Message) error {
+	from, err := mail.ParseAddress(s.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	if len(message.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+	to := []string{}
+	for _, item := range message.To {
+		address, err := mail.ParseAddress(item)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", item, err)
+		}
+		to = append(to, address.Address)
+	}
+	message.To = to
+
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Addr)
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	return smtp.SendMail(s.Addr, auth, from.Address, to, Build(from, message))
+}
+
+// Build 生成 UTF-8 编码的纯文本邮件
+func Build(from *mail.Address, message Message) []byte {
+	var buf bytes.Buffer
+	header := func(key string, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", strings.Join(message.To, ", "))
+	header("Subject", mime.BEncoding.Encode("UTF-8", message.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", messageId(from.Address))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "8bit")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(strings.ReplaceAll(message.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+func messageId(address string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		domain = address[at+1:]
+	}
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(buf), domain)
+}
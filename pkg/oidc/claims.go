@@ -0,0 +1,65 @@
+package oidc
+
+import "strings"
+
+// Claims 为 ID Token 中的全部声明
+type Claims map[string]any
+
+func (c Claims) Subject() string {
+	return c.String("sub")
+}
+
+func (c Claims) Issuer() string {
+	return c.String("iss")
+}
+
+// String 支持以 . 分隔的嵌套声明，例如 realm_access.roles
+func (c Claims) String(name string) string {
+	value, _ := c.lookup(name).(string)
+	return value
+}
+
+// Bool 兼容部分身份提供方以字符串返回 email_verified
+func (c Claims) Bool(name string) bool {
+	switch value := c.lookup(name).(type) {
+	case bool:
+		return value
+	case string:
+		return value == "true"
+	default:
+		return false
+	}
+}
+
+// Strings 单个字符串视为只有一个元素的列表
+func (c Claims) Strings(name string) []string {
+	switch value := c.lookup(name).(type) {
+	case string:
+		return []string{value}
+	case []any:
+		values := []string{}
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+func (c Claims) lookup(name string) any {
+	if value, ok := c[name]; ok {
+		return value
+	}
+	var current any = map[string]any(c)
+	for _, part := range strings.Split(name, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = object[part]
+	}
+	return current
+}
@@ -0,0 +1,273 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"server/pkg/crypto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	DISCOVERY_PATH = "/.well-known/openid-configuration"
+
+	CHALLENGE_METHOD = "S256"
+
+	// 签名密钥轮换后 JWKS 中找不到 kid 时重新拉取，两次拉取至少间隔该时长
+	keysRefreshInterval = time.Minute
+	// ID Token 时间校验允许的时钟偏差
+	clockSkew = time.Minute
+	// 读取身份提供方响应的最大长度
+	maxResponseSize = 1 << 20
+)
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	Timeout      time.Duration
+}
+
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Provider 为授权码模式的 OIDC 客户端，发现文档与签名公钥在首次使用时加载并缓存
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu          sync.Mutex
+	discovery   *Discovery
+	keys        map[string]any
+	keysFetched time.Time
+}
+
+func NewProvider(config Config) *Provider {
+	return &Provider{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// NewCodeVerifier 生成 PKCE code_verifier
+func NewCodeVerifier() (string, error) {
+	return crypto.GenerateRandomToken(32)
+}
+
+// CodeChallenge 按 S256 方式计算 code_challenge
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (p *Provider) getDiscovery() (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery Discovery
+	if err := p.getJSON(strings.TrimRight(p.config.Issuer, "/")+DISCOVERY_PATH, &discovery); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if discovery.Issuer != p.config.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer mismatch %q", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery: missing endpoints")
+	}
+	p.discovery = &discovery
+	return p.discovery, nil
+}
+
+// AuthCodeURL 生成跳转到身份提供方的授权地址
+func (p *Provider) AuthCodeURL(state string, nonce string, verifier string) (string, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", CodeChallenge(verifier))
+	query.Set("code_challenge_method", CHALLENGE_METHOD)
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+// Exchange 使用授权码与 code_verifier 换取令牌
+func (p *Provider) Exchange(code string, verifier string) (*Token, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	form.Set("code_verifier", verifier)
+	form.Set("client_id", p.config.ClientID)
+
+	request, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	if p.config.ClientSecret != "" {
+		request.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token: %w", err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("oidc token: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc token: status %d: %s", response.StatusCode, body)
+	}
+
+	var token Token
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, fmt.Errorf("oidc token: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, fmt.Errorf("oidc token: missing id_token")
+	}
+	return &token, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名、签发方、受众、有效期与 nonce
+func (p *Provider) VerifyIDToken(raw string, nonce string) (Claims, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	claims := Claims{}
+	_, err = jwt.ParseWithClaims(raw, jwt.MapClaims(claims), p.keyFunc,
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("oidc id_token: %w", err)
+	}
+	if claims.String("nonce") != nonce {
+		return nil, fmt.Errorf("oidc id_token: nonce mismatch")
+	}
+	// 存在多个受众时 azp 必须为本客户端
+	if audience := claims.Strings("aud"); len(audience) > 1 && claims.String("azp") != p.config.ClientID {
+		return nil, fmt.Errorf("oidc id_token: azp mismatch")
+	}
+	if claims.Subject() == "" {
+		return nil, fmt.Errorf("oidc id_token: missing sub")
+	}
+	return claims, nil
+}
+
+func (p *Provider) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := p.lookupKey(kid, false); ok {
+		return key, nil
+	}
+	if key, ok := p.lookupKey(kid, true); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+// lookupKey 未指定 kid 且只有一把密钥时直接使用该密钥
+func (p *Provider) lookupKey(kid string, refresh bool) (any, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys == nil || (refresh && time.Since(p.keysFetched) > keysRefreshInterval) {
+		keys, err := p.fetchKeys()
+		if err != nil {
+			return nil, false
+		}
+		p.keys = keys
+		p.keysFetched = time.Now()
+	}
+	if key, ok := p.keys[kid]; ok {
+		return key, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+func (p *Provider) fetchKeys() (map[string]any, error) {
+	var set JSONWebKeySet
+	if err := p.getJSON(p.discovery.JWKSURI, &set); err != nil {
+		return nil, err
+	}
+	keys := map[string]any{}
+	for _, item := range set.Keys {
+		if item.Use != "" && item.Use != "sig" {
+			continue
+		}
+		key, err := item.PublicKey()
+		if err != nil {
+			continue
+		}
+		keys[item.Kid] = key
+	}
+	return keys, nil
+}
+
+func (p *Provider) getJSON(url string, target any) error {
+	response, err := p.client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", response.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(response.Body, maxResponseSize)).Decode(target)
+}
@@ -0,0 +1,154 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	HEADER_EVENT     = "X-Kanboard-Event"
+	HEADER_DELIVERY  = "X-Kanboard-Delivery"
+	HEADER_SIGNATURE = "X-Kanboard-Signature-256"
+
+	SIGNATURE_PREFIX = "sha256="
+)
+
+// 响应内容只保留前面一部分写入投递记录
+const maxResponseSize = 2048
+
+// 除标准库已识别的本机、内网与链路本地地址外，另外禁止的网段
+var forbiddenPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+var ErrForbiddenAddress = errors.New("webhook: address is not allowed")
+
+type Options struct {
+	Timeout time.Duration
+	// 为 false 时拒绝连接本机、内网、链路本地与未指定地址
+	AllowPrivateNetwork bool
+}
+
+type Result struct {
+	StatusCode int
+	Body       string
+}
+
+// IsForbiddenIP 判断地址是否为本机、内网、链路本地或未指定地址
+func IsForbiddenIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return true
+	}
+	addr = addr.Unmap()
+	for _, prefix := range forbiddenPrefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHost 解析主机名，任一地址被禁止时返回 ErrForbiddenAddress
+// 仅用于保存地址时提前提示，实际投递时仍会在建立连接前再次校验
+func CheckHost(ctx context.Context, host string) error {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return err
+	}
+	for _, addr := range addrs {
+		if IsForbiddenIP(addr.IP) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// dialControl 在域名解析完成后、建立连接前校验实际连接的地址，防止 DNS 重绑定
+func dialControl(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsForbiddenIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+func newClient(options Options) *http.Client {
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	transport := &http.Transport{
+		DialContext:       dialer.DialContext,
+		DisableKeepAlives: true,
+	}
+	if options.AllowPrivateNetwork {
+		transport.Proxy = http.ProxyFromEnvironment
+	} else {
+		// 经过代理时无法校验实际的目标地址，因此不使用代理
+		dialer.Control = dialControl
+	}
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+		// 不跟随跳转，避免被重定向到内网地址
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Sign 使用 HMAC-SHA256 对请求体签名
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return SIGNATURE_PREFIX + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名，接收方可直接复用
+func Verify(secret string, body []byte, signature string) bool {
+	if !strings.HasPrefix(signature, SIGNATURE_PREFIX) {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+func Send(url string, event string, deliveryId string, secret string, body []byte, options Options) (*Result, error) {
+	request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "Kanboard-Webhook")
+	request.Header.Set(HEADER_EVENT, event)
+	request.Header.Set(HEADER_DELIVERY, deliveryId)
+	request.Header.Set(HEADER_SIGNATURE, Sign(secret, body))
+
+	response, err := newClient(options).Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	return &Result{StatusCode: response.StatusCode, Body: string(data)}, nil
+}